@@ -28,6 +28,8 @@ import (
 
 	"github.com/GoogleCloudPlatform/testgrid/config"
 	configpb "github.com/GoogleCloudPlatform/testgrid/pb/config"
+
+	"k8s.io/release/pkg/testgrid"
 )
 
 const (
@@ -46,16 +48,13 @@ func main() {
 	}
 
 	branch := os.Args[1]
-	if branch == "master" {
-		branch = "release-master"
-	}
 
 	ctx := context.Background()
 
 	conf, err := readConfFromURL(ctx, TestgridConfigURL)
 	bailOnErr(err, "cannot get config")
 
-	dashboardName := "sig-" + branch + "-blocking"
+	dashboardName := testgrid.BlockingDashboard(branch)
 	dashboard := config.FindDashboard(dashboardName, conf)
 	if dashboard == nil {
 		bailOnErr(fmt.Errorf("%s not found", dashboardName), "finding dashboard")
@@ -0,0 +1,295 @@
+/*
+Copyright 2020 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package cmd
+
+import (
+	"context"
+	"fmt"
+	"io/ioutil"
+	"os"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/pkg/errors"
+	"github.com/sirupsen/logrus"
+	"github.com/spf13/cobra"
+
+	"k8s.io/release/pkg/approval"
+	"k8s.io/release/pkg/command"
+	"k8s.io/release/pkg/github"
+	"k8s.io/release/pkg/slack"
+	"k8s.io/release/pkg/util"
+)
+
+// slackApprovalOptions are the settings of the approvals requested from a
+// Slack app before publishing
+type slackApprovalOptions struct {
+	channel       string
+	teamID        string
+	listenAddress string
+	timeout       time.Duration
+}
+
+func addSlackApprovalFlags(cmd *cobra.Command, opts *slackApprovalOptions) {
+	cmd.PersistentFlags().StringVar(
+		&opts.channel,
+		"slack-approval-channel",
+		"",
+		"Slack channel to request the approvals still missing after the approval signatures and the approval PR in",
+	)
+	cmd.PersistentFlags().StringVar(
+		&opts.teamID,
+		"slack-team-id",
+		util.EnvDefault("SLACK_TEAM_ID", ""),
+		"ID of the Slack workspace of the approvers, approvals from other workspaces are ignored",
+	)
+	cmd.PersistentFlags().StringVar(
+		&opts.listenAddress,
+		"slack-listen-address",
+		"localhost:8080",
+		"address to receive the interactions of the Slack app on, at the path "+slack.InteractionPath+
+			", has to be exposed via a TLS terminating tunnel or proxy because Slack only sends them to HTTPS URLs",
+	)
+	cmd.PersistentFlags().DurationVar(
+		&opts.timeout,
+		"slack-approval-timeout",
+		time.Hour,
+		"maximum time to wait for the Slack approval",
+	)
+}
+
+// requestSlackApprovals requests `required` distinct approvals of the
+// `approvers` in the Slack approval channel
+func requestSlackApprovals(
+	opts *slackApprovalOptions, id, text string, approvers []string, required int,
+) ([]slack.Approval, error) {
+	token := util.EnvDefault("SLACK_BOT_TOKEN", "")
+	secret := util.EnvDefault("SLACK_SIGNING_SECRET", "")
+	if token == "" || secret == "" {
+		return nil, errors.New("the Slack approval requires $SLACK_BOT_TOKEN and $SLACK_SIGNING_SECRET of the Slack app")
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), opts.timeout)
+	defer cancel()
+
+	return slack.New(token).RequestApproval(ctx, &slack.ApprovalRequest{
+		ID:            id,
+		Channel:       opts.channel,
+		Text:          text,
+		Approvers:     approvers,
+		TeamID:        opts.teamID,
+		SigningSecret: secret,
+		Required:      required,
+	}, opts.listenAddress)
+}
+
+// recordSlackDecision records the Slack `decision` in the audit log and
+// fails if it is a rejection
+func recordSlackDecision(version, action string, decision slack.Approval) error {
+	if !decision.Approved {
+		if err := recordAudit(version, "reject", "slack:"+decision.User); err != nil {
+			return err
+		}
+		return errors.Errorf("the %s of %s was rejected by %s", action, version, decision.User)
+	}
+	logrus.Infof("The %s of %s was approved by %s", action, version, decision.User)
+	return recordAudit(version, "approve", "slack:"+decision.User)
+}
+
+// approvalOptions are the settings of the approval gates a --nomock run
+// has to pass before publishing to a destination
+type approvalOptions struct {
+	pullRequest string
+	signatures  []string
+	slack       slackApprovalOptions
+}
+
+func addApprovalFlags(cmd *cobra.Command, opts *approvalOptions) {
+	cmd.PersistentFlags().StringVar(
+		&opts.pullRequest,
+		"approval-pr",
+		"",
+		"GitHub PR whose approving reviews count as approvals, like kubernetes/sig-release#1234, has to mention the approved action",
+	)
+	cmd.PersistentFlags().StringSliceVar(
+		&opts.signatures,
+		"approval-signature",
+		[]string{},
+		"approval signature file created with `krel approve`, can be specified multiple times",
+	)
+	addSlackApprovalFlags(cmd, &opts.slack)
+}
+
+// requireApprovals blocks `action` of `version` to `destination` until the
+// approval gate of the destination is passed. The gates and the keyring of
+// the approvers are read from approval.DefaultPublishedLocation, every
+// destination needs a gate. The approvals are collected from the approval
+// signatures, the approving reviews of the approval PR and, if still not
+// enough, from the Slack approval channel. The GitHub user of `token`,
+// which is the one whose role got checked, never counts as approver.
+// Every authorized approval is recorded in the audit log. Approvals are
+// only required with --nomock.
+func requireApprovals(opts *approvalOptions, token, version, action, destination string) error {
+	if !rootOpts.nomock {
+		return nil
+	}
+	token = githubToken(token)
+	if token == "" {
+		return errors.New("a GitHub token is required to identify the requester of the approval, use --github-token or $GITHUB_TOKEN")
+	}
+	ctx := context.Background()
+	client := github.New(ctx, token)
+
+	dir, err := ioutil.TempDir("", "approval-")
+	if err != nil {
+		return errors.Wrap(err, "creating approval directory")
+	}
+	defer os.RemoveAll(dir)
+	gates, keyring, err := approval.ReadPublished(approval.DefaultPublishedLocation, dir)
+	if err != nil {
+		return errors.Wrap(err, "reading the published approval gates")
+	}
+	gate := gates.ForDestination(destination)
+	if gate == nil {
+		return errors.Errorf("no approval gate published for %s", destination)
+	}
+
+	requester, err := requesterIdentities(ctx, client)
+	if err != nil {
+		return err
+	}
+	gate, excluded := gate.Exclude(requester)
+	for _, name := range excluded {
+		logrus.Infof("Approver %s requested the %s and does not count as approver", name, action)
+	}
+
+	id := approval.ID(action, version, destination)
+	approvals, err := collectApprovals(ctx, client, opts, keyring, id)
+	if err != nil {
+		return err
+	}
+
+	if remaining := gate.Remaining(approvals); remaining > 0 && opts.slack.channel != "" {
+		approvers := gate.SlackApprovers(approvals)
+		decisions, err := requestSlackApprovals(
+			&opts.slack, id,
+			fmt.Sprintf(
+				"Approve the %s of *%s* to %s? %d more approval(s) required.",
+				action, version, destination, remaining,
+			),
+			approvers, remaining,
+		)
+		if err != nil {
+			return err
+		}
+		for _, decision := range decisions {
+			if !decision.Approved {
+				return recordSlackDecision(version, action, decision)
+			}
+			approvals = append(approvals, approval.Approval{
+				Source: approval.SourceSlack, Identity: decision.UserID,
+			})
+		}
+	}
+
+	for name, authorized := range gate.Authorized(approvals) {
+		logrus.Infof("The %s of %s was approved by %s via %s", action, version, name, authorized)
+		if err := recordAudit(version, "approve", authorized.String()); err != nil {
+			return err
+		}
+	}
+	if err := gate.Check(approvals); err != nil {
+		return errors.Wrapf(err, "approval gate of %s not passed", destination)
+	}
+	return nil
+}
+
+// requesterIdentities returns the identities of the person running the
+// action, which are the GitHub user of the `client` and the local secret
+// GPG keys
+func requesterIdentities(ctx context.Context, client github.Client) ([]approval.Approval, error) {
+	user, _, err := client.GetAuthenticatedUser(ctx)
+	if err != nil {
+		return nil, errors.Wrap(err, "getting the authenticated GitHub user")
+	}
+	identities := []approval.Approval{{
+		Source: approval.SourceGitHub, Identity: user.GetLogin(),
+	}}
+
+	if command.Available(approval.GPGExecutable) {
+		fingerprints, err := approval.SecretKeyFingerprints()
+		if err != nil {
+			return nil, err
+		}
+		for _, fingerprint := range fingerprints {
+			identities = append(identities, approval.Approval{
+				Source: approval.SourceGPG, Identity: fingerprint,
+			})
+		}
+	}
+	return identities, nil
+}
+
+// collectApprovals returns the approvals of the approval signatures, which
+// are verified against the `keyring`, and of the approval PR
+func collectApprovals(
+	ctx context.Context, client github.Client, opts *approvalOptions, keyring, id string,
+) ([]approval.Approval, error) {
+	approvals := []approval.Approval{}
+	for _, signature := range opts.signatures {
+		res, err := approval.VerifySignature(keyring, id, signature)
+		if err != nil {
+			return nil, err
+		}
+		approvals = append(approvals, res)
+	}
+
+	if opts.pullRequest != "" {
+		owner, repo, number, err := parsePullRequestRef(opts.pullRequest)
+		if err != nil {
+			return nil, err
+		}
+		logins, err := github.PullRequestApprovers(
+			ctx, client, owner, repo, number, id,
+		)
+		if err != nil {
+			return nil, err
+		}
+		for _, login := range logins {
+			approvals = append(approvals, approval.Approval{
+				Source: approval.SourceGitHub, Identity: login,
+			})
+		}
+	}
+	return approvals, nil
+}
+
+// parsePullRequestRef splits a PR reference like kubernetes/sig-release#1234
+// into its owner, repository and number
+func parsePullRequestRef(ref string) (owner, repo string, number int, err error) {
+	parts := strings.SplitN(ref, "#", 2)
+	repoParts := strings.Split(parts[0], "/")
+	if len(parts) != 2 || len(repoParts) != 2 || repoParts[0] == "" || repoParts[1] == "" {
+		return "", "", 0, errors.Errorf("PR reference %q is not in the format <owner>/<repo>#<number>", ref)
+	}
+	number, err = strconv.Atoi(parts[1])
+	if err != nil {
+		return "", "", 0, errors.Wrapf(err, "invalid PR number in %q", ref)
+	}
+	return repoParts[0], repoParts[1], number, nil
+}
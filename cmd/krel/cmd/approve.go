@@ -0,0 +1,93 @@
+/*
+Copyright 2020 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package cmd
+
+import (
+	"github.com/pkg/errors"
+	"github.com/sirupsen/logrus"
+	"github.com/spf13/cobra"
+
+	"k8s.io/release/pkg/approval"
+	"k8s.io/release/pkg/command"
+)
+
+// approveCmd represents the subcommand for `krel approve`
+var approveCmd = &cobra.Command{
+	Use:   "approve <action> <version> <destination> --output <file>",
+	Short: "Sign an approval for an approval gate",
+	Long: `krel approve <action> <version> <destination> --output <file>
+
+Sign the approval of the action of the version to the destination with
+the GPG key --key of the approver and write the detached signature to
+--output. The signature is handed to the person running the action, who
+passes it via --approval-signature. It is only valid for exactly this
+action, version and destination and only if it was created by the key
+with the fingerprint of the approver in the approval gates. The gates and
+the public keys of the approvers are published at
+` + approval.DefaultPublishedLocation + `.
+
+Because the signature is created with the secret key of the approver,
+nobody else can create it. The approver running the action does not
+count, so a signature of the own key is ignored.`,
+	Example:       "krel approve push v1.18.0 gs://kubernetes-release/release/v1.18.0 --output approval.asc",
+	Args:          cobra.ExactArgs(3),
+	SilenceUsage:  true,
+	SilenceErrors: true,
+	RunE: func(cmd *cobra.Command, args []string) error {
+		return runApprove(approveOpts, args[0], args[1], args[2])
+	},
+}
+
+type approveOptions struct {
+	key    string
+	output string
+}
+
+var approveOpts = &approveOptions{}
+
+func init() {
+	approveCmd.PersistentFlags().StringVar(
+		&approveOpts.key,
+		"key",
+		"",
+		"GPG key to sign the approval with, the default key of gpg if not set",
+	)
+	approveCmd.PersistentFlags().StringVar(
+		&approveOpts.output,
+		"output",
+		"",
+		"path to write the approval signature to",
+	)
+
+	if err := approveCmd.MarkPersistentFlagRequired("output"); err != nil {
+		logrus.Fatal(err)
+	}
+
+	rootCmd.AddCommand(approveCmd)
+}
+
+func runApprove(opts *approveOptions, action, version, destination string) error {
+	if !command.Available(approval.GPGExecutable) {
+		return errors.Errorf("%s is required to sign approvals", approval.GPGExecutable)
+	}
+	id := approval.ID(action, version, destination)
+	if err := approval.Sign(id, opts.key, opts.output); err != nil {
+		return err
+	}
+	logrus.Infof("Wrote the approval signature for %s to %s", id, opts.output)
+	return nil
+}
@@ -0,0 +1,156 @@
+/*
+Copyright 2020 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package cmd
+
+import (
+	"context"
+	"fmt"
+	"io/ioutil"
+	"os"
+	"path"
+	"strings"
+	"text/tabwriter"
+	"time"
+
+	"github.com/pkg/errors"
+	"github.com/spf13/cobra"
+
+	"k8s.io/release/pkg/audit"
+	"k8s.io/release/pkg/gcp/gcs"
+)
+
+// auditCmd represents the subcommand for `krel audit`
+var auditCmd = &cobra.Command{
+	Use:   "audit",
+	Short: "Inspect the audit log of the release runs",
+}
+
+var auditShowCmd = &cobra.Command{
+	Use:   "show <version>",
+	Short: "Show all recorded actions of a release",
+	Long: `krel audit show <version>
+
+Print every side effecting action recorded for the release version, like
+uploads, image pushes and tags, channel changes and rollbacks, together
+with the time, user, host and command line of the run which did it.
+
+All actions done with --nomock are appended to the --audit-log. Every
+record contains the hash of its predecessor, the command fails if the log
+has been modified since the records were written. The command line of a
+record contains only the flag names, their values are redacted.
+
+The hashes carry no key, which means that somebody with access to the log
+can rewrite the whole chain. 'krel push' therefore publishes the hash of
+its last record as ` + audit.HeadFile + ` next to the release artifacts. With
+--bucket, the published head of the release has to be part of the log.`,
+	Example:       "krel audit show v1.18.0",
+	Args:          cobra.ExactArgs(1),
+	SilenceUsage:  true,
+	SilenceErrors: true,
+	RunE: func(cmd *cobra.Command, args []string) error {
+		return runAuditShow(auditShowOpts, args[0])
+	},
+}
+
+type auditShowOptions struct {
+	bucket      string
+	releaseType string
+	gcsSuffix   string
+}
+
+var auditShowOpts = &auditShowOptions{}
+
+func init() {
+	auditShowCmd.PersistentFlags().StringVar(
+		&auditShowOpts.bucket,
+		"bucket",
+		"",
+		"GCS bucket the release was pushed to, to verify the log against the published head",
+	)
+	auditShowCmd.PersistentFlags().StringVar(
+		&auditShowOpts.releaseType,
+		"release-type",
+		"release",
+		"release type the version was pushed as (normally 'release', 'devel' or 'ci')",
+	)
+	auditShowCmd.PersistentFlags().StringVar(
+		&auditShowOpts.gcsSuffix,
+		"gcs-suffix",
+		"",
+		"suffix which was appended to the upload destination on GCS",
+	)
+
+	auditCmd.AddCommand(auditShowCmd)
+	rootCmd.AddCommand(auditCmd)
+}
+
+func runAuditShow(opts *auditShowOptions, version string) error {
+	records, err := audit.New(rootOpts.auditLog).Read()
+	if err != nil {
+		return err
+	}
+	if err := audit.Verify(records); err != nil {
+		return errors.Wrapf(err, "verifying audit log %s", rootOpts.auditLog)
+	}
+	if opts.bucket != "" {
+		head, err := readAuditHead(opts, version)
+		if err != nil {
+			return err
+		}
+		if err := audit.VerifyHead(records, head); err != nil {
+			return errors.Wrapf(err, "verifying audit log %s", rootOpts.auditLog)
+		}
+	}
+
+	records = audit.ForVersion(records, version)
+	if len(records) == 0 {
+		return errors.Errorf("no actions recorded for %s in %s", version, rootOpts.auditLog)
+	}
+
+	w := tabwriter.NewWriter(os.Stdout, 0, 0, 2, ' ', 0)
+	fmt.Fprintln(w, "TIME\tUSER\tHOST\tACTION\tTARGET\tCOMMAND")
+	for i := range records {
+		r := &records[i]
+		fmt.Fprintf(w, "%s\t%s\t%s\t%s\t%s\t%s\n",
+			r.Time.Format(time.RFC3339), r.User, r.Host, r.Action, r.Target, r.Command,
+		)
+	}
+	return errors.Wrap(w.Flush(), "writing audit records")
+}
+
+// readAuditHead returns the audit log head published with the release
+// `version`
+func readAuditHead(opts *auditShowOptions, version string) (string, error) {
+	bucket, err := gcsBucket(opts.bucket)
+	if err != nil {
+		return "", err
+	}
+	r, err := gcs.NewReader(
+		context.Background(), bucket,
+		path.Join(opts.releaseType+opts.gcsSuffix, version, audit.HeadFile),
+	)
+	if err != nil {
+		return "", errors.Wrap(err, "reading published audit log head")
+	}
+	defer r.Close()
+
+	content, err := ioutil.ReadAll(r)
+	if err != nil {
+		return "", errors.Wrap(err, "reading published audit log head")
+	}
+	return strings.TrimSpace(string(content)), nil
+}
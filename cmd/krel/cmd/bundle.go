@@ -0,0 +1,250 @@
+/*
+Copyright 2020 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package cmd
+
+import (
+	"context"
+	"fmt"
+	"io/ioutil"
+	"os"
+	"path"
+	"path/filepath"
+
+	"github.com/pkg/errors"
+	"github.com/sirupsen/logrus"
+	"github.com/spf13/cobra"
+
+	"k8s.io/release/pkg/command"
+	"k8s.io/release/pkg/gcp/gcs"
+	"k8s.io/release/pkg/github"
+	"k8s.io/release/pkg/release"
+)
+
+// bundleCmd represents the subcommand for `krel bundle`
+var bundleCmd = &cobra.Command{
+	Use:   "bundle <version>",
+	Short: "Pack a staged release into a single tarball for offline installs",
+	Long: `krel bundle <version>
+
+Pack the release staged by 'krel push --gpg-sign' in --build-dir together
+with the release images into a single tarball, which allows installing the
+release in air-gapped environments later on by using 'krel bundle push'.
+
+The staged release is bundled unmodified with its signed manifest, checksums
+and signatures. A bundle manifest lists every file of the bundle including
+the images, and the bundle is signed with the GPG key --gpg-key, or the
+default key if not set. The signature is written next to the bundle as
+<bundle>.asc and has to be shipped together with it.
+
+The images are bundled as the 'docker save' archives created by the build,
+not as OCI image layouts.`,
+	Example:       "krel bundle v1.18.0 --build-dir _output",
+	Args:          cobra.ExactArgs(1),
+	SilenceUsage:  true,
+	SilenceErrors: true,
+	RunE: func(cmd *cobra.Command, args []string) error {
+		return runBundle(bundleOpts, args[0])
+	},
+}
+
+var bundlePushCmd = &cobra.Command{
+	Use:   "push <bundle> --bucket <bucket>",
+	Short: "Install a release bundle into a bucket and registry",
+	Long: `krel bundle push <bundle> --bucket <bucket>
+
+Verify the signature <bundle>.asc of the bundle with the keys of --keyring,
+extract it and verify all files against the bundle manifest. The bundled
+release is verified like 'krel verify' does, including the signatures of
+its manifest, checksums and artifacts, which have to be made with the keys
+of --keyring as well.
+
+The release artifacts are pushed to --bucket, where they can be verified
+again using 'krel verify'. The release manifest is pushed unmodified, so
+its URLs still reference the original location of the release.
+
+If --registry is set, the release images get loaded into the local docker
+daemon and pushed to the registry. Only the last path element of the image
+names is kept, k8s.gcr.io/kube-apiserver-amd64:v1.18.0 is pushed as
+<registry>/kube-apiserver-amd64:v1.18.0.`,
+	Example:       "krel bundle push kubernetes-v1.18.0-bundle.tar.gz --keyring release-keys.gpg --bucket my-mirror --registry registry.local/k8s",
+	Args:          cobra.ExactArgs(1),
+	SilenceUsage:  true,
+	SilenceErrors: true,
+	RunE: func(cmd *cobra.Command, args []string) error {
+		return runBundlePush(bundleOpts, args[0])
+	},
+}
+
+type bundleOptions struct {
+	buildDir    string
+	output      string
+	gpgKey      string
+	keyring     string
+	bucket      string
+	gcsSuffix   string
+	registry    string
+	releaseType string
+}
+
+var bundleOpts = &bundleOptions{}
+
+func init() {
+	bundleCmd.Flags().StringVar(
+		&bundleOpts.buildDir,
+		"build-dir",
+		"_output",
+		"build directory containing the staged release",
+	)
+	bundleCmd.Flags().StringVarP(
+		&bundleOpts.output,
+		"output",
+		"o",
+		"",
+		"path of the bundle (defaults to 'kubernetes-<version>-bundle.tar.gz')",
+	)
+	bundleCmd.Flags().StringVar(
+		&bundleOpts.gpgKey,
+		"gpg-key",
+		"",
+		"GPG key to sign the bundle with, the default key is used if not set",
+	)
+
+	bundlePushCmd.PersistentFlags().StringVar(
+		&bundleOpts.bucket,
+		"bucket",
+		"",
+		"GCS bucket to push the release artifacts to",
+	)
+	bundlePushCmd.PersistentFlags().StringVar(
+		&bundleOpts.keyring,
+		"keyring",
+		"",
+		"keyring with the public keys the bundle and the release have to be signed with, as exported by 'gpg --export'",
+	)
+	bundlePushCmd.PersistentFlags().StringVar(
+		&bundleOpts.releaseType,
+		"release-type",
+		"release",
+		"release type to push the version as (normally 'release', 'devel' or 'ci')",
+	)
+	bundlePushCmd.PersistentFlags().StringVar(
+		&bundleOpts.gcsSuffix,
+		"gcs-suffix",
+		"",
+		"suffix to append to the upload destination on GCS",
+	)
+	bundlePushCmd.PersistentFlags().StringVar(
+		&bundleOpts.registry,
+		"registry",
+		"",
+		"container image registry to push the release images to",
+	)
+
+	for _, flag := range []string{"bucket", "keyring"} {
+		if err := bundlePushCmd.MarkPersistentFlagRequired(flag); err != nil {
+			logrus.Fatal(err)
+		}
+	}
+
+	bundleCmd.AddCommand(bundlePushCmd)
+	rootCmd.AddCommand(bundleCmd)
+}
+
+func runBundle(opts *bundleOptions, version string) error {
+	output := opts.output
+	if output == "" {
+		output = fmt.Sprintf("kubernetes-%s-bundle.tar.gz", version)
+	}
+	if !command.Available(release.GPGExecutable) {
+		return errors.Errorf("%s is required for signing the bundle", release.GPGExecutable)
+	}
+	if err := release.CreateBundle(opts.buildDir, version, output, opts.gpgKey); err != nil {
+		return err
+	}
+	logrus.Infof("Created release bundle %s", output)
+	return nil
+}
+
+func runBundlePush(opts *bundleOptions, bundlePath string) error {
+	tmpDir, err := ioutil.TempDir("", "release-bundle-")
+	if err != nil {
+		return errors.Wrap(err, "creating temp dir")
+	}
+	defer os.RemoveAll(tmpDir)
+
+	manifest, err := release.ExtractBundle(bundlePath, tmpDir, opts.keyring)
+	if err != nil {
+		return err
+	}
+
+	gcsPath := path.Join(opts.releaseType+opts.gcsSuffix, manifest.Version)
+	location := "gs://" + path.Join(opts.bucket, gcsPath)
+
+	images, err := release.BundleImages(tmpDir)
+	if err != nil {
+		return errors.Wrap(err, "finding bundled images")
+	}
+
+	if !rootOpts.nomock {
+		logrus.Infof(
+			"Mock run - skipping. Use --nomock to push %s to %s and %d images to %q",
+			manifest.Version, location, len(images), opts.registry,
+		)
+		return nil
+	}
+	if err := requireRole(github.RoleReleaseManager, ""); err != nil {
+		return err
+	}
+
+	bucket, err := gcsBucket(opts.bucket)
+	if err != nil {
+		return err
+	}
+	uploadOpts := transferOptions(opts.bucket, gcs.DefaultConcurrency)
+	uploadOpts.Deduplicate = true
+	if err := gcs.CopyDirToGCS(
+		context.Background(), bucket,
+		filepath.Join(tmpDir, release.BundleReleasePath), gcsPath, uploadOpts,
+	); err != nil {
+		return errors.Wrapf(err, "pushing release artifacts to %s", location)
+	}
+	if err := recordAudit(manifest.Version, "upload", location); err != nil {
+		return err
+	}
+
+	if opts.registry == "" {
+		logrus.Info("No --registry set, skipping release images")
+		return nil
+	}
+	for _, tarball := range images {
+		loaded, err := release.LoadImage(tarball)
+		if err != nil {
+			return err
+		}
+		for _, image := range loaded {
+			target, err := release.PushImage(image, opts.registry)
+			if err != nil {
+				return err
+			}
+			if err := recordAudit(manifest.Version, "push", target); err != nil {
+				return err
+			}
+		}
+	}
+	logrus.Infof("Pushed release bundle %s", manifest.Version)
+	return nil
+}
@@ -26,13 +26,11 @@ import (
 	"os"
 	"path/filepath"
 	"strings"
-	"text/template"
 
 	"github.com/blang/semver"
 	"github.com/pkg/errors"
 	"github.com/sirupsen/logrus"
 	"github.com/spf13/cobra"
-	"gopkg.in/russross/blackfriday.v2"
 
 	"k8s.io/release/pkg/git"
 	"k8s.io/release/pkg/notes"
@@ -308,49 +306,18 @@ func addTocMarkers(toc string) string {
 	return fmt.Sprintf("%s\n\n%s\n%s\n", tocStart, toc, tocEnd)
 }
 
-const htmlTemplate = `<!DOCTYPE html>
-<html>
-  <head>
-    <meta charset="utf-8" />
-    <meta name="viewport" content="width=device-width" />
-    <title>{{ .Title }}</title>
-    <style type="text/css">
-      table,
-      th,
-      tr,
-      td {
-        border: 1px solid gray;
-        border-collapse: collapse;
-        padding: 5px;
-      }
-    </style>
-  </head>
-  <body>
-    {{ .Content }}
-  </body>
-</html>`
-
 func writeHTML(tag semver.Version, markdown string) error {
-	content := blackfriday.Run([]byte(markdown))
-
-	t, err := template.New("html").Parse(htmlTemplate)
+	content, err := notes.MarkdownToHTML(util.SemverToTagString(tag), markdown)
 	if err != nil {
 		return err
 	}
 
-	output := bytes.Buffer{}
-	if err := t.Execute(&output, struct {
-		Title, Content string
-	}{util.SemverToTagString(tag), string(content)}); err != nil {
-		return err
-	}
-
 	absOutputPath, err := filepath.Abs(htmlChangelogFilename(tag))
 	if err != nil {
 		return err
 	}
 	logrus.Infof("Writing single HTML to %s", absOutputPath)
-	return ioutil.WriteFile(absOutputPath, output.Bytes(), os.FileMode(0644))
+	return ioutil.WriteFile(absOutputPath, []byte(content), os.FileMode(0644))
 }
 
 func lookupRemoteReleaseNotes(branch string) (string, error) {
@@ -0,0 +1,179 @@
+/*
+Copyright 2020 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package cmd
+
+import (
+	"context"
+	"fmt"
+	"path"
+
+	"cloud.google.com/go/storage"
+	"github.com/pkg/errors"
+	"github.com/sirupsen/logrus"
+	"github.com/spf13/cobra"
+
+	"k8s.io/release/pkg/gcp/gcs"
+	"k8s.io/release/pkg/github"
+	"k8s.io/release/pkg/release"
+)
+
+// channelCmd represents the subcommand for `krel channel`
+var channelCmd = &cobra.Command{
+	Use:   "channel",
+	Short: "Show or repoint the version markers of a download channel",
+	Long: `krel channel
+
+Download channels are the version marker files on GCS, like
+release/stable.txt or release/latest-1.18.txt, which point to the current
+version of that channel. They are updated automatically by 'krel push',
+these subcommands allow to inspect or repoint them without uploading the
+release artifacts again.
+
+The channel is the name of the marker without extension, for example
+'stable', 'stable-1' or 'latest-1.18'.`,
+}
+
+var channelGetCmd = &cobra.Command{
+	Use:           "get <channel>",
+	Short:         "Print the version a channel points to",
+	Args:          cobra.ExactArgs(1),
+	SilenceUsage:  true,
+	SilenceErrors: true,
+	RunE: func(cmd *cobra.Command, args []string) error {
+		return runChannelGet(channelOpts, args[0])
+	},
+}
+
+var channelSetCmd = &cobra.Command{
+	Use:   "set <channel> <version>",
+	Short: "Point a channel to an already published version",
+	Long: `krel channel set <channel> <version>
+
+Point the channel to the version, which has to be already published to the
+bucket. Other than 'krel push', this also allows to point a channel to an
+older version.
+
+In mock mode the new channel version is only printed.`,
+	Args:          cobra.ExactArgs(2),
+	SilenceUsage:  true,
+	SilenceErrors: true,
+	RunE: func(cmd *cobra.Command, args []string) error {
+		return runChannelSet(channelOpts, args[0], args[1])
+	},
+}
+
+type channelOptions struct {
+	bucket      string
+	releaseType string
+}
+
+var channelOpts = &channelOptions{}
+
+func init() {
+	channelCmd.PersistentFlags().StringVar(
+		&channelOpts.bucket,
+		"bucket",
+		"kubernetes-release",
+		"GCS bucket of the channel",
+	)
+	channelCmd.PersistentFlags().StringVar(
+		&channelOpts.releaseType,
+		"release-type",
+		release.BuildTypeRelease,
+		"release type of the channel (normally 'release' or 'ci')",
+	)
+
+	channelCmd.AddCommand(channelGetCmd, channelSetCmd)
+	rootCmd.AddCommand(channelCmd)
+}
+
+func runChannelGet(opts *channelOptions, channel string) error {
+	marker, err := release.MarkerPath(opts.releaseType, channel)
+	if err != nil {
+		return err
+	}
+
+	bucket, err := gcsBucket(opts.bucket)
+	if err != nil {
+		return err
+	}
+
+	version, err := release.ReadMarker(context.Background(), bucket, marker)
+	if err != nil {
+		return err
+	}
+	if version == "" {
+		return errors.Errorf("channel %s does not exist in gs://%s", channel, opts.bucket)
+	}
+	fmt.Println(version)
+	return nil
+}
+
+func runChannelSet(opts *channelOptions, channel, version string) error {
+	marker, err := release.MarkerPath(opts.releaseType, channel)
+	if err != nil {
+		return err
+	}
+
+	bucket, err := gcsBucket(opts.bucket)
+	if err != nil {
+		return err
+	}
+
+	ctx := context.Background()
+	releasePath := path.Join(opts.releaseType, version)
+	exists, err := gcs.PathExists(ctx, bucket, releasePath+"/")
+	if err != nil {
+		return errors.Wrapf(err, "checking release files of %s", version)
+	}
+	if !exists {
+		return errors.Errorf(
+			"release files don't exist at gs://%s/%s", opts.bucket, releasePath,
+		)
+	}
+
+	current, err := release.ReadMarker(ctx, bucket, marker)
+	if err != nil {
+		return err
+	}
+	logrus.Infof(
+		"Channel %s currently points to %q, repointing to %s",
+		channel, current, version,
+	)
+
+	if !rootOpts.nomock {
+		logrus.Info("Mock run - skipping. Use --nomock to update the channel.")
+		return nil
+	}
+	if err := requireRole(github.RoleReleaseManager, ""); err != nil {
+		return err
+	}
+	if err := release.WriteMarker(ctx, bucket, marker, version); err != nil {
+		return err
+	}
+	return recordAudit(version, "channel", "gs://"+path.Join(opts.bucket, marker))
+}
+
+// gcsBucket returns a handle for the GCS bucket `name` using the default
+// application credentials
+func gcsBucket(name string) (*storage.BucketHandle, error) {
+	client, err := storage.NewClient(context.Background())
+	if err != nil {
+		return nil, errors.Wrap(err, "error fetching gcloud credentials... try running \"gcloud auth application-default login\"")
+	}
+	return client.Bucket(name), nil
+}
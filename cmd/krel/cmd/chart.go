@@ -0,0 +1,261 @@
+/*
+Copyright 2020 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package cmd
+
+import (
+	"context"
+	"io/ioutil"
+	"os"
+	"path"
+	"path/filepath"
+
+	"github.com/pkg/errors"
+	"github.com/sirupsen/logrus"
+	"github.com/spf13/cobra"
+
+	"k8s.io/release/pkg/command"
+	"k8s.io/release/pkg/gcp/gcs"
+	"k8s.io/release/pkg/github"
+	"k8s.io/release/pkg/release"
+	"k8s.io/release/pkg/util"
+)
+
+// chartCmd represents the subcommand for `krel chart`
+var chartCmd = &cobra.Command{
+	Use:   "chart <chart-dir> --version <version>",
+	Short: "Package and publish a Helm chart of a release",
+	Long: `krel chart <chart-dir> --version <version>
+
+Package the Helm chart in <chart-dir> into --output-dir. The chart version
+is set to the release --version without the leading "v", while the
+appVersion is the release version itself.
+
+With --pin-images the images of the chart values are replaced by the
+published release images of the --registry, referenced by digest as
+written by 'krel image pin'. With --sign a provenance file is created
+next to the package, using the GPG --key of the --keyring.
+
+The package is published to the OCI --oci-registry and to the chart
+repository in gs://<bucket>/<chart-path>, if specified. The index of the
+chart repository keeps all previously published charts. In mock mode the
+chart is only packaged locally.`,
+	Example:       "krel chart ./charts/kubernetes --version v1.18.0 --pin-images --bucket kubernetes-charts",
+	Args:          cobra.ExactArgs(1),
+	SilenceUsage:  true,
+	SilenceErrors: true,
+	RunE: func(cmd *cobra.Command, args []string) error {
+		return runChart(chartOpts, args[0])
+	},
+}
+
+type chartOptions struct {
+	version     string
+	outputDir   string
+	registry    string
+	ociRegistry string
+	bucket      string
+	chartPath   string
+	key         string
+	keyring     string
+	images      []string
+	pinImages   bool
+	sign        bool
+}
+
+var chartOpts = &chartOptions{}
+
+func init() {
+	chartCmd.PersistentFlags().StringVar(
+		&chartOpts.version,
+		"version",
+		"",
+		"release version of the chart, like v1.18.0",
+	)
+	chartCmd.PersistentFlags().StringVar(
+		&chartOpts.outputDir,
+		"output-dir",
+		filepath.Join("_output", "charts"),
+		"local directory to write the chart package to",
+	)
+	chartCmd.PersistentFlags().BoolVar(
+		&chartOpts.pinImages,
+		"pin-images",
+		false,
+		"replace the images of the chart values by the published release images",
+	)
+	chartCmd.PersistentFlags().StringVar(
+		&chartOpts.registry,
+		"registry",
+		release.DefaultImageRegistry,
+		"registry containing the images to pin",
+	)
+	chartCmd.PersistentFlags().StringSliceVar(
+		&chartOpts.images,
+		"images",
+		release.DefaultImages,
+		"images to pin",
+	)
+	chartCmd.PersistentFlags().BoolVar(
+		&chartOpts.sign,
+		"sign",
+		false,
+		"create a provenance file for the chart package",
+	)
+	chartCmd.PersistentFlags().StringVar(
+		&chartOpts.key,
+		"key",
+		"",
+		"name of the GPG key to sign the chart with",
+	)
+	chartCmd.PersistentFlags().StringVar(
+		&chartOpts.keyring,
+		"keyring",
+		"",
+		"GPG keyring containing the --key, uses the default of helm if not set",
+	)
+	chartCmd.PersistentFlags().StringVar(
+		&chartOpts.ociRegistry,
+		"oci-registry",
+		"",
+		"OCI registry to push the chart to, if specified",
+	)
+	chartCmd.PersistentFlags().StringVar(
+		&chartOpts.bucket,
+		"bucket",
+		"",
+		"GCS bucket of the chart repository to publish the chart to, if specified",
+	)
+	chartCmd.PersistentFlags().StringVar(
+		&chartOpts.chartPath,
+		"chart-path",
+		"charts",
+		"path of the chart repository in the bucket",
+	)
+
+	if err := chartCmd.MarkPersistentFlagRequired("version"); err != nil {
+		logrus.Fatal(err)
+	}
+
+	rootCmd.AddCommand(chartCmd)
+}
+
+func runChart(opts *chartOptions, chartDir string) error {
+	if _, err := util.TagStringToSemver(opts.version); err != nil {
+		return errors.Wrapf(err, "invalid version tag %q", opts.version)
+	}
+	if opts.sign && opts.key == "" {
+		return errors.New("signing the chart with --sign requires a --key")
+	}
+	if !command.Available(release.HelmExecutable) {
+		return errors.Errorf("%s is required for packaging charts", release.HelmExecutable)
+	}
+
+	chartOptions := &release.ChartOptions{
+		Version: opts.version,
+		Sign:    opts.sign,
+		Key:     opts.key,
+		Keyring: opts.keyring,
+	}
+	if opts.pinImages {
+		if !command.Available(release.GCloudExecutable) {
+			return errors.Errorf("%s is required for pinning images", release.GCloudExecutable)
+		}
+		pinned, err := release.PinImages(opts.registry, opts.images, opts.version)
+		if err != nil {
+			return err
+		}
+		chartOptions.PinnedImages = pinned
+	}
+
+	if err := util.RemoveAndReplaceDir(opts.outputDir); err != nil {
+		return errors.Wrapf(err, "Unable to replace output directory %s", opts.outputDir)
+	}
+	chartPackage, err := release.PackageChart(chartDir, opts.outputDir, chartOptions)
+	if err != nil {
+		return err
+	}
+	logrus.Infof("Packaged chart to %s", chartPackage)
+
+	if opts.ociRegistry == "" && opts.bucket == "" {
+		return nil
+	}
+	if !rootOpts.nomock {
+		logrus.Infof("Mock run - skipping. Use --nomock to publish the chart %s", chartPackage)
+		return nil
+	}
+	if err := requireRole(github.RoleReleaseManager, ""); err != nil {
+		return err
+	}
+
+	if opts.ociRegistry != "" {
+		if err := release.PushChart(chartPackage, opts.ociRegistry); err != nil {
+			return err
+		}
+		if err := recordAudit(opts.version, "push", opts.ociRegistry); err != nil {
+			return err
+		}
+	}
+
+	if opts.bucket != "" {
+		if err := publishChartRepository(opts); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// publishChartRepository uploads the packaged chart together with the
+// updated index to the chart repository in the bucket
+func publishChartRepository(opts *chartOptions) error {
+	ctx := context.Background()
+	bucket, err := gcsBucket(opts.bucket)
+	if err != nil {
+		return err
+	}
+
+	tempDir, err := ioutil.TempDir("", "chart-index-")
+	if err != nil {
+		return errors.Wrap(err, "creating temp directory")
+	}
+	defer os.RemoveAll(tempDir)
+
+	transferOpts := transferOptions(opts.bucket, gcs.DefaultConcurrency)
+	if err := gcs.DownloadFiles(
+		ctx, bucket, opts.chartPath, []string{release.ChartRepositoryIndex},
+		tempDir, transferOpts,
+	); err != nil {
+		return errors.Wrap(err, "downloading the chart repository index")
+	}
+
+	existingIndex := filepath.Join(tempDir, release.ChartRepositoryIndex)
+	if !util.Exists(existingIndex) {
+		logrus.Infof("Creating new chart repository in gs://%s/%s", opts.bucket, opts.chartPath)
+		existingIndex = ""
+	}
+	url := "https://storage.googleapis.com/" + opts.bucket + "/" + opts.chartPath
+	if err := release.IndexChartRepository(opts.outputDir, url, existingIndex); err != nil {
+		return err
+	}
+
+	if err := gcs.CopyDirToGCS(
+		ctx, bucket, opts.outputDir, opts.chartPath, transferOpts,
+	); err != nil {
+		return errors.Wrap(err, "publishing the chart")
+	}
+	logrus.Infof("Published chart to gs://%s/%s", opts.bucket, opts.chartPath)
+	return recordAudit(opts.version, "upload", "gs://"+path.Join(opts.bucket, opts.chartPath))
+}
@@ -0,0 +1,203 @@
+/*
+Copyright 2020 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package cmd
+
+import (
+	"context"
+	"fmt"
+	"strconv"
+
+	"github.com/pkg/errors"
+	"github.com/sirupsen/logrus"
+	"github.com/spf13/cobra"
+
+	"k8s.io/release/pkg/git"
+	"k8s.io/release/pkg/github"
+	"k8s.io/release/pkg/util"
+)
+
+// cherryPickCmd represents the subcommand for `krel cherry-pick`
+var cherryPickCmd = &cobra.Command{
+	Use:   "cherry-pick <pr> --branch <release-branch> --fork <github-user>",
+	Short: "Cherry pick a merged PR onto a release branch",
+	Long: `krel cherry-pick <pr> --branch <release-branch> --fork <github-user>
+
+Apply the merge commit of the PR onto the release branch, push the result
+to the fork and open the cherry pick PR against the release branch. The
+PR uses the standard cherry pick template, which references the original
+PR and carries over its release note.
+
+If the cherry pick does not apply cleanly, the conflicts have to be
+resolved manually in the repository (--repo).
+
+In mock mode the cherry pick is only pushed with --dry-run and no PR is
+opened.`,
+	Example:       "krel cherry-pick 12345 --branch release-1.18 --fork my-user",
+	Args:          cobra.ExactArgs(1),
+	SilenceUsage:  true,
+	SilenceErrors: true,
+	RunE: func(cmd *cobra.Command, args []string) error {
+		number, err := strconv.Atoi(args[0])
+		if err != nil {
+			return errors.Wrapf(err, "invalid PR number %q", args[0])
+		}
+		return runCherryPick(cherryPickOpts, number)
+	},
+}
+
+type cherryPickOptions struct {
+	branch      string
+	fork        string
+	githubOrg   string
+	githubRepo  string
+	githubToken string
+}
+
+var cherryPickOpts = &cherryPickOptions{}
+
+func init() {
+	cherryPickCmd.PersistentFlags().StringVar(
+		&cherryPickOpts.branch,
+		"branch",
+		"",
+		"release branch to cherry pick the PR onto",
+	)
+	cherryPickCmd.PersistentFlags().StringVar(
+		&cherryPickOpts.fork,
+		"fork",
+		"",
+		"GitHub user or organization of the fork to push the cherry pick to",
+	)
+	cherryPickCmd.PersistentFlags().StringVar(
+		&cherryPickOpts.githubOrg,
+		"org",
+		git.DefaultGithubOrg,
+		"GitHub organization of the repository",
+	)
+	cherryPickCmd.PersistentFlags().StringVar(
+		&cherryPickOpts.githubRepo,
+		"github-repo",
+		git.DefaultGithubRepo,
+		"GitHub repository of the PR",
+	)
+	cherryPickCmd.PersistentFlags().StringVarP(
+		&cherryPickOpts.githubToken,
+		"github-token",
+		"g",
+		util.EnvDefault("GITHUB_TOKEN", ""),
+		"a GitHub token allowed to open PRs against the repository",
+	)
+
+	for _, flag := range []string{"branch", "fork"} {
+		if err := cherryPickCmd.MarkPersistentFlagRequired(flag); err != nil {
+			logrus.Fatal(err)
+		}
+	}
+
+	rootCmd.AddCommand(cherryPickCmd)
+}
+
+func runCherryPick(opts *cherryPickOptions, number int) error {
+	if !git.IsReleaseBranch(opts.branch) || opts.branch == git.Master {
+		return errors.Errorf("%s is not a release branch", opts.branch)
+	}
+	if opts.githubToken == "" {
+		return errors.New("a GitHub token is required, use --github-token or $GITHUB_TOKEN")
+	}
+	if err := requireRole(github.RoleBranchManager, opts.githubToken); err != nil {
+		return err
+	}
+
+	ctx := context.Background()
+	client := github.New(ctx, opts.githubToken)
+	pr, err := github.GetMergedPullRequest(
+		ctx, client, opts.githubOrg, opts.githubRepo, number,
+	)
+	if err != nil {
+		return err
+	}
+
+	repo, err := git.CloneOrOpenGitHubRepo(
+		rootOpts.repoPath, opts.githubOrg, opts.githubRepo, true,
+	)
+	if err != nil {
+		return err
+	}
+
+	if !rootOpts.nomock {
+		logrus.Info("Using dry mode, which does not modify any remote content")
+		repo.SetDry()
+	}
+
+	if rootOpts.cleanup {
+		defer repo.Cleanup() // nolint: errcheck
+	}
+
+	if err := repo.HasRemoteBranch(opts.branch); err != nil {
+		return err
+	}
+
+	headBranch := fmt.Sprintf("automated-cherry-pick-of-#%d-%s", number, opts.branch)
+	logrus.Infof("Creating branch %s from %s", headBranch, opts.branch)
+	if err := repo.Checkout("-B", headBranch, git.Remotify(opts.branch)); err != nil {
+		return errors.Wrapf(err, "checking out %s", opts.branch)
+	}
+
+	logrus.Infof("Cherry picking %s of PR #%d", pr.GetMergeCommitSHA(), number)
+	if err := repo.CherryPick(pr.GetMergeCommitSHA()); err != nil {
+		return err
+	}
+
+	logrus.Infof("Pushing %s to the fork of %s", headBranch, opts.fork)
+	if err := repo.PushToRemote(
+		git.GetRepoURL(opts.fork, opts.githubRepo, true), headBranch,
+	); err != nil {
+		return errors.Wrapf(err, "pushing %s", headBranch)
+	}
+	if rootOpts.nomock {
+		if err := recordAudit(
+			opts.branch, "push", opts.fork+"/"+opts.githubRepo+":"+headBranch,
+		); err != nil {
+			return err
+		}
+	}
+
+	if !rootOpts.nomock {
+		logrus.Infof(
+			"Mock run - skipping. Use --nomock to open the PR %q:\n%s",
+			github.CherryPickTitle(pr),
+			github.CherryPickBody(pr, opts.branch),
+		)
+		return nil
+	}
+
+	result, err := github.CreateCherryPickPullRequest(ctx, client, &github.CherryPickOptions{
+		Owner:      opts.githubOrg,
+		Repo:       opts.githubRepo,
+		Branch:     opts.branch,
+		Fork:       opts.fork,
+		HeadBranch: headBranch,
+	}, pr)
+	if err != nil {
+		return err
+	}
+	if err := recordAudit(opts.branch, "cherry-pick-pr", result.GetHTMLURL()); err != nil {
+		return err
+	}
+	logrus.Infof("Opened cherry pick PR %s", result.GetHTMLURL())
+	return nil
+}
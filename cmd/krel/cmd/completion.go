@@ -0,0 +1,64 @@
+/*
+Copyright 2020 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package cmd
+
+import (
+	"io"
+	"os"
+
+	"github.com/pkg/errors"
+	"github.com/spf13/cobra"
+)
+
+// completionCmd represents the subcommand for `krel completion`
+var completionCmd = &cobra.Command{
+	Use:   "completion bash|zsh",
+	Short: "Output the shell completion code of krel",
+	Long: `krel completion bash|zsh
+
+Output the completion code of all krel commands and flags for the
+specified shell. To load the completion into the current bash session:
+
+  source <(krel completion bash)
+
+For zsh, write the output to a file named _krel in a directory of your
+$fpath, like:
+
+  krel completion zsh > "${fpath[1]}/_krel"`,
+	Example:       "krel completion bash > /etc/bash_completion.d/krel",
+	Args:          cobra.ExactArgs(1),
+	ValidArgs:     []string{"bash", "zsh"},
+	SilenceUsage:  true,
+	SilenceErrors: true,
+	RunE: func(cmd *cobra.Command, args []string) error {
+		return runCompletion(os.Stdout, args[0])
+	},
+}
+
+func init() {
+	rootCmd.AddCommand(completionCmd)
+}
+
+func runCompletion(w io.Writer, shell string) error {
+	switch shell {
+	case "bash":
+		return rootCmd.GenBashCompletion(w)
+	case "zsh":
+		return rootCmd.GenZshCompletion(w)
+	}
+	return errors.Errorf("unsupported shell %q, use bash or zsh", shell)
+}
@@ -0,0 +1,125 @@
+/*
+Copyright 2020 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package cmd
+
+import (
+	"github.com/pkg/errors"
+	"github.com/sirupsen/logrus"
+	"github.com/spf13/cobra"
+
+	"k8s.io/release/pkg/command"
+	"k8s.io/release/pkg/release"
+)
+
+// deltaCmd represents the subcommand for `krel delta`
+var deltaCmd = &cobra.Command{
+	Use:   "delta",
+	Short: "Work with the binary deltas published with a release",
+}
+
+var deltaApplyCmd = &cobra.Command{
+	Use:   "apply --from <tarball> --delta <delta> --output <tarball>",
+	Short: "Apply a downloaded binary delta to a tarball of the previous release",
+	Long: `krel delta apply --from <tarball> --delta <delta> --output <tarball>
+
+Apply a binary delta published by 'krel push --delta-from' to the tarball
+--from of the previous release and write the resulting tarball to --output.
+
+The deltas are published below ` + release.DeltaPath + `/<previous version>/ of a
+release and transform the uncompressed tarballs, which means --from is the
+published .tar.gz of the previous release and --output an uncompressed
+.tar. The result is verified against the ` + release.DeltaTargetsFile + `
+file, which has to be downloaded next to the delta. The output is removed
+if it does not match.
+
+If --keyring is set, the signatures of the delta and the
+` + release.DeltaTargetsFile + ` file are verified first, which have to be
+downloaded next to them as well.`,
+	Example: "krel delta apply --from kubernetes-server-linux-amd64.tar.gz " +
+		"--delta kubernetes-server-linux-amd64.tar.zst-patch " +
+		"--output kubernetes-server-linux-amd64.tar --keyring pubring.gpg",
+	SilenceUsage:  true,
+	SilenceErrors: true,
+	RunE: func(cmd *cobra.Command, args []string) error {
+		return runDeltaApply(deltaApplyOpts)
+	},
+}
+
+type deltaApplyOptions struct {
+	from    string
+	delta   string
+	output  string
+	keyring string
+}
+
+var deltaApplyOpts = &deltaApplyOptions{}
+
+func init() {
+	deltaApplyCmd.PersistentFlags().StringVar(
+		&deltaApplyOpts.from,
+		"from",
+		"",
+		"tarball of the previous release to apply the delta to",
+	)
+	deltaApplyCmd.PersistentFlags().StringVar(
+		&deltaApplyOpts.delta,
+		"delta",
+		"",
+		"the downloaded binary delta",
+	)
+	deltaApplyCmd.PersistentFlags().StringVar(
+		&deltaApplyOpts.output,
+		"output",
+		"",
+		"path to write the uncompressed tarball of the new release to",
+	)
+	deltaApplyCmd.PersistentFlags().StringVar(
+		&deltaApplyOpts.keyring,
+		"keyring",
+		"",
+		"GPG keyring with the public keys to verify the signatures of the delta with",
+	)
+
+	for _, flag := range []string{"from", "delta", "output"} {
+		if err := deltaApplyCmd.MarkPersistentFlagRequired(flag); err != nil {
+			logrus.Fatal(err)
+		}
+	}
+
+	deltaCmd.AddCommand(deltaApplyCmd)
+	rootCmd.AddCommand(deltaCmd)
+}
+
+func runDeltaApply(opts *deltaApplyOptions) error {
+	if !command.Available(release.ZstdExecutable) {
+		return errors.Errorf("%s is required to apply binary deltas", release.ZstdExecutable)
+	}
+	if opts.keyring != "" && !command.Available(release.GPGExecutable) {
+		return errors.Errorf("%s is required to verify signatures with --keyring", release.GPGExecutable)
+	}
+	if opts.keyring == "" {
+		logrus.Warn("No --keyring specified, the signatures of the delta are not verified")
+	}
+
+	if err := release.ApplyTarballDelta(
+		opts.from, opts.delta, opts.output, opts.keyring,
+	); err != nil {
+		return errors.Wrapf(err, "applying delta %s to %s", opts.delta, opts.from)
+	}
+	logrus.Infof("Wrote verified tarball %s", opts.output)
+	return nil
+}
@@ -0,0 +1,113 @@
+/*
+Copyright 2020 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package cmd
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+
+	"github.com/pkg/errors"
+	"github.com/spf13/cobra"
+
+	"k8s.io/release/pkg/git"
+	"k8s.io/release/pkg/notes"
+)
+
+// depsCmd represents the subcommand for `krel deps`
+var depsCmd = &cobra.Command{
+	Use:   "deps",
+	Short: "Inspect the Go module dependencies of releases",
+}
+
+var depsDiffCmd = &cobra.Command{
+	Use:   "diff <from> <to>",
+	Short: "Show the dependency changes between two revisions",
+	Long: `krel deps diff <from> <to>
+
+Compare the go.mod requirements of the two revisions, for example tags
+like v1.18.0 and v1.19.0, and print the added, changed and removed
+dependencies. The replace directives are applied before comparing, so
+a dependency pinned in the replace block is reported with the pinned
+version. The markdown output contains one table per type of change and
+can be used as-is in the release notes.`,
+	Example:       "krel deps diff v1.18.0 v1.19.0",
+	Args:          cobra.ExactArgs(2),
+	SilenceUsage:  true,
+	SilenceErrors: true,
+	RunE: func(cmd *cobra.Command, args []string) error {
+		return runDepsDiff(depsOpts, args[0], args[1])
+	},
+}
+
+type depsOptions struct {
+	githubOrg  string
+	githubRepo string
+	format     string
+}
+
+var depsOpts = &depsOptions{}
+
+func init() {
+	depsCmd.PersistentFlags().StringVar(
+		&depsOpts.githubOrg,
+		"org",
+		git.DefaultGithubOrg,
+		"GitHub organization of the repository",
+	)
+	depsCmd.PersistentFlags().StringVar(
+		&depsOpts.githubRepo,
+		"github-repo",
+		git.DefaultGithubRepo,
+		"GitHub repository to compare the revisions of",
+	)
+	depsDiffCmd.PersistentFlags().StringVar(
+		&depsOpts.format,
+		"format",
+		"markdown",
+		"output format (options: markdown, json)",
+	)
+
+	depsCmd.AddCommand(depsDiffCmd)
+	rootCmd.AddCommand(depsCmd)
+}
+
+func runDepsDiff(opts *depsOptions, from, to string) error {
+	if opts.format != "markdown" && opts.format != "json" {
+		return errors.Errorf("%q is an unsupported format", opts.format)
+	}
+
+	repo, err := git.CloneOrOpenGitHubRepo(
+		rootOpts.repoPath, opts.githubOrg, opts.githubRepo, false,
+	)
+	if err != nil {
+		return err
+	}
+
+	changes, err := notes.DependenciesBetween(repo, from, to)
+	if err != nil {
+		return errors.Wrap(err, "comparing dependencies")
+	}
+
+	if opts.format == "json" {
+		enc := json.NewEncoder(os.Stdout)
+		enc.SetIndent("", "  ")
+		return errors.Wrap(enc.Encode(changes), "encoding dependency changes")
+	}
+	fmt.Print(changes.Markdown())
+	return nil
+}
@@ -0,0 +1,169 @@
+/*
+Copyright 2020 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package cmd
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"os"
+	"path"
+
+	"github.com/pkg/errors"
+	"github.com/spf13/cobra"
+
+	"k8s.io/release/pkg/gcp/gcs"
+	"k8s.io/release/pkg/git"
+	"k8s.io/release/pkg/notes"
+	"k8s.io/release/pkg/release"
+)
+
+// diffCmd represents the subcommand for `krel diff`
+var diffCmd = &cobra.Command{
+	Use:   "diff <from> <to>",
+	Short: "Compare the published artifacts of two releases",
+	Long: `krel diff <from> <to>
+
+Compare the release manifests of two versions pushed by 'krel push' and
+print the added and removed artifacts, as well as the artifacts whose
+content or signature changed, including their size difference.
+
+With --dependencies the Go module dependency changes between both
+versions are included, which requires a clone of the repository (--repo).`,
+	Example:       "krel diff v1.18.0 v1.18.1 --format json",
+	Args:          cobra.ExactArgs(2),
+	SilenceUsage:  true,
+	SilenceErrors: true,
+	RunE: func(cmd *cobra.Command, args []string) error {
+		return runDiff(diffOpts, args[0], args[1])
+	},
+}
+
+type diffOptions struct {
+	bucket       string
+	releaseType  string
+	gcsSuffix    string
+	format       string
+	githubOrg    string
+	githubRepo   string
+	dependencies bool
+}
+
+var diffOpts = &diffOptions{}
+
+// releaseDiff is the JSON output of `krel diff`
+type releaseDiff struct {
+	*release.ManifestDiff
+	Dependencies *notes.DependencyChanges `json:"dependencies,omitempty"`
+}
+
+func init() {
+	diffCmd.PersistentFlags().StringVar(
+		&diffOpts.bucket,
+		"bucket",
+		"kubernetes-release",
+		"GCS bucket the releases were pushed to",
+	)
+	diffCmd.PersistentFlags().StringVar(
+		&diffOpts.releaseType,
+		"release-type",
+		"release",
+		"release type the versions were pushed as (normally 'release', 'devel' or 'ci')",
+	)
+	diffCmd.PersistentFlags().StringVar(
+		&diffOpts.gcsSuffix,
+		"gcs-suffix",
+		"",
+		"suffix which was appended to the upload destination on GCS",
+	)
+	diffCmd.PersistentFlags().StringVar(
+		&diffOpts.format,
+		"format",
+		"text",
+		"output format (options: text, json)",
+	)
+	diffCmd.PersistentFlags().BoolVar(
+		&diffOpts.dependencies,
+		"dependencies",
+		false,
+		"include the Go module dependency changes between both versions",
+	)
+	diffCmd.PersistentFlags().StringVar(
+		&diffOpts.githubOrg,
+		"org",
+		git.DefaultGithubOrg,
+		"GitHub organization of the repository",
+	)
+	diffCmd.PersistentFlags().StringVar(
+		&diffOpts.githubRepo,
+		"github-repo",
+		git.DefaultGithubRepo,
+		"GitHub repository to compare the dependencies of",
+	)
+
+	rootCmd.AddCommand(diffCmd)
+}
+
+func runDiff(opts *diffOptions, from, to string) error {
+	if opts.format != "text" && opts.format != "json" {
+		return errors.Errorf("%q is an unsupported format", opts.format)
+	}
+
+	ctx := context.Background()
+	bucket, err := gcsBucket(opts.bucket)
+	if err != nil {
+		return err
+	}
+
+	manifests := []*release.Manifest{}
+	for _, version := range []string{from, to} {
+		gcsPath := path.Join(opts.releaseType+opts.gcsSuffix, version)
+		manifest, err := release.ReadManifest(func(name string) (io.ReadCloser, error) {
+			return gcs.NewReader(ctx, bucket, path.Join(gcsPath, name))
+		})
+		if err != nil {
+			return errors.Wrapf(err, "reading release manifest of %s", version)
+		}
+		manifests = append(manifests, manifest)
+	}
+
+	res := &releaseDiff{ManifestDiff: release.DiffManifests(manifests[0], manifests[1])}
+	if opts.dependencies {
+		repo, err := git.CloneOrOpenGitHubRepo(
+			rootOpts.repoPath, opts.githubOrg, opts.githubRepo, false,
+		)
+		if err != nil {
+			return err
+		}
+		res.Dependencies, err = notes.DependenciesBetween(repo, from, to)
+		if err != nil {
+			return errors.Wrap(err, "comparing dependencies")
+		}
+	}
+
+	if opts.format == "json" {
+		enc := json.NewEncoder(os.Stdout)
+		enc.SetIndent("", "  ")
+		return errors.Wrap(enc.Encode(res), "encoding release differences")
+	}
+	fmt.Print(res.Text())
+	if res.Dependencies != nil {
+		fmt.Print("\n" + res.Dependencies.Markdown())
+	}
+	return nil
+}
@@ -24,6 +24,7 @@ import (
 	"github.com/spf13/cobra"
 
 	kgit "k8s.io/release/pkg/git"
+	"k8s.io/release/pkg/github"
 	"k8s.io/release/pkg/util"
 )
 
@@ -76,6 +77,8 @@ func runFf(opts *ffOptions) error {
 	if !rootOpts.nomock {
 		logrus.Info("Using dry mode, which does not modify any remote content")
 		repo.SetDry()
+	} else if err := requireRole(github.RoleBranchManager, ""); err != nil {
+		return err
 	}
 
 	logrus.Infof("Checking if %q is a release branch", branch)
@@ -0,0 +1,200 @@
+/*
+Copyright 2020 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package cmd
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"path"
+	"strings"
+	"text/tabwriter"
+	"time"
+
+	"github.com/pkg/errors"
+	"github.com/sirupsen/logrus"
+	"github.com/spf13/cobra"
+
+	"k8s.io/release/pkg/gcp/gcs"
+	"k8s.io/release/pkg/github"
+	"k8s.io/release/pkg/release"
+)
+
+// gcCmd represents the subcommand for `krel gc`
+var gcCmd = &cobra.Command{
+	Use:   "gc",
+	Short: "Delete old staged builds from the staging bucket",
+	Long: `krel gc
+
+Garbage collect the builds staged by anago in gs://<bucket>/stage. A
+staged build is deleted if it is superseded by a version released for its
+minor version, or if it has not been updated for longer than --retention.
+Builds which are still needed by a running release process can be
+protected with --keep.
+
+In mock mode the staged builds are only listed together with the action
+which would be taken.`,
+	SilenceUsage:  true,
+	SilenceErrors: true,
+	RunE: func(cmd *cobra.Command, args []string) error {
+		return runGC(gcOpts)
+	},
+}
+
+type gcOptions struct {
+	bucket        string
+	releaseBucket string
+	keep          []string
+	retention     time.Duration
+	concurrency   int
+}
+
+var gcOpts = &gcOptions{}
+
+func init() {
+	gcCmd.PersistentFlags().StringVar(
+		&gcOpts.bucket,
+		"bucket",
+		"kubernetes-release-gcb",
+		"GCS bucket containing the staged builds",
+	)
+	gcCmd.PersistentFlags().StringVar(
+		&gcOpts.releaseBucket,
+		"release-bucket",
+		"kubernetes-release",
+		"GCS bucket containing the published releases",
+	)
+	gcCmd.PersistentFlags().StringSliceVar(
+		&gcOpts.keep,
+		"keep",
+		[]string{},
+		"build version to never delete, can be specified multiple times",
+	)
+	gcCmd.PersistentFlags().DurationVar(
+		&gcOpts.retention,
+		"retention",
+		30*24*time.Hour,
+		"time after which not updated staged builds are deleted, 0 to disable",
+	)
+	gcCmd.PersistentFlags().IntVar(
+		&gcOpts.concurrency,
+		"concurrency",
+		gcs.DefaultConcurrency,
+		"number of parallel deletions",
+	)
+
+	rootCmd.AddCommand(gcCmd)
+}
+
+func runGC(opts *gcOptions) error {
+	bucket, err := gcsBucket(opts.bucket)
+	if err != nil {
+		return err
+	}
+	releaseBucket, err := gcsBucket(opts.releaseBucket)
+	if err != nil {
+		return err
+	}
+
+	ctx := context.Background()
+	dirs, err := gcs.ListDirs(ctx, bucket, release.StagedBuildsPath+"/")
+	if err != nil {
+		return errors.Wrap(err, "listing staged builds")
+	}
+
+	policy := &release.GCPolicy{
+		Now:       time.Now(),
+		Keep:      opts.keep,
+		Retention: opts.retention,
+	}
+
+	collect := []string{}
+	collectedBuilds := map[string]string{}
+	w := tabwriter.NewWriter(os.Stdout, 0, 0, 2, ' ', 0)
+	fmt.Fprintln(w, "BUILD\tUPDATED\tOBJECTS\tACTION")
+	for _, dir := range dirs {
+		buildVersion := path.Base(strings.TrimSuffix(dir, "/"))
+		objects, err := gcs.ListObjects(ctx, bucket, dir)
+		if err != nil {
+			return err
+		}
+
+		var updated time.Time
+		for _, object := range objects {
+			if object.Updated.After(updated) {
+				updated = object.Updated
+			}
+		}
+
+		published, err := release.LatestPublished(ctx, releaseBucket, buildVersion)
+		if err != nil {
+			logrus.Warnf("Skipping staged build %s: %v", dir, err)
+			continue
+		}
+
+		reason, err := policy.Collect(buildVersion, updated, published)
+		if err != nil {
+			logrus.Warnf("Skipping staged build %s: %v", dir, err)
+			continue
+		}
+
+		action := "keep"
+		if reason != "" {
+			action = "delete, " + reason
+			collectedBuilds[buildVersion] = dir
+			for _, object := range objects {
+				collect = append(collect, object.Name)
+			}
+		}
+		fmt.Fprintf(w, "%s\t%s\t%d\t%s\n",
+			buildVersion, updated.Format(time.RFC3339), len(objects), action,
+		)
+	}
+	if err := w.Flush(); err != nil {
+		return errors.Wrap(err, "writing staged builds")
+	}
+
+	if len(collect) == 0 {
+		logrus.Info("Nothing to delete")
+		return nil
+	}
+
+	if !rootOpts.nomock {
+		logrus.Infof(
+			"Mock run - skipping the deletion of %d objects. Use --nomock to delete them.",
+			len(collect),
+		)
+		return nil
+	}
+	if err := requireRole(github.RoleReleaseManager, ""); err != nil {
+		return err
+	}
+
+	logrus.Infof("Deleting %d objects from gs://%s", len(collect), opts.bucket)
+	deleteOpts := transferOptions(opts.bucket, opts.concurrency)
+	if err := gcs.DeleteObjects(ctx, bucket, collect, deleteOpts); err != nil {
+		return errors.Wrap(err, "deleting staged builds")
+	}
+	for buildVersion, dir := range collectedBuilds {
+		if err := recordAudit(
+			buildVersion, "delete", "gs://"+path.Join(opts.bucket, dir),
+		); err != nil {
+			return err
+		}
+	}
+	return nil
+}
@@ -40,6 +40,8 @@ type gcbmgrOptions struct {
 	releaseType  string
 	buildVersion string
 	gcpUser      string
+	schedule     scheduleCheckOptions
+	toolchain    toolchainCheckOptions
 }
 
 var (
@@ -138,6 +140,8 @@ func init() {
 		"",
 		"If provided, this will be used as the GCP_USER_TAG.",
 	)
+	addScheduleCheckFlags(gcbmgrCmd, &gcbmgrOpts.schedule)
+	addToolchainCheckFlags(gcbmgrCmd, &gcbmgrOpts.toolchain)
 
 	rootCmd.AddCommand(gcbmgrCmd)
 }
@@ -187,6 +191,21 @@ func runGcbmgr() error {
 	}
 
 	if rootOpts.nomock {
+		// The version is only known within the job, so it can only be
+		// checked that a release is due at all
+		if (gcbmgrOpts.stage || gcbmgrOpts.release) && gcbmgrOpts.schedule.file != "" {
+			if err := checkScheduleDue(&gcbmgrOpts.schedule); err != nil {
+				return err
+			}
+		}
+		if gcbmgrOpts.stage || gcbmgrOpts.release {
+			if err := checkKubeCrossToolchain(
+				&gcbmgrOpts.toolchain, gcbSubs["KUBE_CROSS_VERSION"],
+			); err != nil {
+				return err
+			}
+		}
+
 		// TODO: Consider a '--yes' flag so we can mock this
 		_, nomockSubmit, askErr := util.Ask(
 			"Really submit a --nomock release job against the $RELEASE_BRANCH branch?",
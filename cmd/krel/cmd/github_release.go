@@ -0,0 +1,187 @@
+/*
+Copyright 2020 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package cmd
+
+import (
+	"context"
+	"io/ioutil"
+
+	"github.com/pkg/errors"
+	"github.com/sirupsen/logrus"
+	"github.com/spf13/cobra"
+
+	"k8s.io/release/pkg/git"
+	"k8s.io/release/pkg/github"
+	"k8s.io/release/pkg/util"
+)
+
+// githubReleaseCmd represents the subcommand for `krel github-release`
+var githubReleaseCmd = &cobra.Command{
+	Use:   "github-release",
+	Short: "Create or update the GitHub release page for a tag",
+	Long: `krel github-release
+
+Create the GitHub release for an existing tag or update it if it already
+exists. The release body is read from --notes-file, which is usually the
+markdown generated by the release notes tooling. All files provided via
+--asset are uploaded to the release, where assets which are already
+completely uploaded are skipped.
+
+In mock mode the release is only posted as a draft if --draft is set,
+otherwise the step is skipped.`,
+	SilenceUsage:  true,
+	SilenceErrors: true,
+	RunE: func(cmd *cobra.Command, args []string) error {
+		return runGithubRelease(githubReleaseOpts)
+	},
+}
+
+type githubReleaseOptions struct {
+	githubOrg      string
+	githubRepo     string
+	githubToken    string
+	tag            string
+	target         string
+	notesFile      string
+	assets         []string
+	uploadAttempts int
+	draft          bool
+}
+
+var githubReleaseOpts = &githubReleaseOptions{}
+
+func init() {
+	githubReleaseCmd.PersistentFlags().StringVar(
+		&githubReleaseOpts.githubOrg,
+		"org",
+		git.DefaultGithubOrg,
+		"GitHub organization of the repository",
+	)
+	githubReleaseCmd.PersistentFlags().StringVar(
+		&githubReleaseOpts.githubRepo,
+		"github-repo",
+		git.DefaultGithubRepo,
+		"GitHub repository to post the release to",
+	)
+	githubReleaseCmd.PersistentFlags().StringVarP(
+		&githubReleaseOpts.githubToken,
+		"github-token",
+		"g",
+		util.EnvDefault("GITHUB_TOKEN", ""),
+		"a GitHub token with write access to the repository",
+	)
+	githubReleaseCmd.PersistentFlags().StringVarP(
+		&githubReleaseOpts.tag,
+		"tag",
+		"t",
+		"",
+		"existing tag to create the release for",
+	)
+	githubReleaseCmd.PersistentFlags().StringVar(
+		&githubReleaseOpts.target,
+		"target",
+		git.Master,
+		"branch the tag was cut from",
+	)
+	githubReleaseCmd.PersistentFlags().StringVar(
+		&githubReleaseOpts.notesFile,
+		"notes-file",
+		"",
+		"markdown file used as release body",
+	)
+	githubReleaseCmd.PersistentFlags().StringSliceVar(
+		&githubReleaseOpts.assets,
+		"asset",
+		[]string{},
+		"file to upload as release asset, can be specified multiple times",
+	)
+	githubReleaseCmd.PersistentFlags().IntVar(
+		&githubReleaseOpts.uploadAttempts,
+		"upload-attempts",
+		github.DefaultUploadAttempts,
+		"number of times a single asset upload is tried",
+	)
+	githubReleaseCmd.PersistentFlags().BoolVar(
+		&githubReleaseOpts.draft,
+		"draft",
+		false,
+		"post the release as draft",
+	)
+
+	if err := githubReleaseCmd.MarkPersistentFlagRequired("tag"); err != nil {
+		logrus.Fatal(err)
+	}
+
+	rootCmd.AddCommand(githubReleaseCmd)
+}
+
+func runGithubRelease(opts *githubReleaseOptions) error {
+	// Only post non-draft releases when running with --nomock
+	draft := opts.draft || !rootOpts.nomock
+	if !rootOpts.nomock && !opts.draft {
+		logrus.Info("Mock run - skipping. Use --draft to force.")
+		return nil
+	}
+
+	if opts.githubToken == "" {
+		return errors.New("a GitHub token is required, use --github-token or $GITHUB_TOKEN")
+	}
+	if err := requireRole(github.RoleReleaseManager, opts.githubToken); err != nil {
+		return err
+	}
+
+	tag, err := util.TagStringToSemver(opts.tag)
+	if err != nil {
+		return errors.Wrapf(err, "no valid tag: %v", opts.tag)
+	}
+
+	body := ""
+	if opts.notesFile != "" {
+		content, err := ioutil.ReadFile(opts.notesFile)
+		if err != nil {
+			return errors.Wrapf(err, "reading release notes %s", opts.notesFile)
+		}
+		body = string(content)
+	}
+
+	ctx := context.Background()
+	release, err := github.UpdateRelease(ctx, github.New(ctx, opts.githubToken), &github.ReleaseOptions{
+		Owner:           opts.githubOrg,
+		Repo:            opts.githubRepo,
+		Tag:             opts.tag,
+		TargetCommitish: opts.target,
+		Name:            opts.tag,
+		Body:            body,
+		Assets:          opts.assets,
+		UploadAttempts:  opts.uploadAttempts,
+		Draft:           draft,
+		Prerelease:      len(tag.Pre) > 0,
+	})
+	if err != nil {
+		return errors.Wrapf(err, "updating GitHub release %s", opts.tag)
+	}
+	action := "github-release"
+	if draft {
+		action = "github-release-draft"
+	}
+	if err := recordAudit(opts.tag, action, release.GetHTMLURL()); err != nil {
+		return err
+	}
+
+	logrus.Infof("Release %s available at %s", opts.tag, release.GetHTMLURL())
+	return nil
+}
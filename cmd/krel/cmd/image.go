@@ -0,0 +1,483 @@
+/*
+Copyright 2020 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package cmd
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"os"
+	"path"
+	"path/filepath"
+	"strings"
+	"text/tabwriter"
+
+	"cloud.google.com/go/storage"
+	"github.com/pkg/errors"
+	"github.com/sirupsen/logrus"
+	"github.com/spf13/cobra"
+
+	"k8s.io/release/pkg/command"
+	"k8s.io/release/pkg/gcp/gcs"
+	"k8s.io/release/pkg/github"
+	"k8s.io/release/pkg/release"
+	"k8s.io/release/pkg/util"
+)
+
+// imageCmd represents the subcommand for `krel image`
+var imageCmd = &cobra.Command{
+	Use:   "image",
+	Short: "Manage the published release images",
+}
+
+var imageRetagCmd = &cobra.Command{
+	Use:   "retag --from <tag> --to <tag>",
+	Short: "Tag the published images of a version with another version",
+	Long: `krel image retag --from <tag> --to <tag>
+
+Add the tag --to to all release images tagged --from in every --registry,
+without rebuilding or pushing them again. This is used when a release
+candidate gets promoted to the final release without any code change.
+
+Both the manifest lists and the architecture specific images are tagged.
+The new tags reference the same digests as the existing ones.
+
+Before anything gets tagged, every image has to exist with the tag --from
+and none with the tag --to in any --registry. This prevents moving an
+existing tag and leaving the registries partially tagged.
+
+With --nomock, every --registry has to pass its published approval gate
+before it is tagged.`,
+	Example:       "krel image retag --from v1.18.0-rc.1 --to v1.18.0",
+	SilenceUsage:  true,
+	SilenceErrors: true,
+	RunE: func(cmd *cobra.Command, args []string) error {
+		return runImageRetag(imageRetagOpts)
+	},
+}
+
+var imageOrphansCmd = &cobra.Command{
+	Use:   "orphans",
+	Short: "List and delete published images not referenced by any release",
+	Long: `krel image orphans
+
+List all digests of the release images in every --registry which are only
+tagged with versions that no release published to
+gs://<bucket>/<release-type> has a release manifest for. These are tags of
+release candidates which never got released, like leftovers of an aborted
+release, and other version tags without a release. Release directories
+without a manifest, which predate release manifests, count as released.
+
+Digests with at least one tag which is no version, like latest, are always
+kept. So are untagged digests and all images referenced by a manifest list,
+which are the architecture specific images of the multi-arch images. The
+manifest lists are inspected with 'docker manifest inspect'.
+
+The orphaned digests are only listed, unless both --nomock and --delete are
+set. Then they get deleted from the registry together with all of their
+tags.`,
+	SilenceUsage:  true,
+	SilenceErrors: true,
+	RunE: func(cmd *cobra.Command, args []string) error {
+		return runImageOrphans(imageOrphansOpts)
+	},
+}
+
+var imagePinCmd = &cobra.Command{
+	Use:   "pin --tag <version>",
+	Short: "Write snippets referencing the published images by digest",
+	Long: `krel image pin --tag <version>
+
+Resolve the manifest lists of the release images tagged --tag in the
+--registry to their digests and write snippets referencing them into
+--output-dir:
+
+- ` + release.KustomizeImagesFile + `: a kustomization replacing the image tags by digests
+- ` + release.HelmValuesFile + `: Helm values with repository, tag and digest per image
+
+If --bucket is specified, the snippets are published together with the
+release artifacts to gs://<bucket>/<release-type>/<version>/` + release.PinnedImagesPath + `.
+In mock mode the snippets are only written locally.`,
+	Example:       "krel image pin --tag v1.18.0 --bucket kubernetes-release",
+	SilenceUsage:  true,
+	SilenceErrors: true,
+	RunE: func(cmd *cobra.Command, args []string) error {
+		return runImagePin(imagePinOpts)
+	},
+}
+
+type imageRetagOptions struct {
+	from       string
+	to         string
+	registries []string
+	images     []string
+	arches     []string
+	approval   approvalOptions
+}
+
+var imageRetagOpts = &imageRetagOptions{}
+
+type imageOrphansOptions struct {
+	bucket      string
+	releaseType string
+	registries  []string
+	images      []string
+	arches      []string
+	delete      bool
+}
+
+var imageOrphansOpts = &imageOrphansOptions{}
+
+type imagePinOptions struct {
+	tag         string
+	registry    string
+	outputDir   string
+	bucket      string
+	releaseType string
+	images      []string
+}
+
+var imagePinOpts = &imagePinOptions{}
+
+func init() {
+	imageRetagCmd.PersistentFlags().StringVar(
+		&imageRetagOpts.from,
+		"from",
+		"",
+		"existing tag of the images",
+	)
+	imageRetagCmd.PersistentFlags().StringVar(
+		&imageRetagOpts.to,
+		"to",
+		"",
+		"new tag of the images",
+	)
+	imageRetagCmd.PersistentFlags().StringSliceVar(
+		&imageRetagOpts.registries,
+		"registry",
+		[]string{release.DefaultImageRegistry},
+		"registry containing the images, can be specified multiple times",
+	)
+	imageRetagCmd.PersistentFlags().StringSliceVar(
+		&imageRetagOpts.images,
+		"images",
+		release.DefaultImages,
+		"images to tag",
+	)
+	imageRetagCmd.PersistentFlags().StringSliceVar(
+		&imageRetagOpts.arches,
+		"arches",
+		release.DefaultImageArchitectures,
+		"architectures the images are published for",
+	)
+
+	addApprovalFlags(imageRetagCmd, &imageRetagOpts.approval)
+
+	for _, flag := range []string{"from", "to"} {
+		if err := imageRetagCmd.MarkPersistentFlagRequired(flag); err != nil {
+			logrus.Fatal(err)
+		}
+	}
+
+	imageOrphansCmd.PersistentFlags().StringVar(
+		&imageOrphansOpts.bucket,
+		"bucket",
+		"kubernetes-release",
+		"GCS bucket containing the published releases",
+	)
+	imageOrphansCmd.PersistentFlags().StringVar(
+		&imageOrphansOpts.releaseType,
+		"release-type",
+		"release",
+		"directory of the published releases in the bucket",
+	)
+	imageOrphansCmd.PersistentFlags().StringSliceVar(
+		&imageOrphansOpts.registries,
+		"registry",
+		[]string{release.DefaultImageRegistry},
+		"registry containing the images, can be specified multiple times",
+	)
+	imageOrphansCmd.PersistentFlags().StringSliceVar(
+		&imageOrphansOpts.images,
+		"images",
+		release.DefaultImages,
+		"images to check",
+	)
+	imageOrphansCmd.PersistentFlags().StringSliceVar(
+		&imageOrphansOpts.arches,
+		"arches",
+		release.DefaultImageArchitectures,
+		"architectures the images are published for",
+	)
+	imageOrphansCmd.PersistentFlags().BoolVar(
+		&imageOrphansOpts.delete,
+		"delete",
+		false,
+		"delete the orphaned digests together with their tags, requires --nomock",
+	)
+
+	imagePinCmd.PersistentFlags().StringVar(
+		&imagePinOpts.tag,
+		"tag",
+		"",
+		"version tag of the published images",
+	)
+	imagePinCmd.PersistentFlags().StringVar(
+		&imagePinOpts.registry,
+		"registry",
+		release.DefaultImageRegistry,
+		"registry containing the images",
+	)
+	imagePinCmd.PersistentFlags().StringSliceVar(
+		&imagePinOpts.images,
+		"images",
+		release.DefaultImages,
+		"images to pin",
+	)
+	imagePinCmd.PersistentFlags().StringVar(
+		&imagePinOpts.outputDir,
+		"output-dir",
+		filepath.Join("_output", release.PinnedImagesPath),
+		"local directory to write the snippets to",
+	)
+	imagePinCmd.PersistentFlags().StringVar(
+		&imagePinOpts.bucket,
+		"bucket",
+		"",
+		"GCS bucket to publish the snippets to, if specified",
+	)
+	imagePinCmd.PersistentFlags().StringVar(
+		&imagePinOpts.releaseType,
+		"release-type",
+		"release",
+		"directory of the published releases in the bucket",
+	)
+
+	if err := imagePinCmd.MarkPersistentFlagRequired("tag"); err != nil {
+		logrus.Fatal(err)
+	}
+
+	imageCmd.AddCommand(imageRetagCmd, imageOrphansCmd, imagePinCmd)
+	rootCmd.AddCommand(imageCmd)
+}
+
+func runImageRetag(opts *imageRetagOptions) error {
+	for _, tag := range []string{opts.from, opts.to} {
+		if _, err := util.TagStringToSemver(tag); err != nil {
+			return errors.Wrapf(err, "invalid version tag %q", tag)
+		}
+	}
+	if opts.from == opts.to {
+		return errors.New("--from and --to must be different tags")
+	}
+	if !command.Available(release.GCloudExecutable) {
+		return errors.Errorf("%s is required for tagging images", release.GCloudExecutable)
+	}
+
+	retags := release.ImageRetags(
+		opts.registries, opts.images, opts.arches, opts.from, opts.to,
+	)
+	if err := release.CheckImageRetags(retags, release.ListRegistryImages); err != nil {
+		return err
+	}
+	if !rootOpts.nomock {
+		for _, retag := range retags {
+			logrus.Infof("Would tag %s as %s", retag.From, retag.To)
+		}
+		logrus.Infof(
+			"Mock run - skipping. Use --nomock to tag %d images", len(retags),
+		)
+		return nil
+	}
+	if err := requireRole(github.RoleReleaseManager, ""); err != nil {
+		return err
+	}
+	for _, registry := range opts.registries {
+		if err := requireApprovals(&opts.approval, "", opts.to, "tag", registry); err != nil {
+			return err
+		}
+	}
+
+	for _, retag := range retags {
+		if err := retag.Run(); err != nil {
+			return err
+		}
+		if err := recordAudit(opts.to, "tag", retag.To); err != nil {
+			return err
+		}
+	}
+	logrus.Infof("Tagged %d images as %s", len(retags), opts.to)
+	return nil
+}
+
+func runImageOrphans(opts *imageOrphansOptions) error {
+	for _, executable := range []string{
+		release.GCloudExecutable, release.DockerExecutable,
+	} {
+		if !command.Available(executable) {
+			return errors.Errorf("%s is required for listing images", executable)
+		}
+	}
+
+	releases, err := releasedVersions(opts.bucket, opts.releaseType)
+	if err != nil {
+		return err
+	}
+	logrus.Infof(
+		"Found %d releases in gs://%s/%s", len(releases), opts.bucket, opts.releaseType,
+	)
+
+	orphans := []release.OrphanedImage{}
+	w := tabwriter.NewWriter(os.Stdout, 0, 0, 2, ' ', 0)
+	fmt.Fprintln(w, "IMAGE\tDIGEST\tTAGS\tREASON")
+	for _, registry := range opts.registries {
+		registry = strings.TrimSuffix(registry, "/")
+		for _, name := range release.ImageNames(opts.images, opts.arches) {
+			ref := registry + "/" + name
+			listed, err := release.ListRegistryImages(ref)
+			if err != nil {
+				return err
+			}
+			found := release.FindOrphanedImages(ref, listed, releases, nil)
+			if len(found) > 0 {
+				// Inspecting the manifest lists is only worth it with candidates
+				children, err := release.ManifestListChildren(ref, listed)
+				if err != nil {
+					return err
+				}
+				found = release.FindOrphanedImages(ref, listed, releases, children)
+			}
+			for _, orphan := range found {
+				fmt.Fprintf(w, "%s\t%s\t%s\t%s\n",
+					orphan.Image, orphan.Digest,
+					strings.Join(orphan.Tags, ","), orphan.Reason,
+				)
+				orphans = append(orphans, orphan)
+			}
+		}
+	}
+	if err := w.Flush(); err != nil {
+		return errors.Wrap(err, "writing orphaned images")
+	}
+
+	if len(orphans) == 0 {
+		logrus.Info("No orphaned images found")
+		return nil
+	}
+
+	if !rootOpts.nomock || !opts.delete {
+		logrus.Infof(
+			"Skipping the deletion of %d images. Use --nomock --delete to delete them.",
+			len(orphans),
+		)
+		return nil
+	}
+	if err := requireRole(github.RoleReleaseManager, ""); err != nil {
+		return err
+	}
+
+	for i := range orphans {
+		if err := orphans[i].Delete(); err != nil {
+			return err
+		}
+		if err := recordAudit(
+			orphans[i].Tags[0], "delete-image", orphans[i].Image+"@"+orphans[i].Digest,
+		); err != nil {
+			return err
+		}
+	}
+	logrus.Infof("Deleted %d orphaned images", len(orphans))
+	return nil
+}
+
+// releasedVersions returns the versions of all releases in the `releaseType`
+// directory of `bucket`, according to their release manifests
+func releasedVersions(bucketName, releaseType string) (map[string]bool, error) {
+	ctx := context.Background()
+	bucket, err := gcsBucket(bucketName)
+	if err != nil {
+		return nil, err
+	}
+	dirs, err := gcs.ListDirs(ctx, bucket, strings.TrimSuffix(releaseType, "/")+"/")
+	if err != nil {
+		return nil, errors.Wrap(err, "listing published releases")
+	}
+
+	res := map[string]bool{}
+	for _, dir := range dirs {
+		manifest, err := release.ReadManifest(func(name string) (io.ReadCloser, error) {
+			return gcs.NewReader(ctx, bucket, path.Join(dir, name))
+		})
+		if errors.Cause(err) == storage.ErrObjectNotExist {
+			version := path.Base(strings.TrimSuffix(dir, "/"))
+			logrus.Debugf("Release %s has no release manifest, keeping its images", version)
+			res[version] = true
+			continue
+		}
+		if err != nil {
+			return nil, errors.Wrapf(err, "reading release manifest of %s", dir)
+		}
+		res[manifest.Version] = true
+	}
+	return res, nil
+}
+
+func runImagePin(opts *imagePinOptions) error {
+	if _, err := util.TagStringToSemver(opts.tag); err != nil {
+		return errors.Wrapf(err, "invalid version tag %q", opts.tag)
+	}
+	if !command.Available(release.GCloudExecutable) {
+		return errors.Errorf("%s is required for resolving image digests", release.GCloudExecutable)
+	}
+
+	pinned, err := release.PinImages(opts.registry, opts.images, opts.tag)
+	if err != nil {
+		return err
+	}
+	if err := release.WritePinnedImages(opts.outputDir, pinned); err != nil {
+		return err
+	}
+	logrus.Infof("Wrote digest pinned image snippets to %s", opts.outputDir)
+
+	if opts.bucket == "" {
+		return nil
+	}
+
+	dst := path.Join(opts.releaseType, opts.tag, release.PinnedImagesPath)
+	if !rootOpts.nomock {
+		logrus.Infof(
+			"Mock run - skipping. Use --nomock to publish the snippets to gs://%s/%s",
+			opts.bucket, dst,
+		)
+		return nil
+	}
+	if err := requireRole(github.RoleReleaseManager, ""); err != nil {
+		return err
+	}
+
+	bucket, err := gcsBucket(opts.bucket)
+	if err != nil {
+		return err
+	}
+	uploadOpts := transferOptions(opts.bucket, gcs.DefaultConcurrency)
+	if err := gcs.CopyDirToGCS(
+		context.Background(), bucket, opts.outputDir, dst, uploadOpts,
+	); err != nil {
+		return errors.Wrap(err, "publishing the pinned image snippets")
+	}
+	return recordAudit(opts.tag, "upload", "gs://"+path.Join(opts.bucket, dst))
+}
@@ -17,8 +17,11 @@ limitations under the License.
 package cmd
 
 import (
+	"strings"
+
 	"github.com/sirupsen/logrus"
 	"github.com/spf13/cobra"
+	"k8s.io/release/pkg/github"
 	"k8s.io/release/pkg/log"
 	"k8s.io/release/pkg/patch"
 	"k8s.io/release/pkg/util"
@@ -34,8 +37,21 @@ func patchAnnounceCommand() *cobra.Command {
 	opts := patch.AnnounceOptions{}
 
 	cmd := &cobra.Command{
-		Use:           "patch-announce",
-		Short:         "Send out patch release announcement mails",
+		Use:   "patch-announce",
+		Short: "Send out patch release announcement mails",
+		Long: `krel patch-announce
+
+Send out the announcement mail of the upcoming patch release.
+
+The subject and the head of the mail are Go templates, which can be
+overridden by the files ` + patch.MailSubjectTemplate + ` and ` + patch.MailHeadTemplate + ` in --template-dir.
+Localized variants like mail-head.de.md or mail-head.pt_BR.md are
+preferred if --locale is set. The templates get the fields of MailData
+in k8s.io/release/pkg/patch, like .Version, .DateFreeze and .DateCut,
+and the functions dateFormatHuman, code, codeBlock and link.
+
+The CVEs of --cve-file use the format of the release-notes --cve-file
+and are announced in a section between the head and the release notes.`,
 		SilenceUsage:  true,
 		SilenceErrors: true,
 		Args:          cobra.MaximumNArgs(0), // no additional/positional args allowed
@@ -47,6 +63,9 @@ func patchAnnounceCommand() *cobra.Command {
 	cmd.PersistentFlags().StringVarP(&opts.FreezeDate, "freeze-date", "f", "", "date when no CPs are allowed anymore")
 	cmd.PersistentFlags().StringVarP(&opts.CutDate, "cut-date", "c", "", "date when the patch release is planned to be cut")
 	cmd.PersistentFlags().StringVarP(&opts.ReleaseRepoPath, "release-repo", "r", "./release", "local path of the k/release checkout")
+	cmd.PersistentFlags().StringVar(&opts.TemplateDir, "template-dir", "", "directory with the templates "+patch.MailSubjectTemplate+" and "+patch.MailHeadTemplate+" overriding the built-in ones")
+	cmd.PersistentFlags().StringVar(&opts.Locale, "locale", "", "locale of the templates in --template-dir to use, like de or pt_BR")
+	cmd.PersistentFlags().StringVar(&opts.CVEFile, "cve-file", "", "YAML file of the CVEs fixed in the release, announced in a separate section")
 
 	// TODO: figure out, how we can read env vars and also be able to set the flags to required in a cobra-native way
 	cmd.PersistentFlags().StringVarP(&opts.SendgridAPIKey, "sendgrid-api-key", "s", util.EnvDefault("SENDGRID_API_KEY", ""), "API key for sendgrid")
@@ -74,8 +93,15 @@ func patchAnnounceCommand() *cobra.Command {
 		localLogger := logrus.NewEntry(logrus.StandardLogger())
 		logger := log.AddTracePath(localLogger, cmd.Name()).WithField("mock", !opts.Nomock)
 
+		if err := requireRole(github.RoleComms, opts.GithubToken); err != nil {
+			return err
+		}
+
 		announcer := &patch.Announcer{
 			Opts: opts,
+			Sent: func(version string, recipients []string) error {
+				return recordAudit(version, "announce", strings.Join(recipients, ","))
+			},
 		}
 		announcer.SetLogger(logger, "announcer")
 
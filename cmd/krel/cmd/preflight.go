@@ -0,0 +1,350 @@
+/*
+Copyright 2020 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package cmd
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"os/user"
+	"text/tabwriter"
+
+	"github.com/pkg/errors"
+	"github.com/sirupsen/logrus"
+	"github.com/spf13/cobra"
+
+	"k8s.io/release/pkg/git"
+	"k8s.io/release/pkg/github"
+	"k8s.io/release/pkg/preflight"
+	"k8s.io/release/pkg/testgrid"
+	"k8s.io/release/pkg/util"
+)
+
+// preflightCmd represents the subcommand for `krel preflight`
+var preflightCmd = &cobra.Command{
+	Use:   "preflight",
+	Short: "Check the prerequisites of a release before starting it",
+	Long: `krel preflight
+
+Run all checks which would otherwise fail late in a release process and
+print a go/no-go report:
+
+- write access to the GCS buckets
+- the remaining GitHub API rate limit
+- the required tools in $PATH
+- the available disk space
+- the host Go toolchain, against --go-version or the version pinned by
+  the repository, if specified
+- a clean git state of the repository (--repo), if it already exists
+- no open release blockers in the --milestone, if specified
+- no failing jobs on the release blocking Testgrid dashboard of the
+  --ci-branch, if specified. With --ci-commit, the most recent run of
+  that commit has to be passed in every tab instead of the latest run.
+
+Release blockers which must not hold the release back can be waived with
+--waive-blocker <number>=<justification>. The justification is logged
+together with the blocker. A red CI signal can be overridden with
+--ci-override <reason>, which logs the current user and the reason.
+
+'krel push --nomock' runs the release blocker and CI signal checks as
+well, with the pushed commit as --ci-commit if not specified. It records
+every waiver and override in the audit log, an override together with
+the current user.
+
+The command fails if at least one check did not pass. Branch protections
+are not checked, because reading them requires admin access to the
+repository.`,
+	SilenceUsage:  true,
+	SilenceErrors: true,
+	RunE: func(cmd *cobra.Command, args []string) error {
+		return runPreflight(preflightOpts)
+	},
+}
+
+type preflightOptions struct {
+	buckets           []string
+	tools             []string
+	diskPath          string
+	minGithubRequests int
+	minDiskSpace      uint64
+	gates             releaseGateOptions
+	toolchain         toolchainCheckOptions
+}
+
+var preflightOpts = &preflightOptions{}
+
+// releaseGateOptions are the options of the release blocker and CI signal
+// checks, which are shared between preflight and the commands cutting
+// releases
+type releaseGateOptions struct {
+	waivers      []string
+	githubOrg    string
+	githubRepo   string
+	githubToken  string
+	milestone    string
+	blockerLabel string
+	ciBranch     string
+	ciCommit     string
+	ciOverride   string
+	testgridURL  string
+}
+
+func init() {
+	preflightCmd.PersistentFlags().StringSliceVar(
+		&preflightOpts.buckets,
+		"bucket",
+		[]string{"kubernetes-release-gcb", "kubernetes-release"},
+		"GCS bucket which has to be writable, can be specified multiple times",
+	)
+	preflightCmd.PersistentFlags().StringSliceVar(
+		&preflightOpts.tools,
+		"tools",
+		[]string{"git", "gcloud", "gsutil", "docker", "gpg"},
+		"tools which have to be available in $PATH",
+	)
+	preflightCmd.PersistentFlags().IntVar(
+		&preflightOpts.minGithubRequests,
+		"min-github-requests",
+		1000,
+		"minimum number of remaining GitHub API requests",
+	)
+	preflightCmd.PersistentFlags().StringVar(
+		&preflightOpts.diskPath,
+		"disk-path",
+		os.TempDir(),
+		"path to check the available disk space for",
+	)
+	preflightCmd.PersistentFlags().Uint64Var(
+		&preflightOpts.minDiskSpace,
+		"min-disk-space",
+		100,
+		"minimum available disk space in GB",
+	)
+	addReleaseGateFlags(preflightCmd, &preflightOpts.gates)
+	addToolchainCheckFlags(preflightCmd, &preflightOpts.toolchain)
+
+	rootCmd.AddCommand(preflightCmd)
+}
+
+// addReleaseGateFlags adds the flags of the release blocker and CI signal
+// checks to `cmd`
+func addReleaseGateFlags(cmd *cobra.Command, opts *releaseGateOptions) {
+	cmd.PersistentFlags().StringVarP(
+		&opts.githubToken,
+		"github-token",
+		"g",
+		util.EnvDefault("GITHUB_TOKEN", ""),
+		"a GitHub token to check the rate limit and the release blockers with",
+	)
+	cmd.PersistentFlags().StringVar(
+		&opts.githubOrg,
+		"org",
+		git.DefaultGithubOrg,
+		"GitHub organization of the repository",
+	)
+	cmd.PersistentFlags().StringVar(
+		&opts.githubRepo,
+		"github-repo",
+		git.DefaultGithubRepo,
+		"GitHub repository to check for release blockers",
+	)
+	cmd.PersistentFlags().StringVar(
+		&opts.milestone,
+		"milestone",
+		"",
+		"GitHub milestone to check for open release blockers, like v1.19",
+	)
+	cmd.PersistentFlags().StringVar(
+		&opts.blockerLabel,
+		"blocker-label",
+		github.DefaultReleaseBlockerLabel,
+		"label of the release blocking issues and PRs",
+	)
+	cmd.PersistentFlags().StringSliceVar(
+		&opts.waivers,
+		"waive-blocker",
+		[]string{},
+		"release blocker to ignore as <number>=<justification>, can be specified multiple times",
+	)
+	cmd.PersistentFlags().StringVar(
+		&opts.ciBranch,
+		"ci-branch",
+		"",
+		"branch to check the release blocking Testgrid dashboard for, like release-1.19",
+	)
+	cmd.PersistentFlags().StringVar(
+		&opts.ciCommit,
+		"ci-commit",
+		"",
+		"commit which has to be passed on the release blocking Testgrid dashboard, the latest results are checked if not set",
+	)
+	cmd.PersistentFlags().StringVar(
+		&opts.ciOverride,
+		"ci-override",
+		"",
+		"reason to release despite a red CI signal",
+	)
+	cmd.PersistentFlags().StringVar(
+		&opts.testgridURL,
+		"testgrid-url",
+		testgrid.DefaultURL,
+		"URL of the Testgrid instance",
+	)
+}
+
+// releaseGateChecks returns the release blocker check of the --milestone
+// and the CI signal check of the --ci-branch, if specified. Waived blockers
+// and an overridden CI signal are recorded in the audit log of `version`,
+// unless it is empty.
+func releaseGateChecks(
+	ctx context.Context, client github.Client, opts *releaseGateOptions, version string,
+) ([]preflight.Check, error) {
+	waivers, err := preflight.ParseWaivers(opts.waivers)
+	if err != nil {
+		return nil, err
+	}
+	if len(waivers) > 0 && opts.milestone == "" {
+		return nil, errors.New("release blockers can only be waived together with --milestone")
+	}
+
+	checks := []preflight.Check{}
+	if opts.milestone != "" {
+		var waived func(int, string) error
+		if version != "" {
+			waived = func(number int, justification string) error {
+				return recordAudit(version, "waive-blocker", fmt.Sprintf(
+					"%s/%s#%d: %s", opts.githubOrg, opts.githubRepo, number, justification,
+				))
+			}
+		}
+		checks = append(checks, preflight.ReleaseBlockers(
+			ctx, client, opts.githubOrg, opts.githubRepo,
+			opts.milestone, opts.blockerLabel, waivers, waived,
+		))
+	}
+
+	if opts.ciBranch != "" {
+		dashboard := testgrid.BlockingDashboard(opts.ciBranch)
+		if opts.ciOverride == "" {
+			return append(checks, preflight.CISignal(
+				opts.testgridURL, dashboard, opts.ciCommit,
+			)), nil
+		}
+
+		u, err := user.Current()
+		if err != nil {
+			return nil, errors.Wrap(err, "Unable to identify current user")
+		}
+		logrus.Warnf(
+			"CI signal check of %s overridden by %s: %s",
+			dashboard, u.Username, opts.ciOverride,
+		)
+		if version != "" {
+			if err := recordAudit(
+				version, "override-ci", fmt.Sprintf("%s: %s", dashboard, opts.ciOverride),
+			); err != nil {
+				return nil, err
+			}
+		}
+	}
+	return checks, nil
+}
+
+// checkReleaseGates fails if `version` must not be released because one of
+// the release gate checks did not pass
+func checkReleaseGates(opts *releaseGateOptions, version string) error {
+	if opts.milestone == "" && opts.ciBranch == "" {
+		return nil
+	}
+	if opts.milestone != "" && opts.githubToken == "" {
+		return errors.New("checking the release blockers requires --github-token or $GITHUB_TOKEN")
+	}
+
+	ctx := context.Background()
+	checks, err := releaseGateChecks(ctx, github.New(ctx, opts.githubToken), opts, version)
+	if err != nil {
+		return err
+	}
+	for _, result := range preflight.Run(checks) {
+		if result.Err != nil {
+			return errors.Wrapf(result.Err, "release gate %s not passed", result.Name)
+		}
+	}
+	return nil
+}
+
+func runPreflight(opts *preflightOptions) error {
+	if opts.gates.githubToken == "" {
+		return errors.New("a GitHub token is required, use --github-token or $GITHUB_TOKEN")
+	}
+
+	ctx := context.Background()
+	client := github.New(ctx, opts.gates.githubToken)
+	gateChecks, err := releaseGateChecks(ctx, client, &opts.gates, "")
+	if err != nil {
+		return err
+	}
+	checks := []preflight.Check{}
+	for _, name := range opts.buckets {
+		bucket, err := gcsBucket(name)
+		if err != nil {
+			return err
+		}
+		checks = append(checks, preflight.GCSWritable(ctx, bucket, name))
+	}
+	checks = append(checks,
+		preflight.GitHubRateLimit(ctx, client, opts.minGithubRequests),
+		preflight.Tools(opts.tools...),
+		preflight.DiskSpace(opts.diskPath, opts.minDiskSpace),
+	)
+	if goVersion, err := pinnedGoVersion(&opts.toolchain, rootOpts.repoPath); err == nil {
+		checks = append(checks, preflight.GoVersion(goVersion))
+	} else {
+		logrus.Infof("Skipping Go toolchain check: %v", err)
+	}
+	if util.Exists(rootOpts.repoPath) {
+		checks = append(checks, preflight.GitClean(rootOpts.repoPath))
+	} else {
+		logrus.Infof(
+			"Repository %s does not exist yet, skipping git state check",
+			rootOpts.repoPath,
+		)
+	}
+
+	checks = append(checks, gateChecks...)
+
+	results := preflight.Run(checks)
+
+	w := tabwriter.NewWriter(os.Stdout, 0, 0, 2, ' ', 0)
+	fmt.Fprintln(w, "CHECK\tRESULT")
+	for _, result := range results {
+		status := "GO"
+		if result.Err != nil {
+			status = fmt.Sprintf("NO-GO: %v", result.Err)
+		}
+		fmt.Fprintf(w, "%s\t%s\n", result.Name, status)
+	}
+	if err := w.Flush(); err != nil {
+		return errors.Wrap(err, "writing preflight report")
+	}
+
+	if !preflight.Passed(results) {
+		return errors.New("preflight checks failed, the release must not be started")
+	}
+	logrus.Info("All preflight checks passed")
+	return nil
+}
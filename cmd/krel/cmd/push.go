@@ -20,6 +20,7 @@ import (
 	"context"
 	"os"
 	"os/user"
+	"path"
 	"path/filepath"
 
 	"cloud.google.com/go/storage"
@@ -27,7 +28,13 @@ import (
 	"github.com/sirupsen/logrus"
 	"github.com/spf13/cobra"
 
+	"k8s.io/release/pkg/approval"
+	"k8s.io/release/pkg/command"
+	"k8s.io/release/pkg/gcp/gcs"
+	"k8s.io/release/pkg/git"
+	"k8s.io/release/pkg/github"
 	"k8s.io/release/pkg/release"
+	"k8s.io/release/pkg/slack"
 	"k8s.io/release/pkg/util"
 )
 
@@ -38,6 +45,32 @@ Developer pushes simply run as they do pushing to devel/ on GCS.
 In --ci mode, 'push' runs in mock mode by default. Use --nomock to do
 a real push.
 
+Non-CI pushes with --nomock require the user of --github-token to be a
+member of the GitHub teams holding the release-manager role.
+
+Pushes with --nomock fail if the go binary in $PATH differs from the
+--go-version or, if not specified, the Go version pinned by the kube-cross
+image of the pushed Kubernetes tree, see 'krel toolchain'.
+
+Non-CI pushes with --nomock fail on open release blockers in the
+--milestone and on a red CI signal of the --ci-branch for the pushed
+commit, if specified. Blockers waived with --waive-blocker and a CI
+signal overridden with --ci-override are recorded in the audit log.
+
+Non-CI pushes with --nomock have to pass the approval gate of their
+destination, which is published together with the public keys of the
+approvers at ` + approval.DefaultPublishedLocation + `.
+Approvals are GPG signatures of the approvers created with 'krel approve',
+approving reviews of an --approval-pr mentioning the pushed version and
+destination, and, if still not enough, approvals of the gate's Slack
+approvers in --slack-approval-channel of the Slack workspace
+--slack-team-id. The user of --github-token and the local GPG keys never
+count as approval. The approval buttons are handled on
+--slack-listen-address, which is only reachable locally by default. Slack sends the clicks to HTTPS URLs only, so expose
+the address through a TLS terminating tunnel or reverse proxy, for example
+'ngrok http 8080', and set its HTTPS URL with the path
+` + slack.InteractionPath + ` as the interactivity request URL of the app.
+
 Federation values are just passed through as exported global vars still
 due to the fact that we're still leveraging the existing federation
 interface in kubernetes proper.
@@ -49,18 +82,29 @@ push --bucket=kubernetes-release-$USER
                            - Do a developer push to kubernetes-release-$USER`
 
 type pushBuildOptions struct {
-	bucket           string
-	buildDir         string
-	dockerRegistry   string
-	extraPublishFile string
-	gcsSuffix        string
-	releaseKind      string
-	releaseType      string
-	versionSuffix    string
-	allowDup         bool
-	ci               bool
-	noUpdateLatest   bool
-	privateBucket    bool
+	bucket            string
+	buildDir          string
+	deltaFrom         string
+	deltaFromVersion  string
+	dockerRegistry    string
+	extraPublishFile  string
+	gcsSuffix         string
+	gpgKey            string
+	releaseKind       string
+	releaseType       string
+	versionSuffix     string
+	deltaMinSize      int64
+	uploadConcurrency int
+	allowDup          bool
+	ci                bool
+	deduplicate       bool
+	gpgSign           bool
+	noUpdateLatest    bool
+	privateBucket     bool
+	approval          approvalOptions
+	schedule          scheduleCheckOptions
+	gates             releaseGateOptions
+	toolchain         toolchainCheckOptions
 }
 
 var pushBuildOpts = &pushBuildOptions{}
@@ -155,6 +199,18 @@ func init() {
 		false,
 		"Used when called from Jenkins (for ci runs)",
 	)
+	pushBuildCmd.PersistentFlags().BoolVar(
+		&pushBuildOpts.deduplicate,
+		"deduplicate",
+		true,
+		"Upload artifacts with identical content only once and copy them within the bucket",
+	)
+	pushBuildCmd.PersistentFlags().BoolVar(
+		&pushBuildOpts.gpgSign,
+		"gpg-sign",
+		false,
+		"Create detached GPG signatures (.asc) for all staged release tarballs, checksum files and the release manifest",
+	)
 	pushBuildCmd.PersistentFlags().BoolVar(
 		&pushBuildOpts.noUpdateLatest,
 		"noupdatelatest",
@@ -197,6 +253,12 @@ func init() {
 		"",
 		"Specify a suffix to append to the upload destination on GCS",
 	)
+	pushBuildCmd.PersistentFlags().StringVar(
+		&pushBuildOpts.gpgKey,
+		"gpg-key",
+		"",
+		"The GPG key ID to be used with --gpg-sign. Uses the default key of gpg(-agent) if not set",
+	)
 	pushBuildCmd.PersistentFlags().StringVar(
 		&pushBuildOpts.releaseKind,
 		"release-kind",
@@ -215,6 +277,34 @@ func init() {
 		"",
 		"Append suffix to version name if set",
 	)
+	pushBuildCmd.PersistentFlags().StringVar(
+		&pushBuildOpts.deltaFrom,
+		"delta-from",
+		"",
+		"Directory containing the release tarballs of --delta-from-version to publish binary deltas of the uncompressed tarballs from, which can be applied with 'krel delta apply'",
+	)
+	pushBuildCmd.PersistentFlags().StringVar(
+		&pushBuildOpts.deltaFromVersion,
+		"delta-from-version",
+		"",
+		"The version of the release tarballs in --delta-from",
+	)
+	pushBuildCmd.PersistentFlags().Int64Var(
+		&pushBuildOpts.deltaMinSize,
+		"delta-min-size",
+		50*1024*1024,
+		"The minimum size in bytes of a release tarball to publish a binary delta for",
+	)
+	pushBuildCmd.PersistentFlags().IntVar(
+		&pushBuildOpts.uploadConcurrency,
+		"upload-concurrency",
+		gcs.DefaultConcurrency,
+		"The maximum amount of files uploaded to GCS in parallel",
+	)
+	addApprovalFlags(pushBuildCmd, &pushBuildOpts.approval)
+	addScheduleCheckFlags(pushBuildCmd, &pushBuildOpts.schedule)
+	addReleaseGateFlags(pushBuildCmd, &pushBuildOpts.gates)
+	addToolchainCheckFlags(pushBuildCmd, &pushBuildOpts.toolchain)
 
 	rootCmd.AddCommand(pushBuildCmd)
 }
@@ -223,6 +313,17 @@ func runPushBuild(opts *pushBuildOptions) error {
 	var latest string
 	releaseKind := opts.releaseKind
 
+	if opts.gpgSign && !command.Available(release.GPGExecutable) {
+		return errors.Errorf("%s is required for signing with --gpg-sign", release.GPGExecutable)
+	}
+
+	if (opts.deltaFrom == "") != (opts.deltaFromVersion == "") {
+		return errors.New("--delta-from and --delta-from-version have to be specified together")
+	}
+	if opts.deltaFrom != "" && !command.Available(release.ZstdExecutable) {
+		return errors.Errorf("%s is required for binary deltas with --delta-from", release.ZstdExecutable)
+	}
+
 	// Check if latest build uses bazel
 	dir, err := os.Getwd()
 	if err != nil {
@@ -271,19 +372,47 @@ func runPushBuild(opts *pushBuildOptions) error {
 
 	logrus.Infof("Latest version is %s", latest)
 
-	gcsDest := opts.releaseType
+	buildType := opts.releaseType
 
 	// TODO: is this how we want to handle gcs dest args?
 	if opts.ci {
-		gcsDest = "ci"
+		buildType = release.BuildTypeCI
 	}
 
-	gcsDest += opts.gcsSuffix
+	gcsDest := buildType + opts.gcsSuffix
 
 	logrus.Infof("GCS destination is %s", gcsDest)
 
 	releaseBucket := opts.bucket
 	if rootOpts.nomock {
+		// CI pushes are done by automation, which holds no release role
+		if !opts.ci {
+			if err := requireRole(github.RoleReleaseManager, opts.gates.githubToken); err != nil {
+				return err
+			}
+		}
+		if err := checkHostToolchain(&opts.toolchain, dir); err != nil {
+			return err
+		}
+		if !opts.ci {
+			if opts.schedule.file != "" {
+				if err := checkSchedule(&opts.schedule, latest); err != nil {
+					return err
+				}
+			}
+			if opts.gates.ciBranch != "" && opts.gates.ciCommit == "" {
+				repo, err := git.OpenRepo(dir)
+				if err != nil {
+					return err
+				}
+				if opts.gates.ciCommit, err = repo.Head(); err != nil {
+					return errors.Wrap(err, "Unable to get the pushed commit")
+				}
+			}
+			if err := checkReleaseGates(&opts.gates, latest); err != nil {
+				return err
+			}
+		}
 		logrus.Infof("Running a *REAL* push with bucket %s", releaseBucket)
 	} else {
 		u, err := user.Current()
@@ -314,6 +443,17 @@ func runPushBuild(opts *pushBuildOptions) error {
 		return errors.Errorf("GCP user must have at least %s permissions on bucket %s", requiredGCSPerms, releaseBucket)
 	}
 
+	gcsPath := path.Join(gcsDest, latest)
+	if !opts.allowDup {
+		exists, err := gcs.PathExists(context.Background(), bucket, gcsPath+"/")
+		if err != nil {
+			return errors.Wrap(err, "Unable to check if build already exists on GCS")
+		}
+		if exists {
+			return errors.Errorf("Build %s already exists on gs://%s/%s, use --allow-dup to overwrite it", latest, releaseBucket, gcsPath)
+		}
+	}
+
 	buildDir := buildOpts.BuildDir
 	if err = util.RemoveAndReplaceDir(filepath.Join(buildDir, release.GCSStagePath)); err != nil {
 		return errors.Wrap(err, "Unable remove and replace GCS staging directory.")
@@ -338,11 +478,76 @@ func runPushBuild(opts *pushBuildOptions) error {
 		}
 	}
 
-	// TODO
-	// Prepare naked binaries
-	// Write checksums
-	// Push Docker images
-	// Push artifacts to release bucket is --ci
+	// TODO: Prepare naked binaries
+
+	// Write the binary deltas, which have to be covered by the checksums
+	if opts.deltaFrom != "" {
+		deltas, err := release.WriteDeltas(filepath.Join(buildDir, release.GCSStagePath), &release.DeltaOptions{
+			PreviousDir:     opts.deltaFrom,
+			PreviousVersion: opts.deltaFromVersion,
+			MinSize:         opts.deltaMinSize,
+		})
+		if err != nil {
+			return errors.Wrap(err, "Unable to write binary deltas")
+		}
+		logrus.Infof("Wrote %d binary deltas from %s", len(deltas), opts.deltaFromVersion)
+	}
+
+	// Write the release manifest which references all staged artifacts. It
+	// is written first to be covered by the checksums and signatures. Every
+	// staged file is hashed only once for the manifest, the checksums and
+	// the deduplicated upload.
+	digests := release.Digests{}
+	if err := release.WriteManifest(filepath.Join(buildDir, release.GCSStagePath), latest, "gs://"+path.Join(releaseBucket, gcsPath), opts.gpgSign, digests); err != nil {
+		return errors.Wrap(err, "Unable to write release manifest")
+	}
+
+	// Write the release checksum files
+	if err := release.WriteChecksums(filepath.Join(buildDir, release.GCSStagePath), digests); err != nil {
+		return errors.Wrap(err, "Unable to write release checksums")
+	}
+
+	if opts.gpgSign {
+		logrus.Info("Signing staged release artifacts")
+		if err := release.SignArtifacts(filepath.Join(buildDir, release.GCSStagePath), opts.gpgKey); err != nil {
+			return errors.Wrap(err, "Unable to sign staged release artifacts")
+		}
+	}
+
+	// Copy the staged artifacts to the release bucket, CI pushes are done by
+	// automation nobody approves
+	if !opts.ci {
+		if err := requireApprovals(
+			&opts.approval, opts.gates.githubToken, latest, "push",
+			"gs://"+path.Join(releaseBucket, gcsPath),
+		); err != nil {
+			return err
+		}
+	}
+	uploadOpts := transferOptions(releaseBucket, opts.uploadConcurrency)
+	uploadOpts.Deduplicate = opts.deduplicate
+	uploadOpts.Digests = digests.SHA256()
+	if err := gcs.CopyDirToGCS(context.Background(), bucket, filepath.Join(buildDir, release.GCSStagePath), gcsPath, uploadOpts); err != nil {
+		return errors.Wrap(err, "Unable to push release artifacts to GCS")
+	}
+	if err := recordAudit(latest, "upload", "gs://"+path.Join(releaseBucket, gcsPath)); err != nil {
+		return err
+	}
+
+	// TODO: Push Docker images
+
+	// The published head has to cover the last record of the push, which
+	// is the publishing of the version markers if they get updated
+	if opts.ci && !opts.noUpdateLatest {
+		if err := release.PublishVersion(
+			context.Background(), bucket, buildType, opts.gcsSuffix, latest, opts.extraPublishFile,
+		); err != nil {
+			return errors.Wrap(err, "Unable to publish version markers")
+		}
+		if err := recordAudit(latest, "publish", "gs://"+path.Join(releaseBucket, gcsDest)); err != nil {
+			return err
+		}
+	}
 
-	return nil
+	return publishAuditHead(bucket, releaseBucket, gcsPath)
 }
@@ -0,0 +1,243 @@
+/*
+Copyright 2020 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package cmd
+
+import (
+	"context"
+	"os/user"
+	"path"
+	"time"
+
+	"github.com/pkg/errors"
+	"github.com/sirupsen/logrus"
+	"github.com/spf13/cobra"
+
+	"k8s.io/release/pkg/gcp/gcs"
+	"k8s.io/release/pkg/git"
+	"k8s.io/release/pkg/github"
+	"k8s.io/release/pkg/release"
+	"k8s.io/release/pkg/util"
+)
+
+// rollbackCmd represents the subcommand for `krel rollback`
+var rollbackCmd = &cobra.Command{
+	Use:   "rollback <version> --to <previous version>",
+	Short: "Point the download channels of a bad release back",
+	Long: `krel rollback <version> --to <previous version>
+
+Repoint all version markers, like release/stable.txt, which currently point
+to the bad version back to the previous version. Markers which do not apply
+to the previous version, for example release/stable-1.19.txt when rolling
+back from v1.19.0 to v1.18.5, are left untouched and have to be fixed
+manually with 'krel channel set'.
+
+The rollback is recorded in a rollback.json next to the artifacts of the
+bad version. With --github-prerelease the GitHub release of the bad
+version is marked as pre-release, too. Published artifacts are never
+deleted.
+
+In mock mode the markers to be repointed are only printed.`,
+	Args:          cobra.ExactArgs(1),
+	SilenceUsage:  true,
+	SilenceErrors: true,
+	RunE: func(cmd *cobra.Command, args []string) error {
+		return runRollback(rollbackOpts, args[0])
+	},
+}
+
+type rollbackOptions struct {
+	bucket           string
+	releaseType      string
+	to               string
+	reason           string
+	githubOrg        string
+	githubRepo       string
+	githubToken      string
+	githubPrerelease bool
+}
+
+var rollbackOpts = &rollbackOptions{}
+
+func init() {
+	rollbackCmd.PersistentFlags().StringVar(
+		&rollbackOpts.bucket,
+		"bucket",
+		"kubernetes-release",
+		"GCS bucket the release was pushed to",
+	)
+	rollbackCmd.PersistentFlags().StringVar(
+		&rollbackOpts.releaseType,
+		"release-type",
+		release.BuildTypeRelease,
+		"release type the version was pushed as (normally 'release' or 'ci')",
+	)
+	rollbackCmd.PersistentFlags().StringVar(
+		&rollbackOpts.to,
+		"to",
+		"",
+		"previous version the markers should point to",
+	)
+	rollbackCmd.PersistentFlags().StringVar(
+		&rollbackOpts.reason,
+		"reason",
+		"",
+		"reason for the rollback, stored in the rollback record",
+	)
+	rollbackCmd.PersistentFlags().BoolVar(
+		&rollbackOpts.githubPrerelease,
+		"github-prerelease",
+		false,
+		"mark the GitHub release of the version as pre-release",
+	)
+	rollbackCmd.PersistentFlags().StringVar(
+		&rollbackOpts.githubOrg,
+		"org",
+		git.DefaultGithubOrg,
+		"GitHub organization of the repository",
+	)
+	rollbackCmd.PersistentFlags().StringVar(
+		&rollbackOpts.githubRepo,
+		"github-repo",
+		git.DefaultGithubRepo,
+		"GitHub repository of the release",
+	)
+	rollbackCmd.PersistentFlags().StringVarP(
+		&rollbackOpts.githubToken,
+		"github-token",
+		"g",
+		util.EnvDefault("GITHUB_TOKEN", ""),
+		"a GitHub token with write access to the repository",
+	)
+
+	if err := rollbackCmd.MarkPersistentFlagRequired("to"); err != nil {
+		logrus.Fatal(err)
+	}
+
+	rootCmd.AddCommand(rollbackCmd)
+}
+
+func runRollback(opts *rollbackOptions, version string) error {
+	bad, err := util.TagStringToSemver(version)
+	if err != nil {
+		return errors.Wrapf(err, "invalid version tag %q", version)
+	}
+	previous, err := util.TagStringToSemver(opts.to)
+	if err != nil {
+		return errors.Wrapf(err, "invalid version tag %q", opts.to)
+	}
+	if !previous.LT(bad) {
+		return errors.Errorf("--to %s has to be older than %s", opts.to, version)
+	}
+	if opts.githubPrerelease && opts.githubToken == "" {
+		return errors.New("a GitHub token is required, use --github-token or $GITHUB_TOKEN")
+	}
+
+	markers, err := release.RollbackMarkers(opts.releaseType, version, opts.to)
+	if err != nil {
+		return err
+	}
+
+	bucket, err := gcsBucket(opts.bucket)
+	if err != nil {
+		return err
+	}
+
+	ctx := context.Background()
+	releasePath := path.Join(opts.releaseType, opts.to)
+	exists, err := gcs.PathExists(ctx, bucket, releasePath+"/")
+	if err != nil {
+		return errors.Wrapf(err, "checking release files of %s", opts.to)
+	}
+	if !exists {
+		return errors.Errorf(
+			"release files don't exist at gs://%s/%s", opts.bucket, releasePath,
+		)
+	}
+
+	repoint := []string{}
+	for _, marker := range markers {
+		current, err := release.ReadMarker(ctx, bucket, marker)
+		if err != nil {
+			return err
+		}
+		if current != version {
+			logrus.Infof("Marker %s points to %q, not rolling it back", marker, current)
+			continue
+		}
+		logrus.Infof("Marker %s will be pointed back to %s", marker, opts.to)
+		repoint = append(repoint, marker)
+	}
+
+	if !rootOpts.nomock {
+		logrus.Info("Mock run - skipping. Use --nomock to roll back the release.")
+		return nil
+	}
+	if err := requireRole(github.RoleReleaseManager, opts.githubToken); err != nil {
+		return err
+	}
+
+	// The markers might have been updated since they were checked, so they
+	// are only rolled back if they still point to the version
+	rolledBack := []string{}
+	for _, marker := range repoint {
+		marker := marker
+		updated, err := release.UpdateMarker(ctx, bucket, marker, opts.to, func(current string) (bool, error) {
+			if current != version {
+				logrus.Warnf("Marker %s changed to %q in the meantime, not rolling it back", marker, current)
+				return false, nil
+			}
+			return true, nil
+		})
+		if err != nil {
+			return err
+		}
+		if !updated {
+			continue
+		}
+		rolledBack = append(rolledBack, marker)
+		if err := recordAudit(version, "rollback", "gs://"+path.Join(opts.bucket, marker)); err != nil {
+			return err
+		}
+	}
+
+	u, err := user.Current()
+	if err != nil {
+		return errors.Wrap(err, "Unable to identify current user")
+	}
+	if err := release.WriteRollbackRecord(ctx, bucket, opts.releaseType, &release.RollbackRecord{
+		Version:    version,
+		RolledBack: opts.to,
+		User:       u.Username,
+		Reason:     opts.reason,
+		Markers:    rolledBack,
+		Time:       time.Now().UTC(),
+	}); err != nil {
+		return err
+	}
+
+	if opts.githubPrerelease {
+		if _, err := github.YankRelease(
+			ctx, github.New(ctx, opts.githubToken),
+			opts.githubOrg, opts.githubRepo, version,
+		); err != nil {
+			return errors.Wrapf(err, "marking GitHub release %s as pre-release", version)
+		}
+	}
+
+	logrus.Infof("Rolled back %s to %s", version, opts.to)
+	return nil
+}
@@ -0,0 +1,61 @@
+/*
+Copyright 2020 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package cmd
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestRunRollbackInvalidVersions(t *testing.T) {
+	for _, tc := range []struct {
+		version string
+		to      string
+		want    string
+	}{
+		{ // same version
+			version: "v1.19.1",
+			to:      "v1.19.1",
+			want:    "has to be older than",
+		},
+		{ // newer version
+			version: "v1.19.1",
+			to:      "v1.19.2",
+			want:    "has to be older than",
+		},
+		{ // newer pre-release
+			version: "v1.19.0-rc.1",
+			to:      "v1.19.0",
+			want:    "has to be older than",
+		},
+		{ // invalid version
+			version: "latest",
+			to:      "v1.19.0",
+			want:    "invalid version tag",
+		},
+		{ // invalid previous version
+			version: "v1.19.1",
+			to:      "1.19",
+			want:    "invalid version tag",
+		},
+	} {
+		err := runRollback(&rollbackOptions{to: tc.to}, tc.version)
+		require.NotNil(t, err)
+		require.Contains(t, err.Error(), tc.want)
+	}
+}
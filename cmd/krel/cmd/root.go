@@ -17,31 +17,54 @@ limitations under the License.
 package cmd
 
 import (
+	"context"
 	"os"
+	"path"
 	"path/filepath"
 
+	"cloud.google.com/go/storage"
+	"github.com/pkg/errors"
 	"github.com/sirupsen/logrus"
 	"github.com/spf13/cobra"
 
+	"k8s.io/release/pkg/audit"
+	"k8s.io/release/pkg/gcp/gcs"
+	"k8s.io/release/pkg/git"
+	"k8s.io/release/pkg/github"
 	"k8s.io/release/pkg/log"
+	"k8s.io/release/pkg/util"
 )
 
 // rootCmd represents the base command when called without any subcommands
 var rootCmd = &cobra.Command{
 	Use:               "krel",
 	Short:             "krel",
-	PersistentPreRunE: initLogging,
+	PersistentPreRunE: initRoot,
 }
 
 type rootOptions struct {
-	nomock   bool
-	cleanup  bool
-	repoPath string
-	logLevel string
+	nomock          bool
+	cleanup         bool
+	repoPath        string
+	auditLog        string
+	logLevel        string
+	logFormat       string
+	bandwidthLimits []string
+	maxBandwidth    int64
+	maxConcurrency  int
 }
 
 var rootOpts = &rootOptions{}
 
+var (
+	// globalLimiter limits the bandwidth of all transfers
+	globalLimiter *gcs.Limiter
+
+	// destinationLimiters limit the bandwidth of the transfers from and to
+	// a single destination, they respect the globalLimiter as well
+	destinationLimiters = map[string]*gcs.Limiter{}
+)
+
 // Execute adds all child commands to the root command and sets flags appropriately.
 // This is called by main.main(). It only needs to happen once to the rootCmd.
 func Execute() {
@@ -55,8 +78,119 @@ func init() {
 	rootCmd.PersistentFlags().BoolVar(&rootOpts.cleanup, "cleanup", false, "cleanup flag")
 	rootCmd.PersistentFlags().StringVar(&rootOpts.repoPath, "repo", filepath.Join(os.TempDir(), "k8s"), "the local path to the repository to be used")
 	rootCmd.PersistentFlags().StringVar(&rootOpts.logLevel, "log-level", "info", "the logging verbosity, either 'panic', 'fatal', 'error', 'warn', 'warning', 'info', 'debug' or 'trace'")
+	rootCmd.PersistentFlags().StringVar(&rootOpts.logFormat, "log-format", log.FormatText, "the logging format, either 'text' or 'json'")
+	rootCmd.PersistentFlags().StringVar(&rootOpts.auditLog, "audit-log", defaultAuditLog(), "the local path of the audit log recording all side effecting actions")
+	rootCmd.PersistentFlags().IntVar(&rootOpts.maxConcurrency, "max-concurrency", 0, "the maximum amount of parallel transfers of every command, unlimited if 0")
+	rootCmd.PersistentFlags().Int64Var(&rootOpts.maxBandwidth, "max-bandwidth", 0, "the maximum combined bandwidth of all transfers in bytes per second, unlimited if 0")
+	rootCmd.PersistentFlags().StringSliceVar(&rootOpts.bandwidthLimits, "bandwidth-limit", []string{}, "the maximum bandwidth of the transfers from and to a GCS bucket as <bucket>=<bytes-per-second>, can be specified multiple times")
+}
+
+func initRoot(cmd *cobra.Command, args []string) error {
+	if err := initLogging(cmd, args); err != nil {
+		return err
+	}
+	return initTransferLimits()
+}
+
+func initTransferLimits() error {
+	limits, err := gcs.ParseLimits(rootOpts.bandwidthLimits)
+	if err != nil {
+		return err
+	}
+	globalLimiter = gcs.NewLimiter(rootOpts.maxBandwidth)
+	for destination, bytesPerSecond := range limits {
+		destinationLimiters[destination] = gcs.NewChildLimiter(bytesPerSecond, globalLimiter)
+	}
+	return nil
+}
+
+// transferOptions returns the GCS options for the transfers from and to the
+// bucket, which respect the global concurrency and bandwidth limits.
+func transferOptions(bucket string, concurrency int) *gcs.Options {
+	opts := gcs.DefaultOptions()
+	opts.Concurrency = concurrency
+	if rootOpts.maxConcurrency > 0 && concurrency > rootOpts.maxConcurrency {
+		opts.Concurrency = rootOpts.maxConcurrency
+	}
+	opts.Limiter = globalLimiter
+	if limiter, ok := destinationLimiters[bucket]; ok {
+		opts.Limiter = limiter
+	}
+	return opts
+}
+
+// recordAudit appends the side effecting `action` on `target` done for the
+// release `version` to the audit log. Only --nomock runs are recorded.
+func recordAudit(version, action, target string) error {
+	if !rootOpts.nomock {
+		return nil
+	}
+	return errors.Wrap(
+		audit.New(rootOpts.auditLog).Append(version, action, target),
+		"recording audit log",
+	)
+}
+
+// publishAuditHead writes the hash of the last audit record to the
+// audit.HeadFile in `gcsPath` of `bucket`. It anchors the local audit log
+// outside of this machine, 'krel audit show --bucket' compares the log
+// with it. Like the records, the head is only published with --nomock.
+func publishAuditHead(bucket *storage.BucketHandle, bucketName, gcsPath string) error {
+	if !rootOpts.nomock {
+		return nil
+	}
+	head, err := audit.New(rootOpts.auditLog).Head()
+	if err != nil {
+		return errors.Wrap(err, "reading audit log head")
+	}
+	dst := path.Join(gcsPath, audit.HeadFile)
+	logrus.Infof("Publishing audit log head %s to gs://%s/%s", head, bucketName, dst)
+	return errors.Wrap(
+		gcs.WriteString(
+			context.Background(), bucket, dst, head+"\n", transferOptions(bucketName, 1),
+		),
+		"publishing audit log head",
+	)
+}
+
+// requireRole fails if the GitHub user of `token` is not a member of the
+// teams holding the `role` in github.DefaultRoles. The token is the
+// --github-token of the command, or $GITHUB_TOKEN if the command has none.
+// Roles are only checked with --nomock.
+func requireRole(role, token string) error {
+	if !rootOpts.nomock {
+		return nil
+	}
+	token = githubToken(token)
+	if token == "" {
+		return errors.Errorf("a GitHub token is required to check the role %s, use --github-token or $GITHUB_TOKEN", role)
+	}
+	ctx := context.Background()
+	return github.RequireRole(
+		ctx, github.New(ctx, token), github.DefaultRoles, git.DefaultGithubOrg, role,
+	)
+}
+
+// githubToken returns the --github-token `token` of a command, or
+// $GITHUB_TOKEN if it is empty
+func githubToken(token string) string {
+	if token == "" {
+		return util.EnvDefault("GITHUB_TOKEN", "")
+	}
+	return token
+}
+
+func defaultAuditLog() string {
+	home, err := os.UserHomeDir()
+	if err != nil {
+		home = os.TempDir()
+	}
+	return filepath.Join(home, ".krel", "audit.log")
 }
 
 func initLogging(*cobra.Command, []string) error {
-	return log.SetupGlobalLogger(rootOpts.logLevel)
+	if err := log.SetupGlobalLogger(rootOpts.logLevel); err != nil {
+		return err
+	}
+	return log.SetFormat(rootOpts.logFormat)
 }
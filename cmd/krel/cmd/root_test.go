@@ -17,6 +17,7 @@ limitations under the License.
 package cmd
 
 import (
+	"os"
 	"testing"
 
 	"github.com/stretchr/testify/require"
@@ -26,3 +27,20 @@ func TestRootCommand(t *testing.T) {
 	err := rootCmd.Execute()
 	require.Nil(t, err)
 }
+
+func TestRequireRole(t *testing.T) {
+	nomock := rootOpts.nomock
+	defer func() { rootOpts.nomock = nomock }()
+
+	// Roles are not checked in mock mode
+	rootOpts.nomock = false
+	require.Nil(t, requireRole("release-manager", ""))
+
+	// A missing token fails with --nomock
+	defer os.Setenv("GITHUB_TOKEN", os.Getenv("GITHUB_TOKEN"))
+	require.Nil(t, os.Unsetenv("GITHUB_TOKEN"))
+	rootOpts.nomock = true
+	err := requireRole("release-manager", "")
+	require.NotNil(t, err)
+	require.Contains(t, err.Error(), "--github-token")
+}
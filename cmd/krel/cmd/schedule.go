@@ -0,0 +1,214 @@
+/*
+Copyright 2020 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package cmd
+
+import (
+	"fmt"
+	"os"
+	"text/tabwriter"
+	"time"
+
+	"github.com/pkg/errors"
+	"github.com/sirupsen/logrus"
+	"github.com/spf13/cobra"
+
+	"k8s.io/release/pkg/release"
+)
+
+// scheduleCmd represents the subcommand for `krel schedule`
+var scheduleCmd = &cobra.Command{
+	Use:   "schedule",
+	Short: "Check releases against the release calendar",
+	Long: `krel schedule
+
+The release calendar is a YAML file maintained by the release team,
+listing the milestones of a release cycle like code freeze and the dates
+of the planned releases:
+
+    - name: Code Freeze
+      date: "2020-07-09"
+    - name: v1.19.0
+      version: v1.19.0
+      date: "2020-08-25"
+
+With --nomock and --schedule-file, 'krel push' checks the pushed release
+version like 'krel schedule check' does. 'krel gcbmgr' does not know the
+version before the job runs, so it only checks that a release is
+scheduled around today.`,
+}
+
+var scheduleCheckCmd = &cobra.Command{
+	Use:   "check <version>",
+	Short: "Verify that a version is released on its scheduled date",
+	Long: `krel schedule check <version>
+
+Fail if the version is not part of the release calendar or today is not
+within --tolerance days of its scheduled date. With --warn-only a release
+outside of its window is only reported.`,
+	Args:          cobra.ExactArgs(1),
+	SilenceUsage:  true,
+	SilenceErrors: true,
+	RunE: func(cmd *cobra.Command, args []string) error {
+		return runScheduleCheck(scheduleOpts, args[0])
+	},
+}
+
+var scheduleNextCmd = &cobra.Command{
+	Use:           "next",
+	Short:         "Print the upcoming milestones of the release calendar",
+	SilenceUsage:  true,
+	SilenceErrors: true,
+	RunE: func(cmd *cobra.Command, args []string) error {
+		return runScheduleNext(scheduleOpts)
+	},
+}
+
+type scheduleOptions struct {
+	scheduleCheckOptions
+	count int
+}
+
+var scheduleOpts = &scheduleOptions{}
+
+// scheduleCheckOptions are the options of checking releases against the
+// release calendar, which are shared with the commands cutting releases
+type scheduleCheckOptions struct {
+	file      string
+	tolerance int
+	warnOnly  bool
+}
+
+func init() {
+	scheduleCmd.PersistentFlags().StringVar(
+		&scheduleOpts.file,
+		"file",
+		"",
+		"YAML file containing the release calendar",
+	)
+	scheduleCheckCmd.PersistentFlags().IntVar(
+		&scheduleOpts.tolerance,
+		"tolerance",
+		1,
+		"number of days a release may happen before or after its scheduled date",
+	)
+	scheduleCheckCmd.PersistentFlags().BoolVar(
+		&scheduleOpts.warnOnly,
+		"warn-only",
+		false,
+		"only warn instead of failing if the release is outside of its window",
+	)
+	scheduleNextCmd.PersistentFlags().IntVar(
+		&scheduleOpts.count,
+		"count",
+		5,
+		"maximum number of milestones to print, 0 for all",
+	)
+
+	if err := scheduleCmd.MarkPersistentFlagRequired("file"); err != nil {
+		logrus.Fatal(err)
+	}
+
+	scheduleCmd.AddCommand(scheduleCheckCmd, scheduleNextCmd)
+	rootCmd.AddCommand(scheduleCmd)
+}
+
+func runScheduleCheck(opts *scheduleOptions, version string) error {
+	return checkSchedule(&opts.scheduleCheckOptions, version)
+}
+
+// addScheduleCheckFlags adds the flags of checking a release against the
+// release calendar to `cmd`
+func addScheduleCheckFlags(cmd *cobra.Command, opts *scheduleCheckOptions) {
+	cmd.PersistentFlags().StringVar(
+		&opts.file,
+		"schedule-file",
+		"",
+		"YAML file containing the release calendar to check the release against with --nomock, see 'krel schedule'",
+	)
+	cmd.PersistentFlags().IntVar(
+		&opts.tolerance,
+		"schedule-tolerance",
+		1,
+		"number of days a release may happen before or after its scheduled date",
+	)
+	cmd.PersistentFlags().BoolVar(
+		&opts.warnOnly,
+		"schedule-warn-only",
+		false,
+		"only warn instead of failing if the release is outside of its window",
+	)
+}
+
+// checkSchedule fails if `version` is not released within --tolerance days
+// of its scheduled date
+func checkSchedule(opts *scheduleCheckOptions, version string) error {
+	schedule, err := release.ParseSchedule(opts.file)
+	if err != nil {
+		return err
+	}
+
+	if err := schedule.Check(version, time.Now(), opts.tolerance); err != nil {
+		return scheduleViolation(opts, err)
+	}
+	logrus.Infof("Release %s is on schedule", version)
+	return nil
+}
+
+// checkScheduleDue fails if no release is scheduled within --tolerance days
+// of today, which is used before the version of a release is known
+func checkScheduleDue(opts *scheduleCheckOptions) error {
+	schedule, err := release.ParseSchedule(opts.file)
+	if err != nil {
+		return err
+	}
+
+	due := schedule.Due(time.Now(), opts.tolerance)
+	if len(due) == 0 {
+		return scheduleViolation(opts, errors.New("no release is scheduled for today"))
+	}
+	for _, milestone := range due {
+		logrus.Infof(
+			"Release %s is scheduled for %s", milestone.Version,
+			milestone.Date.Format(release.ScheduleDateFormat),
+		)
+	}
+	return nil
+}
+
+func scheduleViolation(opts *scheduleCheckOptions, err error) error {
+	if opts.warnOnly {
+		logrus.Warnf("Release outside of its scheduled window: %v", err)
+		return nil
+	}
+	return errors.Wrap(err, "release outside of its scheduled window")
+}
+
+func runScheduleNext(opts *scheduleOptions) error {
+	schedule, err := release.ParseSchedule(opts.file)
+	if err != nil {
+		return err
+	}
+
+	w := tabwriter.NewWriter(os.Stdout, 0, 0, 2, ' ', 0)
+	fmt.Fprintln(w, "DATE\tMILESTONE")
+	for _, milestone := range schedule.Next(time.Now(), opts.count) {
+		fmt.Fprintf(w, "%s\t%s\n",
+			milestone.Date.Format(release.ScheduleDateFormat), milestone.Name,
+		)
+	}
+	return errors.Wrap(w.Flush(), "writing milestones")
+}
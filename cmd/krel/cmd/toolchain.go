@@ -0,0 +1,183 @@
+/*
+Copyright 2020 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package cmd
+
+import (
+	"fmt"
+	"os"
+
+	"github.com/pkg/errors"
+	"github.com/sirupsen/logrus"
+	"github.com/spf13/cobra"
+
+	"k8s.io/release/pkg/golang"
+)
+
+// toolchainCmd represents the subcommand for `krel toolchain`
+var toolchainCmd = &cobra.Command{
+	Use:   "toolchain [<go-version>]",
+	Short: "Install a pinned and verified Go toolchain",
+	Long: `krel toolchain [<go-version>]
+
+Download the archive of the exact Go version for the host platform, verify
+it against the SHA256 checksum published on the Go download page and extract
+it into --dir. The GOROOT of the installed toolchain is printed to stdout,
+which allows every release manager to build with the same toolchain:
+
+  export GOROOT=$(krel toolchain) PATH=$GOROOT/bin:$PATH
+
+The version defaults to the one pinned by the Kubernetes repository (--repo),
+which is the Go version of the kube-cross image in
+build/build-image/cross/VERSION. 'krel push --nomock' fails if the go binary
+in $PATH differs from that version, and 'krel gcbmgr --nomock --go-version'
+fails if the release branch pins another version.
+
+If --verify-host is set, nothing will be installed and the command fails if
+the go binary in $PATH differs from the pinned version.`,
+	Example:       "krel toolchain 1.13.6 --dir /tmp/go-toolchain",
+	Args:          cobra.MaximumNArgs(1),
+	SilenceUsage:  true,
+	SilenceErrors: true,
+	RunE: func(cmd *cobra.Command, args []string) error {
+		if len(args) == 1 {
+			toolchainOpts.goVersion = args[0]
+		}
+		return runToolchain(toolchainOpts)
+	},
+}
+
+type toolchainOptions struct {
+	toolchainCheckOptions
+	dir         string
+	downloadURL string
+	verifyHost  bool
+}
+
+// toolchainCheckOptions are the options of verifying the Go toolchain of a
+// release, which are shared with the commands cutting releases
+type toolchainCheckOptions struct {
+	goVersion string
+}
+
+var toolchainOpts = &toolchainOptions{}
+
+func init() {
+	toolchainCmd.PersistentFlags().StringVar(
+		&toolchainOpts.dir,
+		"dir",
+		os.TempDir(),
+		"directory to install the toolchain into",
+	)
+	toolchainCmd.PersistentFlags().StringVar(
+		&toolchainOpts.downloadURL,
+		"download-url",
+		golang.DefaultDownloadURL,
+		"Go download page listing the releases and their checksums",
+	)
+	toolchainCmd.PersistentFlags().BoolVar(
+		&toolchainOpts.verifyHost,
+		"verify-host",
+		false,
+		"only verify that the host toolchain matches the pinned version",
+	)
+
+	rootCmd.AddCommand(toolchainCmd)
+}
+
+func runToolchain(opts *toolchainOptions) error {
+	version, err := pinnedGoVersion(&opts.toolchainCheckOptions, rootOpts.repoPath)
+	if err != nil {
+		return err
+	}
+	toolchain := golang.NewToolchain(version)
+	toolchain.DownloadURL = opts.downloadURL
+
+	if opts.verifyHost {
+		return checkHostToolchain(&opts.toolchainCheckOptions, rootOpts.repoPath)
+	}
+
+	if err := os.MkdirAll(opts.dir, os.FileMode(0755)); err != nil {
+		return errors.Wrapf(err, "creating directory %s", opts.dir)
+	}
+
+	goroot, err := toolchain.Install(opts.dir)
+	if err != nil {
+		return err
+	}
+	logrus.Infof("Installed %s", toolchain)
+	fmt.Println(goroot)
+	return nil
+}
+
+// addToolchainCheckFlags adds the flags of verifying the Go toolchain of a
+// release to `cmd`
+func addToolchainCheckFlags(cmd *cobra.Command, opts *toolchainCheckOptions) {
+	cmd.PersistentFlags().StringVar(
+		&opts.goVersion,
+		"go-version",
+		"",
+		"exact Go version of the release toolchain, like 1.13.6, defaults to the version pinned by the kube-cross image of the Kubernetes repository",
+	)
+}
+
+// pinnedGoVersion returns the --go-version or, if not specified, the version
+// pinned by the Kubernetes repository at `repoPath`
+func pinnedGoVersion(opts *toolchainCheckOptions, repoPath string) (string, error) {
+	if opts.goVersion != "" {
+		return opts.goVersion, nil
+	}
+	version, err := golang.PinnedVersion(repoPath)
+	if err != nil {
+		return "", errors.Wrap(err, "no pinned Go version found, use --go-version")
+	}
+	return version, nil
+}
+
+// checkHostToolchain fails if the go binary in $PATH differs from the pinned
+// Go version
+func checkHostToolchain(opts *toolchainCheckOptions, repoPath string) error {
+	version, err := pinnedGoVersion(opts, repoPath)
+	if err != nil {
+		return err
+	}
+	toolchain := golang.NewToolchain(version)
+	if err := toolchain.VerifyHost(); err != nil {
+		return errors.Wrapf(err, "install the pinned toolchain with 'krel toolchain %s'", version)
+	}
+	logrus.Infof("Host toolchain matches %s", toolchain)
+	return nil
+}
+
+// checkKubeCrossToolchain fails if the kube-cross image of a release job
+// pins another Go version than the --go-version, if specified
+func checkKubeCrossToolchain(opts *toolchainCheckOptions, kubeCrossVersion string) error {
+	version, err := golang.KubeCrossGoVersion(kubeCrossVersion)
+	if err != nil {
+		return err
+	}
+	logrus.Infof("The release job builds with Go %s of kube-cross %s", version, kubeCrossVersion)
+	if opts.goVersion == "" {
+		return nil
+	}
+	if expected := golang.NormalizeVersion(opts.goVersion); golang.NormalizeVersion(version) != expected {
+		return errors.Errorf(
+			"kube-cross %s of the release branch builds with Go %s instead of %s",
+			kubeCrossVersion, version, opts.goVersion,
+		)
+	}
+	return nil
+}
@@ -0,0 +1,207 @@
+/*
+Copyright 2020 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package cmd
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"io/ioutil"
+	"os"
+	"path"
+	"text/tabwriter"
+
+	"github.com/pkg/errors"
+	"github.com/sirupsen/logrus"
+	"github.com/spf13/cobra"
+
+	"k8s.io/release/pkg/gcp/gcs"
+	"k8s.io/release/pkg/release"
+)
+
+// verifyCmd represents the subcommand for `krel verify`
+var verifyCmd = &cobra.Command{
+	Use:   "verify <version>",
+	Short: "Verify the published artifacts of a release",
+	Long: `krel verify <version>
+
+Download all artifacts listed in the release manifest of a version pushed
+by 'krel push' and check them against the size and hashes recorded in the
+manifest. The hashes are also cross-checked against the published
+SHA256SUMS file, which has to list every artifact and the manifest itself.
+
+If --keyring is set, the signatures of the manifest, the SHA256SUMS file
+and all signed artifacts are verified with the public keys of the keyring,
+which can be exported via 'gpg --export'. Otherwise the signatures of
+signed artifacts only have to exist.
+
+The artifacts are downloaded in parallel, large artifacts in multiple
+parallel range requests of --chunk-size. At most --concurrency requests run
+at the same time across all artifacts. Use the global --max-bandwidth and
+--bandwidth-limit flags to limit the download rate.
+
+A pass/fail matrix of all artifacts is printed, the command fails if at
+least one check did not pass.`,
+	Args:          cobra.ExactArgs(1),
+	SilenceUsage:  true,
+	SilenceErrors: true,
+	RunE: func(cmd *cobra.Command, args []string) error {
+		return runVerify(verifyOpts, args[0])
+	},
+}
+
+type verifyOptions struct {
+	bucket      string
+	gcsSuffix   string
+	releaseType string
+	keyring     string
+	chunkSize   int
+	concurrency int
+}
+
+var verifyOpts = &verifyOptions{}
+
+func init() {
+	verifyCmd.PersistentFlags().StringVar(
+		&verifyOpts.bucket,
+		"bucket",
+		"kubernetes-release",
+		"GCS bucket the release was pushed to",
+	)
+	verifyCmd.PersistentFlags().StringVar(
+		&verifyOpts.releaseType,
+		"release-type",
+		"release",
+		"release type the version was pushed as (normally 'release', 'devel' or 'ci')",
+	)
+	verifyCmd.PersistentFlags().StringVar(
+		&verifyOpts.gcsSuffix,
+		"gcs-suffix",
+		"",
+		"suffix which was appended to the upload destination on GCS",
+	)
+	verifyCmd.PersistentFlags().StringVar(
+		&verifyOpts.keyring,
+		"keyring",
+		"",
+		"GPG keyring with the public keys to verify the release signatures with",
+	)
+	verifyCmd.PersistentFlags().IntVar(
+		&verifyOpts.chunkSize,
+		"chunk-size",
+		gcs.DefaultChunkSize,
+		"size in bytes of a single range request when downloading large artifacts",
+	)
+	verifyCmd.PersistentFlags().IntVar(
+		&verifyOpts.concurrency,
+		"concurrency",
+		gcs.DefaultConcurrency,
+		"maximum amount of parallel download requests across all artifacts",
+	)
+
+	rootCmd.AddCommand(verifyCmd)
+}
+
+func runVerify(opts *verifyOptions, version string) error {
+	ctx := context.Background()
+	bucket, err := gcsBucket(opts.bucket)
+	if err != nil {
+		return err
+	}
+
+	gcsPath := path.Join(opts.releaseType+opts.gcsSuffix, version)
+	open := func(name string) (io.ReadCloser, error) {
+		return gcs.NewReader(ctx, bucket, path.Join(gcsPath, name))
+	}
+
+	logrus.Infof("Verifying release %s on gs://%s/%s", version, opts.bucket, gcsPath)
+	published, err := release.ReadManifest(open)
+	if err != nil {
+		return errors.Wrap(err, "reading release manifest")
+	}
+
+	tmpDir, err := ioutil.TempDir("", "krel-verify-")
+	if err != nil {
+		return errors.Wrap(err, "creating temp dir")
+	}
+	defer os.RemoveAll(tmpDir)
+
+	downloadOpts := transferOptions(opts.bucket, opts.concurrency)
+	downloadOpts.ChunkSize = opts.chunkSize
+	if err := gcs.DownloadFiles(
+		ctx, bucket, gcsPath, published.Files(), tmpDir, downloadOpts,
+	); err != nil {
+		return errors.Wrap(err, "downloading release artifacts")
+	}
+
+	// Verify the downloaded manifest, which is covered by the checksums and
+	// signatures, rather than the one read before
+	manifest, err := release.ReadManifest(release.LocalOpen(tmpDir))
+	if err != nil {
+		return errors.Wrap(err, "reading downloaded release manifest")
+	}
+	if manifest.Version != version {
+		return errors.Errorf(
+			"release manifest is for version %s, not %s", manifest.Version, version,
+		)
+	}
+
+	results, err := release.VerifyManifest(manifest, tmpDir, opts.keyring)
+	if err != nil {
+		return errors.Wrap(err, "verifying release artifacts")
+	}
+
+	failed := 0
+	w := tabwriter.NewWriter(os.Stdout, 0, 0, 2, ' ', 0)
+	fmt.Fprintln(w, "ARTIFACT\tSIZE\tSHA256\tSHA512\tSHA256SUMS\tSIGNATURE")
+	for i := range results {
+		res := &results[i]
+		fmt.Fprintf(w, "%s\t%s\t%s\t%s\t%s\t%s\n", res.Name,
+			checkStatus(res.Size), checkStatus(res.SHA256),
+			checkStatus(res.SHA512), checkStatus(res.Sums),
+			checkStatus(res.Signature),
+		)
+		if !res.Passed() {
+			failed++
+			for _, err := range []error{
+				res.Size, res.SHA256, res.SHA512, res.Sums, res.Signature,
+			} {
+				if err != nil {
+					logrus.Errorf("%s: %v", res.Name, err)
+				}
+			}
+		}
+	}
+	if err := w.Flush(); err != nil {
+		return errors.Wrap(err, "writing verification results")
+	}
+
+	if failed > 0 {
+		return errors.Errorf(
+			"%d of %d artifacts failed verification", failed, len(results),
+		)
+	}
+	logrus.Infof("All %d artifacts of %s passed verification", len(results), version)
+	return nil
+}
+
+func checkStatus(err error) string {
+	if err != nil {
+		return "FAIL"
+	}
+	return "PASS"
+}
@@ -141,7 +141,7 @@ func init() {
 		&opts.Format,
 		"format",
 		util.EnvDefault("FORMAT", "markdown"),
-		"The format for notes output (options: markdown, json)",
+		"The format for notes output (options: markdown, json, html, website)",
 	)
 
 	cmd.PersistentFlags().StringVar(
@@ -206,6 +206,27 @@ func init() {
 		util.EnvDefault("REPLAY", ""),
 		"Replay a previously recorded API from a directory",
 	)
+
+	cmd.PersistentFlags().StringVar(
+		&opts.MapsDir,
+		"maps-from",
+		util.EnvDefault("MAPS_FROM", ""),
+		"Directory of YAML release notes maps to amend or suppress single notes",
+	)
+
+	cmd.PersistentFlags().StringVar(
+		&opts.CVEFile,
+		"cve-file",
+		util.EnvDefault("CVE_FILE", ""),
+		"YAML file of CVEs fixed in the release, added to the notes of their PRs and rendered as a separate section",
+	)
+
+	cmd.PersistentFlags().BoolVar(
+		&opts.Dependencies,
+		"dependencies",
+		util.IsEnvSet("DEPENDENCIES"),
+		"Add the go.mod dependency changes between the revisions to the website format",
+	)
 }
 
 func GetReleaseNotes() (notes.ReleaseNotes, notes.ReleaseNotesHistory, error) {
@@ -239,6 +260,16 @@ func WriteReleaseNotes(releaseNotes notes.ReleaseNotes, history notes.ReleaseNot
 		}
 	}
 
+	// The CVEs are part of every format, so they get validated up front
+	var cves []notes.CVE
+	if opts.CVEFile != "" {
+		cves, err = notes.ParseCVEs(opts.CVEFile)
+		if err != nil {
+			return errors.Wrap(err, "reading CVEs")
+		}
+		releaseNotes.AddCVEs(cves)
+	}
+
 	// Contextualized release notes can be printed in a variety of formats
 	switch opts.Format {
 	case "json":
@@ -274,11 +305,37 @@ func WriteReleaseNotes(releaseNotes notes.ReleaseNotes, history notes.ReleaseNot
 		if err := enc.Encode(releaseNotes); err != nil {
 			return errors.Wrapf(err, "encoding JSON output")
 		}
-	case "markdown":
+	case "website":
+		data := notes.CreateWebsiteData(releaseNotes, history, opts.ReleaseVersion)
+		data.CVEs = cves
+		if opts.ReleaseTars != "" {
+			if err := data.AddDownloads(
+				opts.ReleaseBucket, opts.ReleaseTars, opts.EndRev,
+			); err != nil {
+				return err
+			}
+		}
+		if opts.Dependencies {
+			repo, err := opts.Repo()
+			if err != nil {
+				return err
+			}
+			if err := data.AddDependencies(repo, opts.StartSHA, opts.EndSHA); err != nil {
+				return errors.Wrap(err, "comparing dependencies")
+			}
+		}
+
+		enc := json.NewEncoder(output)
+		enc.SetIndent("", "  ")
+		if err := enc.Encode(data); err != nil {
+			return errors.Wrapf(err, "encoding website output")
+		}
+	case "markdown", "html":
 		doc, err := notes.CreateDocument(releaseNotes, history)
 		if err != nil {
 			return errors.Wrapf(err, "creating release note document")
 		}
+		doc.CVEs = cves
 
 		markdown, err := doc.RenderMarkdown(
 			opts.ReleaseBucket, opts.ReleaseTars, opts.StartRev, opts.EndRev,
@@ -295,6 +352,13 @@ func WriteReleaseNotes(releaseNotes notes.ReleaseNotes, history notes.ReleaseNot
 			markdown = toc + "\n" + markdown
 		}
 
+		if opts.Format == "html" {
+			markdown, err = notes.MarkdownToHTML(htmlTitle(), markdown)
+			if err != nil {
+				return errors.Wrap(err, "rendering release notes to HTML")
+			}
+		}
+
 		if _, err := output.WriteString(markdown); err != nil {
 			return errors.Wrap(err, "writing output file")
 		}
@@ -310,6 +374,17 @@ func WriteReleaseNotes(releaseNotes notes.ReleaseNotes, history notes.ReleaseNot
 	return nil
 }
 
+// htmlTitle returns the title of the HTML release notes page
+func htmlTitle() string {
+	if opts.ReleaseVersion != "" {
+		return fmt.Sprintf("Release notes for %s", opts.ReleaseVersion)
+	}
+	if opts.EndRev != "" {
+		return fmt.Sprintf("Release notes for %s", opts.EndRev)
+	}
+	return "Release notes"
+}
+
 func run(*cobra.Command, []string) error {
 	releaseNotes, history, err := GetReleaseNotes()
 	if err != nil {
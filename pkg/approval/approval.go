@@ -0,0 +1,236 @@
+/*
+Copyright 2020 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package approval
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+
+	"github.com/pkg/errors"
+	"sigs.k8s.io/yaml"
+)
+
+const (
+	// SourceGitHub are the approving reviews of a GitHub PR
+	SourceGitHub = "github"
+
+	// SourceSlack are the approvals requested in a Slack channel
+	SourceSlack = "slack"
+
+	// SourceGPG are the approval signatures created with `krel approve`
+	SourceGPG = "gpg"
+)
+
+// Approver is a person allowed to approve, together with the identities
+// used by the approval sources
+type Approver struct {
+	// Name identifies the approver
+	Name string `json:"name"`
+
+	// GitHub is the GitHub login of the approver
+	GitHub string `json:"github,omitempty"`
+
+	// Slack is the Slack user ID of the approver, like U012AB3CD
+	Slack string `json:"slack,omitempty"`
+
+	// GPG is the fingerprint of the primary GPG key the approver signs
+	// approvals with
+	GPG string `json:"gpg,omitempty"`
+}
+
+// Gate requires the approval of Required distinct Approvers
+type Gate struct {
+	Required  int        `json:"required"`
+	Approvers []Approver `json:"approvers"`
+}
+
+// Gates are the approval gates mapped by the destination they protect, like
+// gs://kubernetes-release or gcr.io/k8s-staging-kubernetes
+type Gates map[string]*Gate
+
+// Approval is a single approval given via Source by Identity
+type Approval struct {
+	Source   string
+	Identity string
+}
+
+func (a Approval) String() string {
+	return a.Source + ":" + a.Identity
+}
+
+// ID returns the identifier of the approval of `action` of `version` to
+// `destination`, which approval signatures, approval PRs and Slack requests
+// are bound to.
+func ID(action, version, destination string) string {
+	return fmt.Sprintf("%s %s to %s", action, version, destination)
+}
+
+// parseGates decodes and validates the approval gates `content` read from
+// `source`, see ReadPublished
+func parseGates(content []byte, source string) (Gates, error) {
+	gates := Gates{}
+	if err := yaml.UnmarshalStrict(content, &gates); err != nil {
+		return nil, errors.Wrapf(err, "decoding approval gates file %s", source)
+	}
+	for destination, gate := range gates {
+		if err := gate.validate(); err != nil {
+			return nil, errors.Wrapf(err, "invalid approval gate for %s", destination)
+		}
+	}
+	return gates, nil
+}
+
+func (g *Gate) validate() error {
+	if g == nil || g.Required < 1 {
+		return errors.New("at least one approval has to be required")
+	}
+	if g.Required > len(g.Approvers) {
+		return errors.Errorf(
+			"%d approvals required, but only %d approvers allowed",
+			g.Required, len(g.Approvers),
+		)
+	}
+	names := map[string]bool{}
+	for _, approver := range g.Approvers {
+		if approver.Name == "" {
+			return errors.New("approver without name")
+		}
+		if names[approver.Name] {
+			return errors.Errorf("approver %s is specified multiple times", approver.Name)
+		}
+		names[approver.Name] = true
+	}
+	return nil
+}
+
+// ForDestination returns the gate with the longest destination prefix
+// matching `destination`, or nil if it is not protected by any gate. A
+// gate for gs://kubernetes-release protects all paths within the bucket.
+func (g Gates) ForDestination(destination string) *Gate {
+	match := ""
+	for prefix := range g {
+		if (destination == prefix ||
+			strings.HasPrefix(destination, strings.TrimSuffix(prefix, "/")+"/")) &&
+			len(prefix) > len(match) {
+			match = prefix
+		}
+	}
+	if match == "" {
+		return nil
+	}
+	return g[match]
+}
+
+// approver returns the approver identified by `approval`, or nil if the
+// approval is not authorized by the gate
+func (g *Gate) approver(approval Approval) *Approver {
+	for i := range g.Approvers {
+		approver := &g.Approvers[i]
+		var identity string
+		switch approval.Source {
+		case SourceGitHub:
+			identity = approver.GitHub
+		case SourceSlack:
+			identity = approver.Slack
+		case SourceGPG:
+			identity = normalizeFingerprint(approver.GPG)
+		}
+		if identity != "" && identity == approval.Identity {
+			return approver
+		}
+	}
+	return nil
+}
+
+// Exclude returns a copy of the gate without the approvers owning any of
+// the `identities`, together with the names of the excluded approvers. The
+// number of required approvals stays the same.
+func (g *Gate) Exclude(identities []Approval) (gate *Gate, excluded []string) {
+	excludedNames := map[string]bool{}
+	for _, identity := range identities {
+		if approver := g.approver(identity); approver != nil {
+			excludedNames[approver.Name] = true
+		}
+	}
+	gate = &Gate{Required: g.Required, Approvers: []Approver{}}
+	excluded = []string{}
+	for _, approver := range g.Approvers {
+		if excludedNames[approver.Name] {
+			excluded = append(excluded, approver.Name)
+			continue
+		}
+		gate.Approvers = append(gate.Approvers, approver)
+	}
+	return gate, excluded
+}
+
+// Authorized returns the first approval of every distinct approver of the
+// gate, mapped by the approver name. Approvals of unknown identities are
+// ignored and approvals of the same approver via different sources are
+// counted once.
+func (g *Gate) Authorized(approvals []Approval) map[string]Approval {
+	authorized := map[string]Approval{}
+	for _, approval := range approvals {
+		approver := g.approver(approval)
+		if approver == nil {
+			continue
+		}
+		if _, ok := authorized[approver.Name]; !ok {
+			authorized[approver.Name] = approval
+		}
+	}
+	return authorized
+}
+
+// Remaining returns the number of approvals still missing
+func (g *Gate) Remaining(approvals []Approval) int {
+	if remaining := g.Required - len(g.Authorized(approvals)); remaining > 0 {
+		return remaining
+	}
+	return 0
+}
+
+// SlackApprovers returns the Slack identities of all approvers which have
+// not approved yet
+func (g *Gate) SlackApprovers(approvals []Approval) []string {
+	authorized := g.Authorized(approvals)
+	res := []string{}
+	for _, approver := range g.Approvers {
+		if _, ok := authorized[approver.Name]; !ok && approver.Slack != "" {
+			res = append(res, approver.Slack)
+		}
+	}
+	return res
+}
+
+// Check verifies that the gate is passed by the `approvals`
+func (g *Gate) Check(approvals []Approval) error {
+	authorized := g.Authorized(approvals)
+	if len(authorized) >= g.Required {
+		return nil
+	}
+	names := []string{}
+	for name := range authorized {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	return errors.Errorf(
+		"%d of %d required approvals given (%s)",
+		len(authorized), g.Required, strings.Join(names, ", "),
+	)
+}
@@ -0,0 +1,171 @@
+/*
+Copyright 2020 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package approval
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func testGate() *Gate {
+	return &Gate{
+		Required: 2,
+		Approvers: []Approver{
+			{Name: "alice", GitHub: "alice-gh", Slack: "U1"},
+			{Name: "bob", GitHub: "bob-gh"},
+			{Name: "carol", Slack: "U3", GPG: "abcd 1234"},
+		},
+	}
+}
+
+func TestParseGates(t *testing.T) {
+	for name, tc := range map[string]struct {
+		content     string
+		shouldError bool
+	}{
+		"success": {
+			content: `{"gs://kubernetes-release": {"required": 1, "approvers": [{"name": "alice", "github": "alice-gh"}]}}`,
+		},
+		"failure nothing required": {
+			content:     `{"gs://kubernetes-release": {"required": 0, "approvers": [{"name": "alice"}]}}`,
+			shouldError: true,
+		},
+		"failure too few approvers": {
+			content:     `{"gs://kubernetes-release": {"required": 2, "approvers": [{"name": "alice"}]}}`,
+			shouldError: true,
+		},
+		"failure duplicate approver": {
+			content:     `{"gs://kubernetes-release": {"required": 2, "approvers": [{"name": "alice"}, {"name": "alice"}]}}`,
+			shouldError: true,
+		},
+		"failure unknown field": {
+			content:     `{"gs://kubernetes-release": {"required": 1, "approvers": [{"name": "alice", "email": "a@b.c"}]}}`,
+			shouldError: true,
+		},
+	} {
+		t.Run(name, func(t *testing.T) {
+			gates, err := parseGates([]byte(tc.content), "gates.yaml")
+			if tc.shouldError {
+				require.NotNil(t, err)
+			} else {
+				require.Nil(t, err)
+				require.Equal(t, 1, gates["gs://kubernetes-release"].Required)
+			}
+		})
+	}
+}
+
+func TestForDestination(t *testing.T) {
+	bucket := &Gate{Required: 1}
+	release := &Gate{Required: 2}
+	gates := Gates{
+		"gs://kubernetes-release":          bucket,
+		"gs://kubernetes-release/release/": release,
+	}
+
+	require.Equal(t, bucket, gates.ForDestination("gs://kubernetes-release"))
+	require.Equal(t, bucket, gates.ForDestination("gs://kubernetes-release/ci/v1.18.0"))
+	require.Equal(t, release, gates.ForDestination("gs://kubernetes-release/release/v1.18.0"))
+	require.Nil(t, gates.ForDestination("gs://kubernetes-release-dev/release/v1.18.0"))
+}
+
+func TestCheck(t *testing.T) {
+	for name, tc := range map[string]struct {
+		approvals   []Approval
+		remaining   int
+		slack       []string
+		shouldError bool
+	}{
+		"success two sources": {
+			approvals: []Approval{
+				{Source: SourceGitHub, Identity: "alice-gh"},
+				{Source: SourceGPG, Identity: "ABCD1234"},
+			},
+			slack: []string{},
+		},
+		"failure same approver twice": {
+			approvals: []Approval{
+				{Source: SourceGitHub, Identity: "alice-gh"},
+				{Source: SourceSlack, Identity: "U1"},
+			},
+			remaining:   1,
+			slack:       []string{"U3"},
+			shouldError: true,
+		},
+		"failure unauthorized approvals": {
+			approvals: []Approval{
+				{Source: SourceGitHub, Identity: "mallory"},
+				{Source: SourceSlack, Identity: "bob-gh"},
+				{Source: SourceGPG, Identity: "alice-gh"},
+			},
+			remaining:   2,
+			slack:       []string{"U1", "U3"},
+			shouldError: true,
+		},
+	} {
+		t.Run(name, func(t *testing.T) {
+			gate := testGate()
+			require.Equal(t, tc.remaining, gate.Remaining(tc.approvals))
+			require.Equal(t, tc.slack, gate.SlackApprovers(tc.approvals))
+			err := gate.Check(tc.approvals)
+			if tc.shouldError {
+				require.NotNil(t, err)
+			} else {
+				require.Nil(t, err)
+			}
+		})
+	}
+}
+
+func TestExclude(t *testing.T) {
+	for name, tc := range map[string]struct {
+		identities []Approval
+		excluded   []string
+		approvers  []string
+	}{
+		"success GitHub identity": {
+			identities: []Approval{{Source: SourceGitHub, Identity: "alice-gh"}},
+			excluded:   []string{"alice"},
+			approvers:  []string{"bob", "carol"},
+		},
+		"success GPG identity": {
+			identities: []Approval{
+				{Source: SourceGitHub, Identity: "mallory"},
+				{Source: SourceGPG, Identity: "ABCD1234"},
+			},
+			excluded:  []string{"carol"},
+			approvers: []string{"alice", "bob"},
+		},
+		"success unknown identities": {
+			identities: []Approval{{Source: SourceGitHub, Identity: "mallory"}},
+			excluded:   []string{},
+			approvers:  []string{"alice", "bob", "carol"},
+		},
+	} {
+		t.Run(name, func(t *testing.T) {
+			gate, excluded := testGate().Exclude(tc.identities)
+			require.Equal(t, tc.excluded, excluded)
+			require.Equal(t, 2, gate.Required)
+			approvers := []string{}
+			for _, approver := range gate.Approvers {
+				approvers = append(approvers, approver.Name)
+			}
+			require.Equal(t, tc.approvers, approvers)
+		})
+	}
+}
@@ -0,0 +1,80 @@
+/*
+Copyright 2020 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package approval
+
+import (
+	"io/ioutil"
+	"net/http"
+	"os"
+	"path/filepath"
+
+	"github.com/pkg/errors"
+)
+
+const (
+	// DefaultPublishedLocation is where the approval gates and the public
+	// keys of the approvers are published. Changing them requires a
+	// reviewed PR, unlike files supplied by the person running krel.
+	DefaultPublishedLocation = "https://raw.githubusercontent.com/kubernetes/sig-release/master/release-engineering/approval"
+
+	// PublishedGatesFile is the YAML file of the approval gates
+	PublishedGatesFile = "gates.yaml"
+
+	// PublishedKeyringFile holds the public GPG keys of all approvers,
+	// exported via `gpg --export`
+	PublishedKeyringFile = "keyring.gpg"
+)
+
+// ReadPublished downloads the approval gates and the keyring of the
+// approvers from `location`. The keyring is written to `dir`, its path is
+// returned together with the gates.
+func ReadPublished(location, dir string) (gates Gates, keyring string, err error) {
+	content, err := download(location + "/" + PublishedGatesFile)
+	if err != nil {
+		return nil, "", err
+	}
+	gates, err = parseGates(content, location+"/"+PublishedGatesFile)
+	if err != nil {
+		return nil, "", err
+	}
+
+	content, err = download(location + "/" + PublishedKeyringFile)
+	if err != nil {
+		return nil, "", err
+	}
+	keyring = filepath.Join(dir, PublishedKeyringFile)
+	if err := ioutil.WriteFile(keyring, content, os.FileMode(0644)); err != nil {
+		return nil, "", errors.Wrapf(err, "writing approval keyring %s", keyring)
+	}
+	return gates, keyring, nil
+}
+
+func download(url string) ([]byte, error) {
+	resp, err := http.Get(url)
+	if err != nil {
+		return nil, errors.Wrapf(err, "fetching %s", url)
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode != http.StatusOK {
+		return nil, errors.Errorf("fetching %s: %s", url, resp.Status)
+	}
+	content, err := ioutil.ReadAll(resp.Body)
+	if err != nil {
+		return nil, errors.Wrapf(err, "reading %s", url)
+	}
+	return content, nil
+}
@@ -0,0 +1,81 @@
+/*
+Copyright 2020 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package approval
+
+import (
+	"io/ioutil"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestReadPublished(t *testing.T) {
+	dir, err := ioutil.TempDir("", "approval-")
+	require.Nil(t, err)
+	defer os.RemoveAll(dir)
+
+	for name, tc := range map[string]struct {
+		files       map[string]string
+		shouldError bool
+	}{
+		"success": {
+			files: map[string]string{
+				"/" + PublishedGatesFile:   `{"gs://kubernetes-release": {"required": 1, "approvers": [{"name": "alice"}]}}`,
+				"/" + PublishedKeyringFile: "keys",
+			},
+		},
+		"failure invalid gates": {
+			files: map[string]string{
+				"/" + PublishedGatesFile:   `{"gs://kubernetes-release": {"required": 0}}`,
+				"/" + PublishedKeyringFile: "keys",
+			},
+			shouldError: true,
+		},
+		"failure keyring missing": {
+			files: map[string]string{
+				"/" + PublishedGatesFile: `{"gs://kubernetes-release": {"required": 1, "approvers": [{"name": "alice"}]}}`,
+			},
+			shouldError: true,
+		},
+	} {
+		t.Run(name, func(t *testing.T) {
+			server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+				content, ok := tc.files[r.URL.Path]
+				if !ok {
+					w.WriteHeader(http.StatusNotFound)
+					return
+				}
+				w.Write([]byte(content)) // nolint: errcheck
+			}))
+			defer server.Close()
+
+			gates, keyring, err := ReadPublished(server.URL, dir)
+			if tc.shouldError {
+				require.NotNil(t, err)
+				return
+			}
+			require.Nil(t, err)
+			require.Equal(t, 1, gates["gs://kubernetes-release"].Required)
+			content, err := ioutil.ReadFile(keyring)
+			require.Nil(t, err)
+			require.Equal(t, "keys", string(content))
+		})
+	}
+}
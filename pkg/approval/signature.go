@@ -0,0 +1,168 @@
+/*
+Copyright 2020 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package approval
+
+import (
+	"bufio"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"github.com/pkg/errors"
+
+	"k8s.io/release/pkg/command"
+)
+
+// GPGExecutable is the binary used for signing and verifying approvals
+const GPGExecutable = "gpg"
+
+// Sign writes the ASCII armored detached GPG signature of the approval `id`
+// to `output`, created with the secret key `keyID` of the approver. If
+// `keyID` is empty, gpg falls back to its default key.
+func Sign(id, keyID, output string) error {
+	return withIDFile(id, func(file string) error {
+		args := []string{"--batch", "--yes", "--armor", "--detach-sign"}
+		if keyID != "" {
+			args = append(args, "--local-user", keyID)
+		}
+		args = append(args, "--output", output, file)
+		if err := command.New(GPGExecutable, args...).RunSilentSuccess(); err != nil {
+			return errors.Wrapf(err, "signing approval %s", id)
+		}
+		return nil
+	})
+}
+
+// VerifySignature verifies the detached approval `signature` file for the
+// approval `id` against the public keys of `keyring` only and returns the
+// approval of the primary key which created it. The keyring is a file of
+// public keys exported via `gpg --export`.
+func VerifySignature(keyring, id, signature string) (Approval, error) {
+	keyring, err := filepath.Abs(keyring)
+	if err != nil {
+		return Approval{}, errors.Wrapf(err, "resolving keyring path")
+	}
+
+	var status string
+	if err := withIDFile(id, func(file string) error {
+		res, err := command.New(
+			GPGExecutable, "--batch", "--no-default-keyring", "--keyring", keyring,
+			"--status-fd", "1", "--verify", signature, file,
+		).RunSilentSuccessOutput()
+		if err != nil {
+			return errors.Wrapf(err, "invalid approval signature %s for %s", signature, id)
+		}
+		status = res.Output()
+		return nil
+	}); err != nil {
+		return Approval{}, err
+	}
+
+	fingerprint, err := signatureFingerprint(status)
+	if err != nil {
+		return Approval{}, errors.Wrapf(err, "verifying approval signature %s", signature)
+	}
+	return Approval{Source: SourceGPG, Identity: fingerprint}, nil
+}
+
+// SecretKeyFingerprints returns the fingerprints of all primary keys in the
+// secret keyring of the current user
+func SecretKeyFingerprints() ([]string, error) {
+	res, err := command.New(
+		GPGExecutable, "--batch", "--with-colons", "--list-secret-keys",
+	).RunSilentSuccessOutput()
+	if err != nil {
+		return nil, errors.Wrap(err, "listing the secret GPG keys")
+	}
+	return secretKeyFingerprints(res.Output()), nil
+}
+
+// signatureFingerprint returns the primary key fingerprint of the good
+// signature in the gpg `status` output. Signatures of expired or revoked
+// keys are not reported as good.
+func signatureFingerprint(status string) (string, error) {
+	good := false
+	fingerprint := ""
+	scanner := bufio.NewScanner(strings.NewReader(status))
+	for scanner.Scan() {
+		fields := strings.Fields(scanner.Text())
+		if len(fields) < 3 || fields[0] != "[GNUPG:]" {
+			continue
+		}
+		switch fields[1] {
+		case "GOODSIG":
+			good = true
+		case "VALIDSIG":
+			// The primary key fingerprint is the tenth argument, older gpg
+			// versions only report the fingerprint of the signing key
+			fingerprint = fields[2]
+			if len(fields) > 11 {
+				fingerprint = fields[11]
+			}
+		}
+	}
+	if !good || fingerprint == "" {
+		return "", errors.New("no good signature found")
+	}
+	return normalizeFingerprint(fingerprint), nil
+}
+
+// secretKeyFingerprints returns the primary key fingerprints of the gpg
+// `--with-colons` secret key listing
+func secretKeyFingerprints(listing string) []string {
+	res := []string{}
+	primary := false
+	scanner := bufio.NewScanner(strings.NewReader(listing))
+	for scanner.Scan() {
+		fields := strings.Split(scanner.Text(), ":")
+		switch fields[0] {
+		case "sec":
+			primary = true
+		case "ssb":
+			primary = false
+		case "fpr":
+			if primary && len(fields) > 9 && fields[9] != "" {
+				res = append(res, normalizeFingerprint(fields[9]))
+				primary = false
+			}
+		}
+	}
+	return res
+}
+
+func normalizeFingerprint(fingerprint string) string {
+	return strings.ToUpper(strings.ReplaceAll(fingerprint, " ", ""))
+}
+
+// withIDFile calls `fn` with a temporary file containing the approval `id`,
+// which is the data the approval signatures are created for
+func withIDFile(id string, fn func(file string) error) error {
+	f, err := ioutil.TempFile("", "approval-")
+	if err != nil {
+		return errors.Wrap(err, "creating approval ID file")
+	}
+	defer os.Remove(f.Name())
+	if _, err := f.WriteString(id); err != nil {
+		f.Close()
+		return errors.Wrap(err, "writing approval ID file")
+	}
+	if err := f.Close(); err != nil {
+		return errors.Wrap(err, "closing approval ID file")
+	}
+	return fn(f.Name())
+}
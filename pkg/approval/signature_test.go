@@ -0,0 +1,73 @@
+/*
+Copyright 2020 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package approval
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestSignatureFingerprint(t *testing.T) {
+	for name, tc := range map[string]struct {
+		status      string
+		expected    string
+		shouldError bool
+	}{
+		"success primary key": {
+			status: "[GNUPG:] NEWSIG\n" +
+				"[GNUPG:] GOODSIG 89AB alice\n" +
+				"[GNUPG:] VALIDSIG 4567 2020-06-01 1590969600 0 4 0 1 8 00 0123\n",
+			expected: "0123",
+		},
+		"success without primary key": {
+			status: "[GNUPG:] GOODSIG 89AB alice\n" +
+				"[GNUPG:] VALIDSIG 4567abcd 2020-06-01 1590969600 0 4 0 1 8 00\n",
+			expected: "4567ABCD",
+		},
+		"failure expired key": {
+			status: "[GNUPG:] EXPKEYSIG 89AB alice\n" +
+				"[GNUPG:] VALIDSIG 4567 2020-06-01 1590969600 0 4 0 1 8 00 0123\n",
+			shouldError: true,
+		},
+		"failure bad signature": {
+			status:      "[GNUPG:] BADSIG 89AB alice\n",
+			shouldError: true,
+		},
+	} {
+		t.Run(name, func(t *testing.T) {
+			res, err := signatureFingerprint(tc.status)
+			if tc.shouldError {
+				require.NotNil(t, err)
+			} else {
+				require.Nil(t, err)
+				require.Equal(t, tc.expected, res)
+			}
+		})
+	}
+}
+
+func TestSecretKeyFingerprints(t *testing.T) {
+	listing := "sec:u:255:22:89AB:1590969600:::u:::scSC:::+:::23::0:\n" +
+		"fpr:::::::::0123:\n" +
+		"uid:u::::1590969600::AAAA::alice <alice@example.com>::::::::::0:\n" +
+		"ssb:u:255:18:CDEF:1590969600::::::e:::+:::23:\n" +
+		"fpr:::::::::4567:\n" +
+		"sec:u:255:22:EF01:1590969600:::u:::scSC:::+:::23::0:\n" +
+		"fpr:::::::::89ab:\n"
+	require.Equal(t, []string{"0123", "89AB"}, secretKeyFingerprints(listing))
+}
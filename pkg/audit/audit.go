@@ -0,0 +1,258 @@
+/*
+Copyright 2020 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package audit
+
+import (
+	"bufio"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"os"
+	"os/user"
+	"path/filepath"
+	"strings"
+	"syscall"
+	"time"
+
+	"github.com/pkg/errors"
+	"github.com/sirupsen/logrus"
+)
+
+// HeadFile is the file published with a release, which contains the hash of
+// the last audit record of the push. It anchors the local log outside of the
+// machine it is written on, see VerifyHead.
+const HeadFile = "audit-head"
+
+// redacted replaces the values on the recorded command line
+const redacted = "<redacted>"
+
+// Record is a single side effecting action of a release run. Every record
+// contains the hash of its predecessor, which makes changes to the log
+// detectable by Verify. The hashes carry no key, so the whole chain can be
+// rewritten unless it is compared with a published head via VerifyHead.
+type Record struct {
+	Time         time.Time `json:"time"`
+	User         string    `json:"user"`
+	Host         string    `json:"host"`
+	Command      string    `json:"command"`
+	Version      string    `json:"version"`
+	Action       string    `json:"action"`
+	Target       string    `json:"target"`
+	PreviousHash string    `json:"previous_hash"`
+	Hash         string    `json:"hash"`
+}
+
+// Log is an append-only audit log stored as one JSON record per line
+type Log struct {
+	path string
+}
+
+// New returns the audit log stored in the file `path`
+func New(path string) *Log {
+	return &Log{path: path}
+}
+
+// Append adds the action `action` on `target` done for the release
+// `version` to the log, together with the current user, host and command
+// line. Flag values are redacted from the command line, because they may
+// contain secrets like tokens.
+func (l *Log) Append(version, action, target string) error {
+	if err := os.MkdirAll(filepath.Dir(l.path), os.FileMode(0755)); err != nil {
+		return errors.Wrapf(err, "creating directory of audit log %s", l.path)
+	}
+	f, err := os.OpenFile(
+		l.path, os.O_APPEND|os.O_CREATE|os.O_WRONLY, os.FileMode(0644),
+	)
+	if err != nil {
+		return errors.Wrapf(err, "opening audit log %s", l.path)
+	}
+	defer f.Close()
+
+	// Concurrent runs would otherwise chain to the same previous record and
+	// fork the log, so reading the head and appending happen under one lock,
+	// which is released by closing the file
+	if err := syscall.Flock(int(f.Fd()), syscall.LOCK_EX); err != nil {
+		return errors.Wrapf(err, "locking audit log %s", l.path)
+	}
+
+	records, err := l.Read()
+	if err != nil {
+		return err
+	}
+
+	record := &Record{
+		Time:    time.Now().UTC(),
+		Command: redactArgs(os.Args),
+		Version: version,
+		Action:  action,
+		Target:  target,
+	}
+	if u, err := user.Current(); err == nil {
+		record.User = u.Username
+	}
+	if host, err := os.Hostname(); err == nil {
+		record.Host = host
+	}
+	if len(records) > 0 {
+		record.PreviousHash = records[len(records)-1].Hash
+	}
+	record.Hash, err = record.hash()
+	if err != nil {
+		return err
+	}
+
+	content, err := json.Marshal(record)
+	if err != nil {
+		return errors.Wrap(err, "marshalling audit record")
+	}
+
+	logrus.Debugf("Recording %s of %s for %s in %s", action, target, version, l.path)
+	if _, err := f.Write(append(content, '\n')); err != nil {
+		return errors.Wrapf(err, "writing audit log %s", l.path)
+	}
+	return nil
+}
+
+// Head returns the hash of the last record of the log, which is empty for
+// a log without records
+func (l *Log) Head() (string, error) {
+	records, err := l.Read()
+	if err != nil {
+		return "", err
+	}
+	if len(records) == 0 {
+		return "", nil
+	}
+	return records[len(records)-1].Hash, nil
+}
+
+// Read returns all records of the log in the order they were appended. A not
+// existing log has no records.
+func (l *Log) Read() ([]Record, error) {
+	f, err := os.Open(l.path)
+	if os.IsNotExist(err) {
+		return []Record{}, nil
+	}
+	if err != nil {
+		return nil, errors.Wrapf(err, "opening audit log %s", l.path)
+	}
+	defer f.Close()
+
+	res := []Record{}
+	scanner := bufio.NewScanner(f)
+	for line := 1; scanner.Scan(); line++ {
+		if strings.TrimSpace(scanner.Text()) == "" {
+			continue
+		}
+		record := Record{}
+		if err := json.Unmarshal(scanner.Bytes(), &record); err != nil {
+			return nil, errors.Wrapf(err, "decoding line %d of audit log %s", line, l.path)
+		}
+		res = append(res, record)
+	}
+	if err := scanner.Err(); err != nil {
+		return nil, errors.Wrapf(err, "reading audit log %s", l.path)
+	}
+	return res, nil
+}
+
+// Verify checks that no record has been modified, removed or inserted after
+// it was appended
+func Verify(records []Record) error {
+	previous := ""
+	for i := range records {
+		if records[i].PreviousHash != previous {
+			return errors.Errorf(
+				"record %d does not follow its predecessor, the log has been altered", i+1,
+			)
+		}
+		hash, err := records[i].hash()
+		if err != nil {
+			return err
+		}
+		if records[i].Hash != hash {
+			return errors.Errorf(
+				"hash of record %d does not match its content, the log has been altered", i+1,
+			)
+		}
+		previous = hash
+	}
+	return nil
+}
+
+// VerifyHead checks that the published `head` is the hash of one of the
+// `records`. Together with Verify this detects logs which have been
+// truncated or rewritten since the head was published.
+func VerifyHead(records []Record, head string) error {
+	for i := range records {
+		if records[i].Hash == head {
+			return nil
+		}
+	}
+	return errors.Errorf(
+		"published head %s is not part of the log, the log has been altered", head,
+	)
+}
+
+// redactArgs returns the command line `args` with only the names of flags,
+// for example `krel push --github-token <redacted>` for
+// `krel push --github-token secret`. Every argument after the first flag
+// which is no flag itself is redacted as well, because it may be the value
+// of the preceding flag.
+func redactArgs(args []string) string {
+	res := []string{}
+	flags := false
+	for _, arg := range args {
+		switch {
+		case strings.HasPrefix(arg, "--"):
+			flags = true
+			res = append(res, strings.SplitN(arg, "=", 2)[0])
+		case strings.HasPrefix(arg, "-") && len(arg) > 1:
+			// Short flags can have their value attached, like -gsecret
+			flags = true
+			res = append(res, arg[:2])
+		case flags:
+			res = append(res, redacted)
+		default:
+			res = append(res, arg)
+		}
+	}
+	return strings.Join(res, " ")
+}
+
+// ForVersion returns the records of the release `version`
+func ForVersion(records []Record, version string) []Record {
+	res := []Record{}
+	for i := range records {
+		if records[i].Version == version {
+			res = append(res, records[i])
+		}
+	}
+	return res
+}
+
+// hash returns the SHA256 of the record content, excluding its own hash
+func (r *Record) hash() (string, error) {
+	unhashed := *r
+	unhashed.Hash = ""
+	content, err := json.Marshal(&unhashed)
+	if err != nil {
+		return "", errors.Wrap(err, "marshalling audit record")
+	}
+	sum := sha256.Sum256(content)
+	return hex.EncodeToString(sum[:]), nil
+}
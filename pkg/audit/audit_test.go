@@ -0,0 +1,180 @@
+/*
+Copyright 2020 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package audit
+
+import (
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"strings"
+	"sync"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func newTestLog(t *testing.T) (*Log, func()) {
+	dir, err := ioutil.TempDir("", "audit-")
+	require.Nil(t, err)
+	return New(filepath.Join(dir, "audit.log")), func() { os.RemoveAll(dir) }
+}
+
+func TestAppendRead(t *testing.T) {
+	log, cleanup := newTestLog(t)
+	defer cleanup()
+
+	records, err := log.Read()
+	require.Nil(t, err)
+	require.Empty(t, records)
+
+	require.Nil(t, log.Append("v1.18.0", "upload", "gs://bucket/release/v1.18.0"))
+	require.Nil(t, log.Append("v1.18.0", "tag", "gcr.io/a/kube-proxy:v1.18.0"))
+	require.Nil(t, log.Append("v1.18.1", "upload", "gs://bucket/release/v1.18.1"))
+
+	records, err = log.Read()
+	require.Nil(t, err)
+	require.Len(t, records, 3)
+	require.Equal(t, "", records[0].PreviousHash)
+	require.Equal(t, records[0].Hash, records[1].PreviousHash)
+	require.Equal(t, records[1].Hash, records[2].PreviousHash)
+	require.Equal(t, "tag", records[1].Action)
+	require.Equal(t, "gcr.io/a/kube-proxy:v1.18.0", records[1].Target)
+	require.Nil(t, Verify(records))
+
+	versionRecords := ForVersion(records, "v1.18.0")
+	require.Len(t, versionRecords, 2)
+	require.Equal(t, "upload", versionRecords[0].Action)
+	require.Equal(t, "tag", versionRecords[1].Action)
+}
+
+func TestAppendConcurrent(t *testing.T) {
+	log, cleanup := newTestLog(t)
+	defer cleanup()
+
+	// Every run opens the log on its own, like separate krel processes, and
+	// all of them start at once
+	const runs = 50
+	var wg sync.WaitGroup
+	start := make(chan struct{})
+	errs := make(chan error, runs)
+	for i := 0; i < runs; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			<-start
+			errs <- New(log.path).Append("v1.18.0", "upload", "gs://bucket/release/v1.18.0")
+		}()
+	}
+	close(start)
+	wg.Wait()
+	close(errs)
+	for err := range errs {
+		require.Nil(t, err)
+	}
+
+	records, err := log.Read()
+	require.Nil(t, err)
+	require.Len(t, records, runs)
+	require.Nil(t, Verify(records))
+}
+
+func TestVerify(t *testing.T) {
+	log, cleanup := newTestLog(t)
+	defer cleanup()
+
+	for _, action := range []string{"upload", "tag", "channel"} {
+		require.Nil(t, log.Append("v1.18.0", action, "target"))
+	}
+	records, err := log.Read()
+	require.Nil(t, err)
+
+	for name, tc := range map[string]struct {
+		modify      func([]Record) []Record
+		shouldError bool
+	}{
+		"success unmodified": {
+			modify: func(r []Record) []Record { return r },
+		},
+		"failure modified content": {
+			modify: func(r []Record) []Record {
+				r[1].Target = "other"
+				return r
+			},
+			shouldError: true,
+		},
+		"failure removed record": {
+			modify: func(r []Record) []Record {
+				return append(r[:1], r[2])
+			},
+			shouldError: true,
+		},
+		"failure recomputed hash": {
+			modify: func(r []Record) []Record {
+				r[0].User = "other"
+				r[0].Hash, _ = r[0].hash()
+				return r
+			},
+			shouldError: true,
+		},
+	} {
+		t.Run(name, func(t *testing.T) {
+			modified := append([]Record{}, records...)
+			err := Verify(tc.modify(modified))
+			if tc.shouldError {
+				require.NotNil(t, err)
+			} else {
+				require.Nil(t, err)
+			}
+		})
+	}
+}
+
+func TestHead(t *testing.T) {
+	log, cleanup := newTestLog(t)
+	defer cleanup()
+
+	head, err := log.Head()
+	require.Nil(t, err)
+	require.Empty(t, head)
+
+	require.Nil(t, log.Append("v1.18.0", "upload", "target"))
+	head, err = log.Head()
+	require.Nil(t, err)
+	records, err := log.Read()
+	require.Nil(t, err)
+	require.Equal(t, records[0].Hash, head)
+
+	require.Nil(t, log.Append("v1.18.0", "tag", "target"))
+	records, err = log.Read()
+	require.Nil(t, err)
+	require.Nil(t, VerifyHead(records, head))
+
+	// A truncated log misses the published head
+	require.NotNil(t, VerifyHead(records[1:], head))
+}
+
+func TestRedactArgs(t *testing.T) {
+	for args, expected := range map[string]string{
+		"krel push": "krel push",
+		"krel push --nomock --github-token secret": "krel push --nomock --github-token <redacted>",
+		"krel push --github-token=secret":          "krel push --github-token",
+		"krel push -gsecret v1.18.0":               "krel push -g <redacted>",
+		"krel verify v1.18.0 --keyring keys.gpg":   "krel verify v1.18.0 --keyring <redacted>",
+	} {
+		require.Equal(t, expected, redactArgs(strings.Fields(args)), args)
+	}
+}
@@ -0,0 +1,99 @@
+/*
+Copyright 2020 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package gcs
+
+import (
+	"context"
+	"fmt"
+
+	"cloud.google.com/go/storage"
+	"github.com/nozzle/throttler"
+	"github.com/pkg/errors"
+	"github.com/sirupsen/logrus"
+
+	"k8s.io/release/pkg/util"
+)
+
+// duplicate is a local file with the same content as an already uploaded
+// one. It gets copied within the bucket instead of being uploaded again.
+type duplicate struct {
+	src string
+	dst string
+}
+
+// deduplicateUploads splits the uploads into the ones with unique content
+// and the duplicates of them, which reference the object of the first
+// upload with the same SHA256 digest. Only the files missing in the known
+// `digests` are hashed.
+func deduplicateUploads(
+	uploads []upload, digests map[string]string,
+) (unique []upload, duplicates []duplicate, err error) {
+	unique = []upload{}
+	duplicates = []duplicate{}
+	objects := map[string]string{}
+	for _, u := range uploads {
+		digest, ok := digests[u.src]
+		if !ok {
+			fileDigests, err := util.DigestFile(u.src)
+			if err != nil {
+				return nil, nil, err
+			}
+			digest = fileDigests.SHA256
+		}
+		// Include the size to make accidental collisions even more unlikely
+		key := fmt.Sprintf("%s-%d", digest, u.size)
+		if dst, ok := objects[key]; ok {
+			duplicates = append(duplicates, duplicate{src: dst, dst: u.dst})
+			continue
+		}
+		objects[key] = u.dst
+		unique = append(unique, u)
+	}
+	return unique, duplicates, nil
+}
+
+// copyDuplicates copies the already uploaded objects to the destinations of
+// their duplicates within `bucket`.
+func copyDuplicates(
+	ctx context.Context, bucket *storage.BucketHandle, duplicates []duplicate, opts *Options,
+) error {
+	if len(duplicates) == 0 {
+		return nil
+	}
+
+	logrus.Infof("Copying %d duplicate files within the bucket", len(duplicates))
+	t := throttler.New(maxConcurrency(opts), len(duplicates))
+	for _, d := range duplicates {
+		go func(d duplicate) {
+			logrus.Debugf("Copying %s to %s", d.src, d.dst)
+			copier := bucket.Object(d.dst).CopierFrom(bucket.Object(d.src))
+			copier.CacheControl = opts.CacheControl
+			if _, err := copier.Run(ctx); err != nil {
+				t.Done(errors.Wrapf(err, "copying object %s to %s", d.src, d.dst))
+				return
+			}
+			t.Done(nil)
+		}(d)
+
+		// abort all, if we got one error
+		if t.Throttle() > 0 {
+			break
+		}
+	}
+
+	return t.Err()
+}
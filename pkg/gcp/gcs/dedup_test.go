@@ -0,0 +1,74 @@
+/*
+Copyright 2020 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package gcs
+
+import (
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestDeduplicateUploads(t *testing.T) {
+	tempDir, err := ioutil.TempDir("", "gcs-test-")
+	require.Nil(t, err)
+	defer os.RemoveAll(tempDir)
+
+	uploads := []upload{}
+	for _, file := range []struct{ name, content string }{
+		{"linux/amd64/LICENSE", "license"},
+		{"linux/arm64/LICENSE", "license"},
+		{"linux/amd64/kubectl", "amd64"},
+		{"linux/arm64/kubectl", "arm64"},
+		{"windows/amd64/LICENSE", "license"},
+	} {
+		path := filepath.Join(tempDir, file.name)
+		require.Nil(t, os.MkdirAll(filepath.Dir(path), os.ModePerm))
+		require.Nil(t, ioutil.WriteFile(path, []byte(file.content), os.FileMode(0644)))
+		uploads = append(uploads, upload{
+			src:  path,
+			dst:  "v1.18.0/" + file.name,
+			size: int64(len(file.content)),
+		})
+	}
+
+	unique, duplicates, err := deduplicateUploads(uploads, nil)
+	require.Nil(t, err)
+	require.Equal(t, []upload{uploads[0], uploads[2], uploads[3]}, unique)
+	require.Equal(t, []duplicate{
+		{src: "v1.18.0/linux/amd64/LICENSE", dst: "v1.18.0/linux/arm64/LICENSE"},
+		{src: "v1.18.0/linux/amd64/LICENSE", dst: "v1.18.0/windows/amd64/LICENSE"},
+	}, duplicates)
+
+	// Known digests are used instead of hashing the files
+	unique, duplicates, err = deduplicateUploads(uploads[2:4], map[string]string{
+		uploads[2].src: "same",
+		uploads[3].src: "same",
+	})
+	require.Nil(t, err)
+	require.Equal(t, []upload{uploads[2]}, unique)
+	require.Equal(t, []duplicate{
+		{src: "v1.18.0/linux/amd64/kubectl", dst: "v1.18.0/linux/arm64/kubectl"},
+	}, duplicates)
+}
+
+func TestDeduplicateUploadsNotExisting(t *testing.T) {
+	_, _, err := deduplicateUploads([]upload{{src: "/not/existing"}}, nil)
+	require.NotNil(t, err)
+}
@@ -0,0 +1,223 @@
+/*
+Copyright 2020 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package gcs
+
+import (
+	"context"
+	"io"
+	"os"
+	"path"
+	"path/filepath"
+
+	"cloud.google.com/go/storage"
+	"github.com/nozzle/throttler"
+	"github.com/pkg/errors"
+	"github.com/sirupsen/logrus"
+
+	"k8s.io/release/pkg/util"
+)
+
+// part is a byte range of an object, downloaded within a single request.
+type part struct {
+	offset int64
+	length int64
+}
+
+// requestSlots limits the amount of parallel requests of a download, which
+// are shared by all files and their parts
+type requestSlots chan struct{}
+
+func newRequestSlots(opts *Options) requestSlots {
+	return make(requestSlots, maxConcurrency(opts))
+}
+
+func (r requestSlots) acquire() { r <- struct{}{} }
+
+func (r requestSlots) release() { <-r }
+
+// DownloadFiles downloads the objects `names` below the prefix `src` in
+// `bucket` into the local directory `dst`. Every object is downloaded in
+// parallel parts like in DownloadFile, while at most `opts.Concurrency`
+// requests run in parallel across all files and parts. Nothing is
+// downloaded if any name would be written outside of `dst`.
+func DownloadFiles(
+	ctx context.Context, bucket *storage.BucketHandle, src string, names []string, dst string, opts *Options,
+) error {
+	if len(names) == 0 {
+		return nil
+	}
+
+	// The names are untrusted, so none of them may write outside of dst
+	files := make([]string, len(names))
+	for i, name := range names {
+		file, err := util.JoinWithin(dst, name)
+		if err != nil {
+			return errors.Wrapf(err, "downloading %s", name)
+		}
+		files[i] = file
+	}
+
+	logrus.Infof(
+		"Downloading %d files from %s to %s (concurrency: %d)",
+		len(names), src, dst, maxConcurrency(opts),
+	)
+	slots := newRequestSlots(opts)
+	t := throttler.New(maxConcurrency(opts), len(names))
+	for i, name := range names {
+		go func(name, file string) {
+			err := downloadFile(
+				ctx, bucket, path.Join(src, name), file, opts, slots,
+			)
+			if errors.Cause(err) == storage.ErrObjectNotExist {
+				logrus.Warnf("Skipping not existing object %s", name)
+				err = nil
+			}
+			t.Done(err)
+		}(name, files[i])
+
+		// abort all, if we got one error
+		if t.Throttle() > 0 {
+			break
+		}
+	}
+
+	return t.Err()
+}
+
+// DownloadFile downloads the object `src` in `bucket` into the local file
+// `dst`. Objects larger than `opts.ChunkSize` are split into range requests
+// of that size, of which `opts.Concurrency` run in parallel. Every range
+// request gets retried on its own and resumes at the offset it failed.
+func DownloadFile(
+	ctx context.Context, bucket *storage.BucketHandle, src, dst string, opts *Options,
+) error {
+	return downloadFile(ctx, bucket, src, dst, opts, newRequestSlots(opts))
+}
+
+func downloadFile(
+	ctx context.Context, bucket *storage.BucketHandle, src, dst string,
+	opts *Options, slots requestSlots,
+) error {
+	object := bucket.Object(src)
+	slots.acquire()
+	attrs, err := object.Attrs(ctx)
+	slots.release()
+	if err != nil {
+		return errors.Wrapf(err, "reading attributes of object %s", src)
+	}
+
+	if err := os.MkdirAll(filepath.Dir(dst), os.FileMode(0755)); err != nil {
+		return errors.Wrapf(err, "creating directory for %s", dst)
+	}
+	file, err := os.Create(dst)
+	if err != nil {
+		return errors.Wrapf(err, "creating file %s", dst)
+	}
+	defer file.Close()
+
+	parts := downloadParts(attrs.Size, int64(opts.ChunkSize))
+	if len(parts) == 0 {
+		return nil
+	}
+
+	logrus.Debugf("Downloading %s in %d parts", src, len(parts))
+	t := throttler.New(maxConcurrency(opts), len(parts))
+	for _, p := range parts {
+		go func(p part) {
+			slots.acquire()
+			defer slots.release()
+			t.Done(downloadPart(ctx, object, file, p, opts))
+		}(p)
+
+		// abort all, if we got one error
+		if t.Throttle() > 0 {
+			break
+		}
+	}
+
+	if err := t.Err(); err != nil {
+		return errors.Wrapf(err, "downloading object %s", src)
+	}
+	return nil
+}
+
+// downloadParts splits an object of `size` bytes into parts of `partSize`
+func downloadParts(size, partSize int64) []part {
+	if partSize < 1 {
+		partSize = size
+	}
+	res := []part{}
+	for offset := int64(0); offset < size; offset += partSize {
+		length := partSize
+		if offset+length > size {
+			length = size - offset
+		}
+		res = append(res, part{offset: offset, length: length})
+	}
+	return res
+}
+
+func downloadPart(
+	ctx context.Context, object *storage.ObjectHandle, file io.WriterAt, p part, opts *Options,
+) error {
+	retryOpts := util.DefaultRetryOptions()
+	retryOpts.MaxRetries = opts.Retries
+	retryOpts.Context = ctx
+
+	var written int64
+	for shouldRetry := util.Retrier(retryOpts); ; {
+		err := func() error {
+			r, err := object.NewRangeReader(ctx, p.offset+written, p.length-written)
+			if err != nil {
+				return err
+			}
+			defer r.Close()
+
+			n, err := io.Copy(
+				&offsetWriter{w: file, offset: p.offset + written},
+				opts.Limiter.Reader(r),
+			)
+			written += n
+			if err == nil && written < p.length {
+				err = io.ErrUnexpectedEOF
+			}
+			return err
+		}()
+		if !shouldRetry(err) {
+			return err
+		}
+	}
+}
+
+// offsetWriter writes sequentially into an io.WriterAt, starting at offset
+type offsetWriter struct {
+	w      io.WriterAt
+	offset int64
+}
+
+func (o *offsetWriter) Write(p []byte) (int, error) {
+	n, err := o.w.WriteAt(p, o.offset)
+	o.offset += int64(n)
+	return n, err
+}
+
+func maxConcurrency(opts *Options) int {
+	if opts.Concurrency < 1 {
+		return 1
+	}
+	return opts.Concurrency
+}
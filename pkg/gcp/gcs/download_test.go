@@ -0,0 +1,106 @@
+/*
+Copyright 2020 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package gcs
+
+import (
+	"context"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestDownloadParts(t *testing.T) {
+	for name, tc := range map[string]struct {
+		size     int64
+		partSize int64
+		expected []part
+	}{
+		"success multiple parts": {
+			size:     25,
+			partSize: 10,
+			expected: []part{
+				{offset: 0, length: 10},
+				{offset: 10, length: 10},
+				{offset: 20, length: 5},
+			},
+		},
+		"success single part": {
+			size:     5,
+			partSize: 10,
+			expected: []part{{offset: 0, length: 5}},
+		},
+		"success no part size": {
+			size:     25,
+			partSize: 0,
+			expected: []part{{offset: 0, length: 25}},
+		},
+		"success empty object": {
+			size:     0,
+			partSize: 10,
+			expected: []part{},
+		},
+	} {
+		t.Run(name, func(t *testing.T) {
+			require.Equal(t, tc.expected, downloadParts(tc.size, tc.partSize))
+		})
+	}
+}
+
+type writerAt struct {
+	buf []byte
+}
+
+func (w *writerAt) WriteAt(p []byte, off int64) (int, error) {
+	return copy(w.buf[off:], p), nil
+}
+
+func TestOffsetWriter(t *testing.T) {
+	w := &writerAt{buf: make([]byte, 6)}
+	o := &offsetWriter{w: w, offset: 2}
+	_, err := o.Write([]byte("ab"))
+	require.Nil(t, err)
+	_, err = o.Write([]byte("cd"))
+	require.Nil(t, err)
+	require.Equal(t, []byte("\x00\x00abcd"), w.buf)
+}
+
+func TestRequestSlots(t *testing.T) {
+	slots := newRequestSlots(&Options{Concurrency: 2})
+	slots.acquire()
+	slots.acquire()
+	select {
+	case slots <- struct{}{}:
+		t.Fatal("acquired more slots than the concurrency")
+	default:
+	}
+	slots.release()
+	slots.acquire()
+
+	// At least one request runs without concurrency
+	require.Equal(t, 1, cap(newRequestSlots(&Options{})))
+}
+
+func TestDownloadFilesOutsideDestination(t *testing.T) {
+	for _, name := range []string{"../../etc/x", "/etc/x"} {
+		// Fails before any request, so no bucket is needed
+		err := DownloadFiles(
+			context.Background(), nil, "release/v1.18.0",
+			[]string{"SHA256SUMS", name}, "/tmp/dst", DefaultOptions(),
+		)
+		require.NotNil(t, err)
+	}
+}
@@ -0,0 +1,383 @@
+/*
+Copyright 2020 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package gcs
+
+import (
+	"context"
+	"io"
+	"net/http"
+	"os"
+	"path"
+	"path/filepath"
+	"strings"
+
+	"cloud.google.com/go/storage"
+	"github.com/nozzle/throttler"
+	"github.com/pkg/errors"
+	"github.com/sirupsen/logrus"
+	"google.golang.org/api/googleapi"
+	"google.golang.org/api/iterator"
+
+	"k8s.io/release/pkg/util"
+)
+
+const (
+	// DefaultConcurrency is the default maximum amount of parallel uploads.
+	DefaultConcurrency = 10
+
+	// DefaultChunkSize is the default size of a single request within a
+	// resumable upload. Objects larger than this are uploaded in multiple
+	// requests, where each of them can be retried on its own.
+	DefaultChunkSize = 16 * 1024 * 1024
+
+	// DefaultCacheControl is the default Cache-Control header for uploaded
+	// release artifacts.
+	DefaultCacheControl = "public, max-age=3600"
+
+	// NoCacheControl is the Cache-Control header for objects which should
+	// never be cached, like version marker files.
+	NoCacheControl = "private, max-age=0, no-transform"
+)
+
+// Options are the settings used when uploading to and downloading from GCS.
+type Options struct {
+	// Concurrency is the maximum amount of files transferred in parallel.
+	// Downloads of large files are split into range requests, of which at
+	// most Concurrency run in parallel across all downloaded files.
+	Concurrency int
+
+	// ChunkSize is the size of a single request within a resumable upload or
+	// a ranged download.
+	ChunkSize int
+
+	// CacheControl is the Cache-Control header set on every uploaded object.
+	CacheControl string
+
+	// Retries is the amount of times a failed file upload or range request
+	// gets retried.
+	Retries int
+
+	// Limiter limits the bandwidth of all transfers using these options. The
+	// bandwidth is unlimited if not set.
+	Limiter *Limiter
+
+	// Deduplicate uploads files with identical content only once. The other
+	// files get copied from the uploaded object within the bucket.
+	Deduplicate bool
+
+	// Digests are the already known SHA256 digests of local files mapped by
+	// their path, which do not have to be hashed again for deduplication.
+	Digests map[string]string
+
+	// Conditions are the preconditions for writing a single object, like
+	// the generation it has to replace. Objects are written unconditionally
+	// if not set.
+	Conditions *storage.Conditions
+}
+
+// DefaultOptions returns a new Options instance with the default values.
+func DefaultOptions() *Options {
+	return &Options{
+		Concurrency:  DefaultConcurrency,
+		ChunkSize:    DefaultChunkSize,
+		CacheControl: DefaultCacheControl,
+		Retries:      util.DefaultRetryMaxRetries,
+	}
+}
+
+// upload is a single local file to be copied to an object.
+type upload struct {
+	src  string
+	dst  string
+	size int64
+}
+
+// CopyDirToGCS uploads the contents of the local directory `src` recursively
+// into `bucket`, prefixed by `dst`. If `opts.Deduplicate` is set, files with
+// identical content are uploaded only once and copied within the bucket
+// afterwards.
+func CopyDirToGCS(
+	ctx context.Context, bucket *storage.BucketHandle, src, dst string, opts *Options,
+) error {
+	uploads, err := uploadsForDir(src, dst)
+	if err != nil {
+		return errors.Wrapf(err, "collecting files to upload from %s", src)
+	}
+	if len(uploads) == 0 {
+		logrus.Infof("Nothing to upload from %s", src)
+		return nil
+	}
+
+	duplicates := []duplicate{}
+	if opts.Deduplicate {
+		uploads, duplicates, err = deduplicateUploads(uploads, opts.Digests)
+		if err != nil {
+			return errors.Wrapf(err, "deduplicating files to upload from %s", src)
+		}
+	}
+
+	concurrency := opts.Concurrency
+	if concurrency < 1 {
+		concurrency = 1
+	}
+
+	var total int64
+	for _, u := range uploads {
+		total += u.size
+	}
+	p := newProgress(total)
+
+	logrus.Infof(
+		"Uploading %d files (%s) from %s to %s (concurrency: %d)",
+		len(uploads), byteSize(total), src, dst, concurrency,
+	)
+	t := throttler.New(concurrency, len(uploads))
+	for _, u := range uploads {
+		go func(u upload) {
+			retryOpts := util.DefaultRetryOptions()
+			retryOpts.MaxRetries = opts.Retries
+			retryOpts.Context = ctx
+			tracker := p.tracker()
+			for shouldRetry := util.Retrier(retryOpts); ; {
+				err := copyFileToGCS(ctx, bucket, u.src, u.dst, opts, tracker)
+				if !shouldRetry(err) {
+					t.Done(err)
+					return
+				}
+				// Restart the progress of this file
+				tracker(0)
+			}
+		}(u)
+
+		// abort all, if we got one error
+		if t.Throttle() > 0 {
+			break
+		}
+	}
+
+	if err := t.Err(); err != nil {
+		return err
+	}
+	return copyDuplicates(ctx, bucket, duplicates, opts)
+}
+
+// CopyFileToGCS uploads the local file `src` to the object `dst` in `bucket`.
+func CopyFileToGCS(
+	ctx context.Context, bucket *storage.BucketHandle, src, dst string, opts *Options,
+) error {
+	return copyFileToGCS(ctx, bucket, src, dst, opts, nil)
+}
+
+func copyFileToGCS(
+	ctx context.Context,
+	bucket *storage.BucketHandle,
+	src, dst string,
+	opts *Options,
+	onProgress func(int64),
+) error {
+	file, err := os.Open(src)
+	if err != nil {
+		return errors.Wrapf(err, "opening file %s", src)
+	}
+	defer file.Close()
+
+	logrus.Debugf("Uploading %s to %s", src, dst)
+	return write(ctx, bucket, dst, file, "", opts, onProgress)
+}
+
+// WriteString writes `content` as plain text to the object `dst` in `bucket`.
+func WriteString(
+	ctx context.Context, bucket *storage.BucketHandle, dst, content string, opts *Options,
+) error {
+	return write(
+		ctx, bucket, dst, strings.NewReader(content), "text/plain", opts, nil,
+	)
+}
+
+// IsPreconditionFailed returns true if `err` is caused by a write which
+// did not meet its Options.Conditions.
+func IsPreconditionFailed(err error) bool {
+	apiErr, ok := errors.Cause(err).(*googleapi.Error)
+	return ok && apiErr.Code == http.StatusPreconditionFailed
+}
+
+// NewReader opens the object `src` in `bucket` for reading. The caller has to
+// close the returned reader.
+func NewReader(
+	ctx context.Context, bucket *storage.BucketHandle, src string,
+) (io.ReadCloser, error) {
+	r, err := bucket.Object(src).NewReader(ctx)
+	if err != nil {
+		return nil, errors.Wrapf(err, "reading object %s", src)
+	}
+	return r, nil
+}
+
+// PathExists returns true if at least one object with the prefix `dst`
+// exists in `bucket`.
+func PathExists(
+	ctx context.Context, bucket *storage.BucketHandle, dst string,
+) (bool, error) {
+	_, err := bucket.Objects(ctx, &storage.Query{Prefix: dst}).Next()
+	if err == iterator.Done {
+		return false, nil
+	}
+	if err != nil {
+		return false, errors.Wrapf(err, "listing objects for %s", dst)
+	}
+	return true, nil
+}
+
+// ListDirs returns the "directories" directly below `prefix` in `bucket`,
+// which are the common prefixes of all objects delimited by a slash. The
+// returned paths end with a slash.
+func ListDirs(
+	ctx context.Context, bucket *storage.BucketHandle, prefix string,
+) ([]string, error) {
+	res := []string{}
+	it := bucket.Objects(ctx, &storage.Query{Prefix: prefix, Delimiter: "/"})
+	for {
+		attrs, err := it.Next()
+		if err == iterator.Done {
+			break
+		}
+		if err != nil {
+			return nil, errors.Wrapf(err, "listing directories of %s", prefix)
+		}
+		if attrs.Prefix != "" {
+			res = append(res, attrs.Prefix)
+		}
+	}
+	return res, nil
+}
+
+// ListObjects returns the attributes of all objects with the prefix
+// `prefix` in `bucket`.
+func ListObjects(
+	ctx context.Context, bucket *storage.BucketHandle, prefix string,
+) ([]*storage.ObjectAttrs, error) {
+	res := []*storage.ObjectAttrs{}
+	it := bucket.Objects(ctx, &storage.Query{Prefix: prefix})
+	for {
+		attrs, err := it.Next()
+		if err == iterator.Done {
+			break
+		}
+		if err != nil {
+			return nil, errors.Wrapf(err, "listing objects of %s", prefix)
+		}
+		res = append(res, attrs)
+	}
+	return res, nil
+}
+
+// DeleteObjects deletes the objects `names` from `bucket`, using
+// `opts.Concurrency` parallel requests.
+func DeleteObjects(
+	ctx context.Context, bucket *storage.BucketHandle, names []string, opts *Options,
+) error {
+	if len(names) == 0 {
+		return nil
+	}
+
+	concurrency := opts.Concurrency
+	if concurrency < 1 {
+		concurrency = 1
+	}
+
+	t := throttler.New(concurrency, len(names))
+	for _, name := range names {
+		go func(name string) {
+			logrus.Debugf("Deleting %s", name)
+			if err := bucket.Object(name).Delete(ctx); err != nil {
+				t.Done(errors.Wrapf(err, "deleting object %s", name))
+				return
+			}
+			t.Done(nil)
+		}(name)
+
+		// abort all, if we got one error
+		if t.Throttle() > 0 {
+			break
+		}
+	}
+
+	return t.Err()
+}
+
+func write(
+	ctx context.Context,
+	bucket *storage.BucketHandle,
+	dst string,
+	content io.Reader,
+	contentType string,
+	opts *Options,
+	onProgress func(int64),
+) error {
+	obj := bucket.Object(dst)
+	if opts.Conditions != nil {
+		obj = obj.If(*opts.Conditions)
+	}
+	w := obj.NewWriter(ctx)
+	w.ChunkSize = opts.ChunkSize
+	w.CacheControl = opts.CacheControl
+	if contentType != "" {
+		w.ContentType = contentType
+	}
+	if onProgress != nil {
+		w.ProgressFunc = onProgress
+	}
+
+	if _, err := io.Copy(w, opts.Limiter.Reader(content)); err != nil {
+		w.Close() // nolint: errcheck
+		return errors.Wrapf(err, "writing object %s", dst)
+	}
+	if err := w.Close(); err != nil {
+		return errors.Wrapf(err, "finishing upload of object %s", dst)
+	}
+	if attrs := w.Attrs(); onProgress != nil && attrs != nil {
+		// The progress callback is only called per uploaded chunk, which
+		// means that small files would never be reported
+		onProgress(attrs.Size)
+	}
+	return nil
+}
+
+// uploadsForDir returns the uploads for every regular file found recursively
+// within `src`, mapped to their object names below `dst`.
+func uploadsForDir(src, dst string) ([]upload, error) {
+	res := []upload{}
+	err := filepath.Walk(src, func(p string, info os.FileInfo, err error) error {
+		if err != nil {
+			return err
+		}
+		if !info.Mode().IsRegular() {
+			return nil
+		}
+		rel, err := filepath.Rel(src, p)
+		if err != nil {
+			return err
+		}
+		res = append(res, upload{
+			src:  p,
+			dst:  path.Join(dst, filepath.ToSlash(rel)),
+			size: info.Size(),
+		})
+		return nil
+	})
+	return res, err
+}
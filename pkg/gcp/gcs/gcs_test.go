@@ -0,0 +1,89 @@
+/*
+Copyright 2020 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package gcs
+
+import (
+	"io/ioutil"
+	"net/http"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/pkg/errors"
+	"github.com/stretchr/testify/require"
+	"google.golang.org/api/googleapi"
+
+	"k8s.io/release/pkg/util"
+)
+
+func TestUploadsForDir(t *testing.T) {
+	tempDir, err := ioutil.TempDir("", "gcs-test-")
+	require.Nil(t, err)
+	defer os.RemoveAll(tempDir)
+
+	require.Nil(t, os.MkdirAll(filepath.Join(tempDir, "bin", "linux", "amd64"), os.ModePerm))
+	for _, file := range []string{
+		"kubernetes.tar.gz",
+		"SHA256SUMS",
+		"bin/linux/amd64/kubectl",
+	} {
+		require.Nil(t, ioutil.WriteFile(
+			filepath.Join(tempDir, file), []byte("test"), os.FileMode(0644),
+		))
+	}
+
+	res, err := uploadsForDir(tempDir, "ci/v1.18.0")
+	require.Nil(t, err)
+	require.Equal(t, []upload{
+		{
+			src:  filepath.Join(tempDir, "SHA256SUMS"),
+			dst:  "ci/v1.18.0/SHA256SUMS",
+			size: 4,
+		},
+		{
+			src:  filepath.Join(tempDir, "bin/linux/amd64/kubectl"),
+			dst:  "ci/v1.18.0/bin/linux/amd64/kubectl",
+			size: 4,
+		},
+		{
+			src:  filepath.Join(tempDir, "kubernetes.tar.gz"),
+			dst:  "ci/v1.18.0/kubernetes.tar.gz",
+			size: 4,
+		},
+	}, res)
+}
+
+func TestUploadsForDirNotExisting(t *testing.T) {
+	_, err := uploadsForDir("/not/existing", "ci")
+	require.NotNil(t, err)
+}
+
+func TestDefaultOptions(t *testing.T) {
+	opts := DefaultOptions()
+	require.Equal(t, DefaultConcurrency, opts.Concurrency)
+	require.Equal(t, DefaultChunkSize, opts.ChunkSize)
+	require.Equal(t, DefaultCacheControl, opts.CacheControl)
+	require.Equal(t, util.DefaultRetryMaxRetries, opts.Retries)
+}
+
+func TestIsPreconditionFailed(t *testing.T) {
+	require.True(t, IsPreconditionFailed(errors.Wrap(
+		&googleapi.Error{Code: http.StatusPreconditionFailed}, "writing",
+	)))
+	require.False(t, IsPreconditionFailed(&googleapi.Error{Code: http.StatusNotFound}))
+	require.False(t, IsPreconditionFailed(errors.New("other")))
+}
@@ -0,0 +1,116 @@
+/*
+Copyright 2020 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package gcs
+
+import (
+	"io"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/pkg/errors"
+)
+
+// limiterChunkSize is the maximum amount of bytes read at once by a limited
+// reader, which keeps the throughput smooth.
+const limiterChunkSize = 32 * 1024
+
+// Limiter limits the combined throughput of all readers sharing it.
+type Limiter struct {
+	mu             sync.Mutex
+	next           time.Time
+	parent         *Limiter
+	bytesPerSecond int64
+}
+
+// NewLimiter creates a new Limiter allowing `bytesPerSecond`. A value below
+// one disables the limit by returning nil, which is a valid Limiter.
+func NewLimiter(bytesPerSecond int64) *Limiter {
+	return NewChildLimiter(bytesPerSecond, nil)
+}
+
+// NewChildLimiter creates a new Limiter allowing `bytesPerSecond`, whose
+// readers additionally respect the limit of `parent`. A value below one
+// disables the own limit by returning the parent.
+func NewChildLimiter(bytesPerSecond int64, parent *Limiter) *Limiter {
+	if bytesPerSecond < 1 {
+		return parent
+	}
+	return &Limiter{bytesPerSecond: bytesPerSecond, parent: parent}
+}
+
+// ParseLimits parses bandwidth limits in the format
+// <destination>=<bytes-per-second> into a map of the destinations to their
+// limits.
+func ParseLimits(limits []string) (map[string]int64, error) {
+	res := map[string]int64{}
+	for _, limit := range limits {
+		parts := strings.SplitN(limit, "=", 2)
+		if len(parts) != 2 || strings.TrimSpace(parts[0]) == "" {
+			return nil, errors.Errorf(
+				"invalid bandwidth limit %q, expected <destination>=<bytes-per-second>", limit,
+			)
+		}
+		bytesPerSecond, err := strconv.ParseInt(strings.TrimSpace(parts[1]), 10, 64)
+		if err != nil || bytesPerSecond < 1 {
+			return nil, errors.Errorf("invalid bandwidth of limit %q", limit)
+		}
+		res[strings.TrimSpace(parts[0])] = bytesPerSecond
+	}
+	return res, nil
+}
+
+// Reader wraps `r` to respect the limit.
+func (l *Limiter) Reader(r io.Reader) io.Reader {
+	if l == nil {
+		return r
+	}
+	return &limitedReader{r: r, limiter: l}
+}
+
+// wait blocks until the previously transferred bytes are within the limit
+// and reserves the time needed for `n` further bytes.
+func (l *Limiter) wait(n int) {
+	l.mu.Lock()
+	now := time.Now()
+	if l.next.Before(now) {
+		l.next = now
+	}
+	delay := l.next.Sub(now)
+	l.next = l.next.Add(time.Duration(int64(n) * int64(time.Second) / l.bytesPerSecond))
+	l.mu.Unlock()
+
+	time.Sleep(delay)
+	if l.parent != nil {
+		l.parent.wait(n)
+	}
+}
+
+type limitedReader struct {
+	r       io.Reader
+	limiter *Limiter
+}
+
+func (l *limitedReader) Read(p []byte) (int, error) {
+	if len(p) > limiterChunkSize {
+		p = p[:limiterChunkSize]
+	}
+	n, err := l.r.Read(p)
+	l.limiter.wait(n)
+	return n, err
+}
@@ -0,0 +1,103 @@
+/*
+Copyright 2020 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package gcs
+
+import (
+	"bytes"
+	"io/ioutil"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestLimiter(t *testing.T) {
+	content := bytes.Repeat([]byte("a"), 8*limiterChunkSize)
+
+	// A nil limiter does not limit at all
+	require.Nil(t, NewLimiter(0))
+	res, err := ioutil.ReadAll(NewLimiter(0).Reader(bytes.NewReader(content)))
+	require.Nil(t, err)
+	require.Equal(t, content, res)
+
+	// Only the first chunk is not delayed, which results in 7 chunks of
+	// 31.25ms each
+	limiter := NewLimiter(1024 * 1024)
+	start := time.Now()
+	res, err = ioutil.ReadAll(limiter.Reader(bytes.NewReader(content)))
+	require.Nil(t, err)
+	require.Equal(t, content, res)
+	require.True(t, time.Since(start) >= 200*time.Millisecond)
+}
+
+func TestChildLimiter(t *testing.T) {
+	parent := NewLimiter(1024 * 1024)
+	require.Equal(t, parent, NewChildLimiter(0, parent))
+	require.Nil(t, NewChildLimiter(0, nil))
+
+	// The parent limit applies even if the own limit is higher
+	child := NewChildLimiter(1024*1024*1024, parent)
+	content := bytes.Repeat([]byte("a"), 8*limiterChunkSize)
+	start := time.Now()
+	res, err := ioutil.ReadAll(child.Reader(bytes.NewReader(content)))
+	require.Nil(t, err)
+	require.Equal(t, content, res)
+	require.True(t, time.Since(start) >= 200*time.Millisecond)
+}
+
+func TestParseLimits(t *testing.T) {
+	for name, tc := range map[string]struct {
+		limits      []string
+		expected    map[string]int64
+		shouldError bool
+	}{
+		"success": {
+			limits:   []string{"kubernetes-release=1048576", " gcr.io = 2048"},
+			expected: map[string]int64{"kubernetes-release": 1048576, "gcr.io": 2048},
+		},
+		"success empty": {
+			limits:   []string{},
+			expected: map[string]int64{},
+		},
+		"failure no bandwidth": {
+			limits:      []string{"kubernetes-release"},
+			shouldError: true,
+		},
+		"failure no destination": {
+			limits:      []string{"=1024"},
+			shouldError: true,
+		},
+		"failure invalid bandwidth": {
+			limits:      []string{"kubernetes-release=1M"},
+			shouldError: true,
+		},
+		"failure zero bandwidth": {
+			limits:      []string{"kubernetes-release=0"},
+			shouldError: true,
+		},
+	} {
+		t.Run(name, func(t *testing.T) {
+			res, err := ParseLimits(tc.limits)
+			if tc.shouldError {
+				require.NotNil(t, err)
+			} else {
+				require.Nil(t, err)
+				require.Equal(t, tc.expected, res)
+			}
+		})
+	}
+}
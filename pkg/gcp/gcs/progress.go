@@ -0,0 +1,99 @@
+/*
+Copyright 2020 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package gcs
+
+import (
+	"fmt"
+	"sync"
+	"time"
+
+	"github.com/sirupsen/logrus"
+)
+
+// progressStep is the percentage after which the upload progress is logged
+const progressStep = 10
+
+// progress tracks the transferred bytes of multiple parallel uploads and
+// logs every time another progressStep percent have been completed. This
+// works for non-interactive environments like CI, too.
+type progress struct {
+	mu        sync.Mutex
+	total     int64
+	done      int64
+	start     time.Time
+	milestone int64
+}
+
+func newProgress(total int64) *progress {
+	return &progress{
+		total:     total,
+		start:     time.Now(),
+		milestone: progressStep,
+	}
+}
+
+// tracker returns a callback for a single upload, which gets called with the
+// total amount of bytes transferred for that upload so far.
+func (p *progress) tracker() func(int64) {
+	var last int64
+	return func(n int64) {
+		p.add(n - last)
+		last = n
+	}
+}
+
+func (p *progress) add(n int64) {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+
+	p.done += n
+	if p.total <= 0 {
+		return
+	}
+
+	percent := p.done * 100 / p.total
+	if percent < p.milestone {
+		return
+	}
+	p.milestone = percent - percent%progressStep + progressStep
+
+	elapsed := time.Since(p.start)
+	eta := time.Duration(0)
+	if p.done > 0 {
+		eta = time.Duration(
+			float64(elapsed) * float64(p.total-p.done) / float64(p.done),
+		)
+	}
+	logrus.Infof(
+		"Uploaded %d%% (%s of %s, ETA %s)",
+		percent, byteSize(p.done), byteSize(p.total), eta.Round(time.Second),
+	)
+}
+
+// byteSize returns a human readable representation of `b` bytes
+func byteSize(b int64) string {
+	const unit = 1024
+	if b < unit {
+		return fmt.Sprintf("%d B", b)
+	}
+	div, exp := int64(unit), 0
+	for n := b / unit; n >= unit; n /= unit {
+		div *= unit
+		exp++
+	}
+	return fmt.Sprintf("%.1f %ciB", float64(b)/float64(div), "KMGTPE"[exp])
+}
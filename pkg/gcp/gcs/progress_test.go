@@ -0,0 +1,59 @@
+/*
+Copyright 2020 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package gcs
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestProgress(t *testing.T) {
+	p := newProgress(200)
+	first := p.tracker()
+	second := p.tracker()
+
+	first(10)
+	require.EqualValues(t, 10, p.done)
+	require.EqualValues(t, 10, p.milestone)
+
+	// Trackers report the cumulative amount per upload
+	first(50)
+	second(30)
+	require.EqualValues(t, 80, p.done)
+	require.EqualValues(t, 50, p.milestone)
+
+	// Reporting the same amount again must not count twice
+	first(100)
+	first(100)
+	second(100)
+	require.EqualValues(t, 200, p.done)
+	require.EqualValues(t, 110, p.milestone)
+}
+
+func TestByteSize(t *testing.T) {
+	for input, expected := range map[int64]string{
+		0:                      "0 B",
+		1023:                   "1023 B",
+		1024:                   "1.0 KiB",
+		1536:                   "1.5 KiB",
+		16 * 1024 * 1024:       "16.0 MiB",
+		3 * 1024 * 1024 * 1024: "3.0 GiB",
+	} {
+		require.Equal(t, expected, byteSize(input))
+	}
+}
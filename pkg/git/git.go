@@ -139,17 +139,17 @@ func CloneOrOpenDefaultGitHubRepoSSH(path, owner string) (*Repo, error) {
 // GitHub repository via the owner and repo. If useSSH is true, then it will
 // clone the repository using the defaultGithubAuthRoot.
 func CloneOrOpenGitHubRepo(path, owner, repo string, useSSH bool) (*Repo, error) {
-	return CloneOrOpenRepo(
-		path,
-		func() string {
-			slug := fmt.Sprintf("%s/%s", owner, repo)
-			if useSSH {
-				return defaultGithubAuthRoot + slug
-			}
-			return fmt.Sprintf("%s/%s", DefaultGithubURL, slug)
-		}(),
-		useSSH,
-	)
+	return CloneOrOpenRepo(path, GetRepoURL(owner, repo, useSSH), useSSH)
+}
+
+// GetRepoURL returns the URL of the GitHub repository via the owner and repo.
+// If useSSH is true, then the URL uses the defaultGithubAuthRoot.
+func GetRepoURL(owner, repo string, useSSH bool) string {
+	slug := fmt.Sprintf("%s/%s", owner, repo)
+	if useSSH {
+		return defaultGithubAuthRoot + slug
+	}
+	return fmt.Sprintf("%s/%s", DefaultGithubURL, slug)
 }
 
 // CloneOrOpenRepo creates a temp directory containing the provided
@@ -525,16 +525,52 @@ func (r *Repo) Merge(from string) error {
 // Push does push the specified branch to the default remote, but only if the
 // repository is not in dry run mode
 func (r *Repo) Push(remoteBranch string) error {
+	return r.PushToRemote(DefaultRemote, remoteBranch)
+}
+
+// PushToRemote does push the specified branch to the remote, which can be a
+// remote name or URL, but only if the repository is not in dry run mode
+func (r *Repo) PushToRemote(remote, remoteBranch string) error {
 	args := []string{"push"}
 	if r.dryRun {
 		logrus.Infof("Won't push due to dry run repository")
 		args = append(args, "--dry-run")
 	}
-	args = append(args, DefaultRemote, remoteBranch)
+	args = append(args, remote, remoteBranch)
 
 	return command.NewWithWorkDir(r.Dir(), gitExecutable, args...).RunSuccess()
 }
 
+// CherryPick applies the changes of the commit `rev` onto the current
+// branch. Merge commits are applied relative to their first parent, while
+// squashed or rebased commits are applied as they are. The commit message
+// references the original commit.
+func (r *Repo) CherryPick(rev string) error {
+	hash, err := r.inner.ResolveRevision(plumbing.Revision(rev))
+	if err != nil {
+		return errors.Wrapf(err, "resolving %s", rev)
+	}
+	commit, err := r.inner.CommitObject(*hash)
+	if err != nil {
+		return errors.Wrapf(err, "reading commit %s", rev)
+	}
+
+	args := []string{"cherry-pick", "-x"}
+	if commit.NumParents() > 1 {
+		args = append(args, "-m", "1")
+	}
+	args = append(args, rev)
+	if err := command.NewWithWorkDir(
+		r.Dir(), gitExecutable, args...,
+	).RunSuccess(); err != nil {
+		return errors.Wrapf(
+			err, "cherry picking %s, resolve the conflicts in %s or run "+
+				"'git cherry-pick --abort'", rev, r.Dir(),
+		)
+	}
+	return nil
+}
+
 // Head retrieves the current repository HEAD as a string
 func (r *Repo) Head() (string, error) {
 	ref, err := r.inner.Head()
@@ -699,6 +735,29 @@ func (r *Repo) CurrentBranch() (branch string, err error) {
 	return branch, nil
 }
 
+// ShowFile returns the content of the file at `path` in the revision `rev`
+func (r *Repo) ShowFile(rev, path string) (string, error) {
+	res, err := command.NewWithWorkDir(
+		r.Dir(), gitExecutable, "show", fmt.Sprintf("%s:%s", rev, path),
+	).RunSilentSuccessOutput()
+	if err != nil {
+		return "", errors.Wrapf(err, "showing %s at revision %s", path, rev)
+	}
+	return res.Output(), nil
+}
+
+// IsDirty returns true if the repository contains uncommitted changes or
+// untracked files
+func (r *Repo) IsDirty() (bool, error) {
+	res, err := command.
+		NewWithWorkDir(r.Dir(), gitExecutable, "status", "--porcelain").
+		RunSilentSuccessOutput()
+	if err != nil {
+		return false, errors.Wrap(err, "getting repository status")
+	}
+	return res.OutputTrimNL() != "", nil
+}
+
 // Rm removes files from the repository
 func (r *Repo) Rm(force bool, files ...string) error {
 	args := []string{"rm"}
@@ -279,6 +279,55 @@ func TestFailureMerge(t *testing.T) {
 	require.NotNil(t, err)
 }
 
+func TestFailureCherryPick(t *testing.T) {
+	testRepo := newTestRepo(t)
+	defer testRepo.cleanup(t)
+
+	err := testRepo.sut.CherryPick("wrong")
+	require.NotNil(t, err)
+}
+
+func TestSuccessCherryPick(t *testing.T) {
+	testRepo := newTestRepo(t)
+	defer testRepo.cleanup(t)
+
+	dir := testRepo.sut.Dir()
+	gitOutput := func(args ...string) string {
+		res, err := command.NewWithWorkDir(dir, "git", args...).RunSilentSuccessOutput()
+		require.Nil(t, err)
+		return res.OutputTrimNL()
+	}
+	commitFile := func(name string) {
+		require.Nil(t, ioutil.WriteFile(
+			filepath.Join(dir, name), []byte("test-content"), os.FileMode(0644),
+		))
+		gitOutput("add", name)
+		gitOutput("commit", "-m", "Add "+name)
+	}
+	gitOutput("config", "user.name", "John Doe")
+	gitOutput("config", "user.email", "john@doe.org")
+
+	// A squash merged PR is a single commit with one parent
+	gitOutput("checkout", git.Master)
+	commitFile("squashed-file")
+	squashed := gitOutput("rev-parse", "HEAD")
+
+	// A merged PR is a merge commit with two parents
+	gitOutput("checkout", "-b", "feature")
+	commitFile("merged-file")
+	gitOutput("checkout", git.Master)
+	gitOutput("merge", "--no-ff", "-m", "Merge feature", "feature")
+	merged := gitOutput("rev-parse", "HEAD")
+
+	gitOutput("checkout", testRepo.branchName)
+	for _, rev := range []string{squashed, merged} {
+		require.Nil(t, testRepo.sut.CherryPick(rev))
+		require.Contains(t, gitOutput("log", "-1"), "cherry picked from commit "+rev)
+	}
+	require.FileExists(t, filepath.Join(dir, "squashed-file"))
+	require.FileExists(t, filepath.Join(dir, "merged-file"))
+}
+
 func TestSuccessMergeBase(t *testing.T) {
 	testRepo := newTestRepo(t)
 	defer testRepo.cleanup(t)
@@ -651,3 +700,39 @@ func TestOpenRepoFailure(t *testing.T) {
 	require.NotNil(t, err)
 	require.Nil(t, repo)
 }
+
+func TestIsDirtySuccess(t *testing.T) {
+	testRepo := newTestRepo(t)
+	defer testRepo.cleanup(t)
+
+	dirty, err := testRepo.sut.IsDirty()
+	require.Nil(t, err)
+	require.False(t, dirty)
+
+	require.Nil(t, ioutil.WriteFile(testRepo.testFileName,
+		[]byte("changed"), os.FileMode(0644)),
+	)
+
+	dirty, err = testRepo.sut.IsDirty()
+	require.Nil(t, err)
+	require.True(t, dirty)
+}
+
+func TestSuccessShowFile(t *testing.T) {
+	testRepo := newTestRepo(t)
+	defer testRepo.cleanup(t)
+
+	content, err := testRepo.sut.ShowFile(
+		git.Master, filepath.Base(testRepo.testFileName),
+	)
+	require.Nil(t, err)
+	require.NotEmpty(t, content)
+}
+
+func TestFailureShowFile(t *testing.T) {
+	testRepo := newTestRepo(t)
+	defer testRepo.cleanup(t)
+
+	_, err := testRepo.sut.ShowFile(git.Master, "wrong")
+	require.NotNil(t, err)
+}
@@ -40,3 +40,14 @@ func TestCommit(t *testing.T) {
 	require.Nil(t, repo.Commit("msg"))
 	require.Equal(t, worktreeMock.CommitCallCount(), 1)
 }
+
+func TestGetRepoURL(t *testing.T) {
+	require.Equal(t,
+		"git@github.com:kubernetes/release",
+		git.GetRepoURL("kubernetes", "release", true),
+	)
+	require.Equal(t,
+		"https://github.com/kubernetes/release",
+		git.GetRepoURL("kubernetes", "release", false),
+	)
+}
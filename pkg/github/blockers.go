@@ -0,0 +1,54 @@
+/*
+Copyright 2020 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package github
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/google/go-github/v29/github"
+	"github.com/pkg/errors"
+)
+
+// DefaultReleaseBlockerLabel is the label marking issues and PRs which have
+// to be resolved before a release
+const DefaultReleaseBlockerLabel = "release-blocker"
+
+// ReleaseBlockers returns all open issues and PRs of the repository which
+// are labeled with `label` in the milestone.
+func ReleaseBlockers(
+	ctx context.Context, client Client, owner, repo, milestone, label string,
+) ([]github.Issue, error) {
+	query := fmt.Sprintf(
+		"repo:%s/%s is:open label:%q milestone:%q", owner, repo, label, milestone,
+	)
+	opts := &github.SearchOptions{ListOptions: github.ListOptions{PerPage: 100}}
+
+	blockers := []github.Issue{}
+	for {
+		res, resp, err := client.SearchIssues(ctx, query, opts)
+		if err != nil {
+			return nil, errors.Wrapf(err, "searching release blockers of %s", milestone)
+		}
+		blockers = append(blockers, res.Issues...)
+		if resp.NextPage == 0 {
+			break
+		}
+		opts.Page = resp.NextPage
+	}
+	return blockers, nil
+}
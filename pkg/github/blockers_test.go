@@ -0,0 +1,50 @@
+/*
+Copyright 2020 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package github
+
+import (
+	"context"
+	"testing"
+
+	"github.com/google/go-github/v29/github"
+	"github.com/stretchr/testify/require"
+)
+
+func TestReleaseBlockers(t *testing.T) {
+	client := &fakeClient{issues: []github.Issue{
+		{Number: github.Int(1)},
+		{Number: github.Int(2)},
+		{Number: github.Int(3)},
+	}}
+
+	blockers, err := ReleaseBlockers(
+		context.Background(), client, "owner", "repo", "v1.19", DefaultReleaseBlockerLabel,
+	)
+	require.Nil(t, err)
+	require.Len(t, blockers, 3)
+	require.Equal(t, 3, blockers[2].GetNumber())
+	require.Equal(t,
+		`repo:owner/repo is:open label:"release-blocker" milestone:"v1.19"`,
+		client.query,
+	)
+
+	blockers, err = ReleaseBlockers(
+		context.Background(), &fakeClient{}, "owner", "repo", "v1.19", DefaultReleaseBlockerLabel,
+	)
+	require.Nil(t, err)
+	require.Empty(t, blockers)
+}
@@ -0,0 +1,120 @@
+/*
+Copyright 2020 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package github
+
+import (
+	"context"
+	"fmt"
+	"regexp"
+	"strings"
+
+	"github.com/google/go-github/v29/github"
+	"github.com/pkg/errors"
+	"github.com/sirupsen/logrus"
+)
+
+// cherryPickDocsURL documents the cherry pick process of Kubernetes
+const cherryPickDocsURL = "https://git.k8s.io/community/contributors/devel/sig-release/cherry-picks.md"
+
+// releaseNoteRegex matches the release-note block of a PR description
+var releaseNoteRegex = regexp.MustCompile("(?s)```release-note\\r?\\n(.*?)\\r?\\n```")
+
+// CherryPickOptions are the settings used to open a cherry pick PR
+type CherryPickOptions struct {
+	// Owner and Repo are the upstream repository of the original PR
+	Owner string
+	Repo  string
+
+	// Branch is the release branch the PR is cherry picked onto
+	Branch string
+
+	// Fork is the GitHub user or organization HeadBranch was pushed to
+	Fork string
+
+	// HeadBranch is the branch containing the cherry pick
+	HeadBranch string
+}
+
+// GetMergedPullRequest returns the pull request `number`, but only if it has
+// been merged already.
+func GetMergedPullRequest(
+	ctx context.Context, client Client, owner, repo string, number int,
+) (*github.PullRequest, error) {
+	pr, _, err := client.GetPullRequest(ctx, owner, repo, number)
+	if err != nil {
+		return nil, errors.Wrapf(err, "getting PR #%d", number)
+	}
+	if !pr.GetMerged() || pr.GetMergeCommitSHA() == "" {
+		return nil, errors.Errorf("PR #%d is not merged", number)
+	}
+	return pr, nil
+}
+
+// ReleaseNoteFromBody returns the content of the release-note block of a PR
+// description, or NONE if it does not contain one.
+func ReleaseNoteFromBody(body string) string {
+	match := releaseNoteRegex.FindStringSubmatch(body)
+	if match == nil {
+		return "NONE"
+	}
+	if note := strings.TrimSpace(match[1]); note != "" {
+		return note
+	}
+	return "NONE"
+}
+
+// CherryPickTitle returns the title of the cherry pick PR for `pr`
+func CherryPickTitle(pr *github.PullRequest) string {
+	return fmt.Sprintf("Automated cherry pick of #%d: %s", pr.GetNumber(), pr.GetTitle())
+}
+
+// CherryPickBody returns the description of the cherry pick PR for `pr`. The
+// leading "Cherry pick of #<number>" reference links the cherry pick to the
+// original PR and the release note of the original PR is carried over.
+func CherryPickBody(pr *github.PullRequest, branch string) string {
+	return fmt.Sprintf(`Cherry pick of #%d on %s.
+
+#%d: %s
+
+For details on the cherry pick process, see the [cherry pick requests](%s) page.
+
+`+"```release-note\n%s\n```\n",
+		pr.GetNumber(), branch, pr.GetNumber(), pr.GetTitle(),
+		cherryPickDocsURL, ReleaseNoteFromBody(pr.GetBody()),
+	)
+}
+
+// CreateCherryPickPullRequest opens the cherry pick PR for `pr` against the
+// release branch
+func CreateCherryPickPullRequest(
+	ctx context.Context, client Client, opts *CherryPickOptions, pr *github.PullRequest,
+) (*github.PullRequest, error) {
+	logrus.Infof(
+		"Opening cherry pick PR of #%d against %s", pr.GetNumber(), opts.Branch,
+	)
+	result, _, err := client.CreatePullRequest(ctx, opts.Owner, opts.Repo, &github.NewPullRequest{
+		Title:               github.String(CherryPickTitle(pr)),
+		Head:                github.String(fmt.Sprintf("%s:%s", opts.Fork, opts.HeadBranch)),
+		Base:                github.String(opts.Branch),
+		Body:                github.String(CherryPickBody(pr, opts.Branch)),
+		MaintainerCanModify: github.Bool(true),
+	})
+	if err != nil {
+		return nil, errors.Wrapf(err, "creating cherry pick PR of #%d", pr.GetNumber())
+	}
+	return result, nil
+}
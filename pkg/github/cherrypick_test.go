@@ -0,0 +1,97 @@
+/*
+Copyright 2020 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package github
+
+import (
+	"context"
+	"testing"
+
+	"github.com/google/go-github/v29/github"
+	"github.com/stretchr/testify/require"
+)
+
+func TestReleaseNoteFromBody(t *testing.T) {
+	for name, tc := range map[string]struct {
+		body     string
+		expected string
+	}{
+		"Note": {
+			body:     "Fix it\n\n```release-note\nFixed a bug in kubectl\n```\n",
+			expected: "Fixed a bug in kubectl",
+		},
+		"NoteWithCarriageReturns": {
+			body:     "Fix it\r\n\r\n```release-note\r\nFixed a bug\r\n```\r\n",
+			expected: "Fixed a bug",
+		},
+		"NoNote": {
+			body:     "Fix it",
+			expected: "NONE",
+		},
+		"EmptyNote": {
+			body:     "```release-note\n\n```",
+			expected: "NONE",
+		},
+	} {
+		t.Run(name, func(t *testing.T) {
+			require.Equal(t, tc.expected, ReleaseNoteFromBody(tc.body))
+		})
+	}
+}
+
+func TestGetMergedPullRequest(t *testing.T) {
+	ctx := context.Background()
+
+	client := &fakeClient{pr: &github.PullRequest{
+		Number:         github.Int(1),
+		Merged:         github.Bool(true),
+		MergeCommitSHA: github.String("0123456789abcdef"),
+	}}
+	pr, err := GetMergedPullRequest(ctx, client, "owner", "repo", 1)
+	require.Nil(t, err)
+	require.Equal(t, 1, pr.GetNumber())
+
+	client.pr.Merged = github.Bool(false)
+	_, err = GetMergedPullRequest(ctx, client, "owner", "repo", 1)
+	require.NotNil(t, err)
+
+	_, err = GetMergedPullRequest(ctx, &fakeClient{}, "owner", "repo", 1)
+	require.NotNil(t, err)
+}
+
+func TestCreateCherryPickPullRequest(t *testing.T) {
+	client := &fakeClient{}
+	pr := &github.PullRequest{
+		Number: github.Int(1),
+		Title:  github.String("Fix the bug"),
+		Body:   github.String("```release-note\nFixed the bug\n```"),
+	}
+
+	res, err := CreateCherryPickPullRequest(context.Background(), client, &CherryPickOptions{
+		Owner:      "owner",
+		Repo:       "repo",
+		Branch:     "release-1.18",
+		Fork:       "user",
+		HeadBranch: "automated-cherry-pick-of-#1-release-1.18",
+	}, pr)
+	require.Nil(t, err)
+	require.Equal(t, 2, res.GetNumber())
+	require.Equal(t, "Automated cherry pick of #1: Fix the bug", *client.newPR.Title)
+	require.Equal(t, "user:automated-cherry-pick-of-#1-release-1.18", *client.newPR.Head)
+	require.Equal(t, "release-1.18", *client.newPR.Base)
+	require.Contains(t, *client.newPR.Body, "Cherry pick of #1 on release-1.18.")
+	require.Contains(t, *client.newPR.Body, "```release-note\nFixed the bug\n```")
+}
@@ -0,0 +1,465 @@
+/*
+Copyright 2020 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package github
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+
+	"github.com/google/go-github/v29/github"
+	"github.com/pkg/errors"
+	"github.com/sirupsen/logrus"
+	"golang.org/x/oauth2"
+
+	"k8s.io/release/pkg/util"
+)
+
+const (
+	// DefaultUploadAttempts is the default number of times a release asset
+	// upload is tried before giving up.
+	DefaultUploadAttempts = 3
+
+	assetStateUploaded = "uploaded"
+	assetsPerPage      = 100
+	releasesPerPage    = 100
+)
+
+// Client is the subset of the GitHub API needed to manage releases
+type Client interface {
+	ListReleases(ctx context.Context, owner, repo string, opt *github.ListOptions) ([]*github.RepositoryRelease, *github.Response, error)
+	CreateRelease(ctx context.Context, owner, repo string, release *github.RepositoryRelease) (*github.RepositoryRelease, *github.Response, error)
+	EditRelease(ctx context.Context, owner, repo string, id int64, release *github.RepositoryRelease) (*github.RepositoryRelease, *github.Response, error)
+	ListReleaseAssets(ctx context.Context, owner, repo string, id int64, opt *github.ListOptions) ([]*github.ReleaseAsset, *github.Response, error)
+	DeleteReleaseAsset(ctx context.Context, owner, repo string, id int64) (*github.Response, error)
+	UploadReleaseAsset(ctx context.Context, owner, repo string, id int64, opt *github.UploadOptions, file *os.File) (*github.ReleaseAsset, *github.Response, error)
+	RateLimits(ctx context.Context) (*github.RateLimits, *github.Response, error)
+	GetPullRequest(ctx context.Context, owner, repo string, number int) (*github.PullRequest, *github.Response, error)
+	CreatePullRequest(ctx context.Context, owner, repo string, pull *github.NewPullRequest) (*github.PullRequest, *github.Response, error)
+	ListReviews(ctx context.Context, owner, repo string, number int, opt *github.ListOptions) ([]*github.PullRequestReview, *github.Response, error)
+	SearchIssues(ctx context.Context, query string, opts *github.SearchOptions) (*github.IssuesSearchResult, *github.Response, error)
+	GetAuthenticatedUser(ctx context.Context) (*github.User, *github.Response, error)
+	ListTeams(ctx context.Context, org string, opt *github.ListOptions) ([]*github.Team, *github.Response, error)
+	GetTeamMembership(ctx context.Context, team int64, user string) (*github.Membership, *github.Response, error)
+}
+
+// New creates a new Client authenticated with the provided token
+func New(ctx context.Context, token string) Client {
+	httpClient := oauth2.NewClient(ctx, oauth2.StaticTokenSource(
+		&oauth2.Token{AccessToken: token},
+	))
+	return &githubClient{github.NewClient(httpClient)}
+}
+
+type githubClient struct {
+	*github.Client
+}
+
+var _ Client = &githubClient{}
+
+func (c *githubClient) ListReleases(ctx context.Context, owner, repo string, opt *github.ListOptions) ([]*github.RepositoryRelease, *github.Response, error) {
+	for shouldRetry := util.Retrier(RetryOptions(ctx)); ; {
+		res, resp, err := c.Repositories.ListReleases(ctx, owner, repo, opt)
+		if !shouldRetry(err) {
+			return res, resp, err
+		}
+	}
+}
+
+func (c *githubClient) CreateRelease(ctx context.Context, owner, repo string, release *github.RepositoryRelease) (*github.RepositoryRelease, *github.Response, error) {
+	for shouldRetry := util.Retrier(RetryOptions(ctx)); ; {
+		res, resp, err := c.Repositories.CreateRelease(ctx, owner, repo, release)
+		if !shouldRetry(err) {
+			return res, resp, err
+		}
+	}
+}
+
+func (c *githubClient) EditRelease(ctx context.Context, owner, repo string, id int64, release *github.RepositoryRelease) (*github.RepositoryRelease, *github.Response, error) {
+	for shouldRetry := util.Retrier(RetryOptions(ctx)); ; {
+		res, resp, err := c.Repositories.EditRelease(ctx, owner, repo, id, release)
+		if !shouldRetry(err) {
+			return res, resp, err
+		}
+	}
+}
+
+func (c *githubClient) ListReleaseAssets(ctx context.Context, owner, repo string, id int64, opt *github.ListOptions) ([]*github.ReleaseAsset, *github.Response, error) {
+	for shouldRetry := util.Retrier(RetryOptions(ctx)); ; {
+		res, resp, err := c.Repositories.ListReleaseAssets(ctx, owner, repo, id, opt)
+		if !shouldRetry(err) {
+			return res, resp, err
+		}
+	}
+}
+
+func (c *githubClient) DeleteReleaseAsset(ctx context.Context, owner, repo string, id int64) (*github.Response, error) {
+	for shouldRetry := util.Retrier(RetryOptions(ctx)); ; {
+		resp, err := c.Repositories.DeleteReleaseAsset(ctx, owner, repo, id)
+		if !shouldRetry(err) {
+			return resp, err
+		}
+	}
+}
+
+func (c *githubClient) UploadReleaseAsset(ctx context.Context, owner, repo string, id int64, opt *github.UploadOptions, file *os.File) (*github.ReleaseAsset, *github.Response, error) {
+	return c.Repositories.UploadReleaseAsset(ctx, owner, repo, id, opt, file)
+}
+
+func (c *githubClient) RateLimits(ctx context.Context) (*github.RateLimits, *github.Response, error) {
+	for shouldRetry := util.Retrier(RetryOptions(ctx)); ; {
+		res, resp, err := c.Client.RateLimits(ctx)
+		if !shouldRetry(err) {
+			return res, resp, err
+		}
+	}
+}
+
+func (c *githubClient) GetPullRequest(ctx context.Context, owner, repo string, number int) (*github.PullRequest, *github.Response, error) {
+	for shouldRetry := util.Retrier(RetryOptions(ctx)); ; {
+		res, resp, err := c.PullRequests.Get(ctx, owner, repo, number)
+		if !shouldRetry(err) {
+			return res, resp, err
+		}
+	}
+}
+
+func (c *githubClient) CreatePullRequest(ctx context.Context, owner, repo string, pull *github.NewPullRequest) (*github.PullRequest, *github.Response, error) {
+	for shouldRetry := util.Retrier(RetryOptions(ctx)); ; {
+		res, resp, err := c.PullRequests.Create(ctx, owner, repo, pull)
+		if !shouldRetry(err) {
+			return res, resp, err
+		}
+	}
+}
+
+func (c *githubClient) ListReviews(ctx context.Context, owner, repo string, number int, opt *github.ListOptions) ([]*github.PullRequestReview, *github.Response, error) {
+	for shouldRetry := util.Retrier(RetryOptions(ctx)); ; {
+		res, resp, err := c.PullRequests.ListReviews(ctx, owner, repo, number, opt)
+		if !shouldRetry(err) {
+			return res, resp, err
+		}
+	}
+}
+
+func (c *githubClient) SearchIssues(ctx context.Context, query string, opts *github.SearchOptions) (*github.IssuesSearchResult, *github.Response, error) {
+	for shouldRetry := util.Retrier(RetryOptions(ctx)); ; {
+		res, resp, err := c.Search.Issues(ctx, query, opts)
+		if !shouldRetry(err) {
+			return res, resp, err
+		}
+	}
+}
+
+func (c *githubClient) GetAuthenticatedUser(ctx context.Context) (*github.User, *github.Response, error) {
+	for shouldRetry := util.Retrier(RetryOptions(ctx)); ; {
+		res, resp, err := c.Users.Get(ctx, "")
+		if !shouldRetry(err) {
+			return res, resp, err
+		}
+	}
+}
+
+func (c *githubClient) ListTeams(ctx context.Context, org string, opt *github.ListOptions) ([]*github.Team, *github.Response, error) {
+	for shouldRetry := util.Retrier(RetryOptions(ctx)); ; {
+		res, resp, err := c.Teams.ListTeams(ctx, org, opt)
+		if !shouldRetry(err) {
+			return res, resp, err
+		}
+	}
+}
+
+func (c *githubClient) GetTeamMembership(ctx context.Context, team int64, user string) (*github.Membership, *github.Response, error) {
+	for shouldRetry := util.Retrier(RetryOptions(ctx)); ; {
+		res, resp, err := c.Teams.GetTeamMembership(ctx, team, user)
+		if !shouldRetry(err) {
+			return res, resp, err
+		}
+	}
+}
+
+// ReleaseOptions are the settings used to create or update a GitHub release
+type ReleaseOptions struct {
+	Owner           string
+	Repo            string
+	Tag             string
+	TargetCommitish string
+	Name            string
+	Body            string
+
+	// Assets are the local file paths to be uploaded to the release. Assets
+	// which already exist on the release with the same size are skipped,
+	// which allows resuming an interrupted upload.
+	Assets []string
+
+	// UploadAttempts is the number of times a single asset upload is tried
+	UploadAttempts int
+
+	Draft      bool
+	Prerelease bool
+}
+
+// UpdateRelease creates the release for the tag if it does not exist yet,
+// otherwise it updates the existing one. Afterwards all assets are uploaded
+// to the release.
+func UpdateRelease(
+	ctx context.Context, client Client, opts *ReleaseOptions,
+) (*github.RepositoryRelease, error) {
+	release := &github.RepositoryRelease{
+		TagName:         github.String(opts.Tag),
+		TargetCommitish: github.String(opts.TargetCommitish),
+		Name:            github.String(opts.Name),
+		Body:            github.String(opts.Body),
+		Draft:           github.Bool(opts.Draft),
+		Prerelease:      github.Bool(opts.Prerelease),
+	}
+
+	// Creating or updating the release is retried on any error. The release
+	// is looked up again on every try, because a failed create might still
+	// have gone through on the GitHub side.
+	retryOpts := util.DefaultRetryOptions()
+	retryOpts.Context = ctx
+	retryOpts.Delay = RateLimitDelay
+
+	var result *github.RepositoryRelease
+	var err error
+	for shouldRetry := util.Retrier(retryOpts); ; {
+		result, err = createOrEditRelease(ctx, client, opts, release)
+		if !shouldRetry(err) {
+			break
+		}
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	if err := uploadAssets(ctx, client, opts, result.GetID()); err != nil {
+		return nil, err
+	}
+	return result, nil
+}
+
+func createOrEditRelease(
+	ctx context.Context, client Client, opts *ReleaseOptions,
+	release *github.RepositoryRelease,
+) (*github.RepositoryRelease, error) {
+	existing, err := findRelease(ctx, client, opts.Owner, opts.Repo, opts.Tag)
+	if err != nil {
+		return nil, errors.Wrapf(err, "getting release for tag %s", opts.Tag)
+	}
+
+	if existing == nil {
+		logrus.Infof("Creating the %s release on GitHub", opts.Tag)
+		result, _, err := client.CreateRelease(ctx, opts.Owner, opts.Repo, release)
+		if err != nil {
+			return nil, errors.Wrapf(err, "creating release %s", opts.Tag)
+		}
+		return result, nil
+	}
+
+	logrus.Infof(
+		"Updating the existing %s release (id #%d) on GitHub",
+		opts.Tag, existing.GetID(),
+	)
+	result, _, err := client.EditRelease(
+		ctx, opts.Owner, opts.Repo, existing.GetID(), release,
+	)
+	if err != nil {
+		return nil, errors.Wrapf(err, "updating release %s", opts.Tag)
+	}
+	return result, nil
+}
+
+// findRelease returns the release of the tag, or nil if there is none.
+// Other than the releases/tags API, listing the releases also returns the
+// draft releases, which are not bound to their tag before being published.
+func findRelease(
+	ctx context.Context, client Client, owner, repo, tag string,
+) (*github.RepositoryRelease, error) {
+	listOpts := &github.ListOptions{PerPage: releasesPerPage}
+	for {
+		page, resp, err := client.ListReleases(ctx, owner, repo, listOpts)
+		if err != nil {
+			return nil, errors.Wrap(err, "listing releases")
+		}
+		for _, release := range page {
+			if release.GetTagName() == tag {
+				return release, nil
+			}
+		}
+		if resp == nil || resp.NextPage == 0 {
+			return nil, nil
+		}
+		listOpts.Page = resp.NextPage
+	}
+}
+
+// YankRelease marks the existing release of the tag as pre-release, so that
+// it is not shown as the latest release of the repository anymore.
+func YankRelease(
+	ctx context.Context, client Client, owner, repo, tag string,
+) (*github.RepositoryRelease, error) {
+	existing, err := findRelease(ctx, client, owner, repo, tag)
+	if err != nil {
+		return nil, errors.Wrapf(err, "getting release for tag %s", tag)
+	}
+	if existing == nil {
+		return nil, errors.Errorf("no release found for tag %s", tag)
+	}
+	if existing.GetPrerelease() {
+		logrus.Infof("Release %s is already marked as pre-release", tag)
+		return existing, nil
+	}
+
+	logrus.Infof("Marking the %s release (id #%d) as pre-release", tag, existing.GetID())
+	result, _, err := client.EditRelease(
+		ctx, owner, repo, existing.GetID(),
+		&github.RepositoryRelease{Prerelease: github.Bool(true)},
+	)
+	if err != nil {
+		return nil, errors.Wrapf(err, "updating release %s", tag)
+	}
+	return result, nil
+}
+
+func uploadAssets(
+	ctx context.Context, client Client, opts *ReleaseOptions, releaseID int64,
+) error {
+	if len(opts.Assets) == 0 {
+		return nil
+	}
+
+	existing, err := listAssets(ctx, client, opts, releaseID)
+	if err != nil {
+		return err
+	}
+
+	attempts := opts.UploadAttempts
+	if attempts < 1 {
+		attempts = DefaultUploadAttempts
+	}
+
+	for _, assetPath := range opts.Assets {
+		fileInfo, err := os.Stat(assetPath)
+		if err != nil {
+			return errors.Wrapf(err, "reading asset %s", assetPath)
+		}
+		name := filepath.Base(assetPath)
+
+		if asset, ok := existing[name]; ok {
+			if asset.GetState() == assetStateUploaded &&
+				int64(asset.GetSize()) == fileInfo.Size() {
+				logrus.Infof("Asset %s already uploaded, skipping", name)
+				continue
+			}
+			if err := deleteAsset(ctx, client, opts, asset); err != nil {
+				return err
+			}
+		}
+
+		retryOpts := util.DefaultRetryOptions()
+		retryOpts.MaxRetries = attempts - 1
+		retryOpts.Context = ctx
+		retryOpts.Delay = RateLimitDelay
+		for shouldRetry, retried := util.Retrier(retryOpts), false; ; retried = true {
+			// A failed upload may leave a partial asset behind, which would
+			// make the retry fail because the asset already exists
+			if retried {
+				if err := deletePartialAsset(ctx, client, opts, releaseID, name); err != nil {
+					return err
+				}
+			}
+			logrus.Infof("Uploading asset %s", name)
+			err = uploadAsset(ctx, client, opts, releaseID, assetPath, name)
+			if !shouldRetry(err) {
+				break
+			}
+		}
+		if err != nil {
+			return errors.Wrapf(err, "uploading asset %s", name)
+		}
+	}
+
+	return nil
+}
+
+// deletePartialAsset removes the asset `name` of the release, if a failed
+// upload created it
+func deletePartialAsset(
+	ctx context.Context, client Client, opts *ReleaseOptions, releaseID int64, name string,
+) error {
+	existing, err := listAssets(ctx, client, opts, releaseID)
+	if err != nil {
+		return err
+	}
+	if asset, ok := existing[name]; ok {
+		return deleteAsset(ctx, client, opts, asset)
+	}
+	return nil
+}
+
+func deleteAsset(
+	ctx context.Context, client Client, opts *ReleaseOptions, asset *github.ReleaseAsset,
+) error {
+	logrus.Infof("Removing incomplete asset %s", asset.GetName())
+	if _, err := client.DeleteReleaseAsset(
+		ctx, opts.Owner, opts.Repo, asset.GetID(),
+	); err != nil {
+		return errors.Wrapf(err, "deleting asset %s", asset.GetName())
+	}
+	return nil
+}
+
+func uploadAsset(
+	ctx context.Context, client Client, opts *ReleaseOptions,
+	releaseID int64, assetPath, name string,
+) error {
+	file, err := os.Open(assetPath)
+	if err != nil {
+		return err
+	}
+	defer file.Close()
+
+	_, _, err = client.UploadReleaseAsset(
+		ctx, opts.Owner, opts.Repo, releaseID,
+		&github.UploadOptions{Name: name}, file,
+	)
+	return err
+}
+
+func listAssets(
+	ctx context.Context, client Client, opts *ReleaseOptions, releaseID int64,
+) (map[string]*github.ReleaseAsset, error) {
+	assets := make(map[string]*github.ReleaseAsset)
+	listOpts := &github.ListOptions{PerPage: assetsPerPage}
+	for {
+		page, resp, err := client.ListReleaseAssets(
+			ctx, opts.Owner, opts.Repo, releaseID, listOpts,
+		)
+		if err != nil {
+			return nil, errors.Wrap(err, "listing release assets")
+		}
+		for _, asset := range page {
+			assets[asset.GetName()] = asset
+		}
+		if resp == nil || resp.NextPage == 0 {
+			break
+		}
+		listOpts.Page = resp.NextPage
+	}
+	return assets, nil
+}
@@ -0,0 +1,333 @@
+/*
+Copyright 2020 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package github
+
+import (
+	"context"
+	"io/ioutil"
+	"net/http"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/google/go-github/v29/github"
+	"github.com/pkg/errors"
+	"github.com/stretchr/testify/require"
+)
+
+type fakeClient struct {
+	releases      []*github.RepositoryRelease
+	createErrs    int
+	assets        []*github.ReleaseAsset
+	pr            *github.PullRequest
+	newPR         *github.NewPullRequest
+	issues        []github.Issue
+	query         string
+	uploadErrs    int
+	partialAssets bool
+	created       bool
+	edited        bool
+	deletedAssets []int64
+	uploaded      []string
+	login         string
+	teams         []*github.Team
+	members       map[int64]string
+	reviews       []*github.PullRequestReview
+}
+
+func (f *fakeClient) ListReleases(ctx context.Context, owner, repo string, opt *github.ListOptions) ([]*github.RepositoryRelease, *github.Response, error) {
+	// Serve one release per page to test the pagination
+	page := opt.Page
+	if page == 0 {
+		page = 1
+	}
+	resp := &github.Response{}
+	if page < len(f.releases) {
+		resp.NextPage = page + 1
+	}
+	if page > len(f.releases) {
+		return nil, resp, nil
+	}
+	return f.releases[page-1 : page], resp, nil
+}
+
+func (f *fakeClient) CreateRelease(ctx context.Context, owner, repo string, release *github.RepositoryRelease) (*github.RepositoryRelease, *github.Response, error) {
+	if f.createErrs > 0 {
+		f.createErrs--
+		return nil, nil, errors.New("create failed")
+	}
+	f.created = true
+	release.ID = github.Int64(1)
+	return release, &github.Response{}, nil
+}
+
+func (f *fakeClient) EditRelease(ctx context.Context, owner, repo string, id int64, release *github.RepositoryRelease) (*github.RepositoryRelease, *github.Response, error) {
+	f.edited = true
+	release.ID = github.Int64(id)
+	return release, &github.Response{}, nil
+}
+
+func (f *fakeClient) ListReleaseAssets(ctx context.Context, owner, repo string, id int64, opt *github.ListOptions) ([]*github.ReleaseAsset, *github.Response, error) {
+	return f.assets, &github.Response{}, nil
+}
+
+func (f *fakeClient) DeleteReleaseAsset(ctx context.Context, owner, repo string, id int64) (*github.Response, error) {
+	f.deletedAssets = append(f.deletedAssets, id)
+	assets := []*github.ReleaseAsset{}
+	for _, asset := range f.assets {
+		if asset.GetID() != id {
+			assets = append(assets, asset)
+		}
+	}
+	f.assets = assets
+	return &github.Response{}, nil
+}
+
+func (f *fakeClient) UploadReleaseAsset(ctx context.Context, owner, repo string, id int64, opt *github.UploadOptions, file *os.File) (*github.ReleaseAsset, *github.Response, error) {
+	for _, asset := range f.assets {
+		if asset.GetName() == opt.Name {
+			return nil, nil, errors.New("422 already_exists")
+		}
+	}
+	if f.uploadErrs > 0 {
+		f.uploadErrs--
+		if f.partialAssets {
+			f.assets = append(f.assets, &github.ReleaseAsset{
+				ID:    github.Int64(int64(100 + len(f.assets))),
+				Name:  github.String(opt.Name),
+				State: github.String("starter"),
+			})
+		}
+		return nil, nil, errors.New("upload failed")
+	}
+	f.uploaded = append(f.uploaded, opt.Name)
+	return &github.ReleaseAsset{Name: github.String(opt.Name)}, &github.Response{}, nil
+}
+
+func (f *fakeClient) RateLimits(ctx context.Context) (*github.RateLimits, *github.Response, error) {
+	return &github.RateLimits{}, &github.Response{}, nil
+}
+
+func (f *fakeClient) GetPullRequest(ctx context.Context, owner, repo string, number int) (*github.PullRequest, *github.Response, error) {
+	if f.pr == nil {
+		return nil, &github.Response{}, errors.New("not found")
+	}
+	return f.pr, &github.Response{}, nil
+}
+
+func (f *fakeClient) CreatePullRequest(ctx context.Context, owner, repo string, pull *github.NewPullRequest) (*github.PullRequest, *github.Response, error) {
+	f.newPR = pull
+	return &github.PullRequest{Number: github.Int(2)}, &github.Response{}, nil
+}
+
+func (f *fakeClient) ListReviews(ctx context.Context, owner, repo string, number int, opt *github.ListOptions) ([]*github.PullRequestReview, *github.Response, error) {
+	return f.reviews, &github.Response{}, nil
+}
+
+func (f *fakeClient) SearchIssues(ctx context.Context, query string, opts *github.SearchOptions) (*github.IssuesSearchResult, *github.Response, error) {
+	f.query = query
+	// Serve one issue per page to test the pagination
+	page := opts.Page
+	if page == 0 {
+		page = 1
+	}
+	res := &github.IssuesSearchResult{Total: github.Int(len(f.issues))}
+	if page <= len(f.issues) {
+		res.Issues = f.issues[page-1 : page]
+	}
+	resp := &github.Response{}
+	if page < len(f.issues) {
+		resp.NextPage = page + 1
+	}
+	return res, resp, nil
+}
+
+func (f *fakeClient) GetAuthenticatedUser(ctx context.Context) (*github.User, *github.Response, error) {
+	return &github.User{Login: github.String(f.login)}, &github.Response{}, nil
+}
+
+func (f *fakeClient) ListTeams(ctx context.Context, org string, opt *github.ListOptions) ([]*github.Team, *github.Response, error) {
+	// Serve one team per page to test the pagination
+	page := opt.Page
+	if page == 0 {
+		page = 1
+	}
+	resp := &github.Response{}
+	if page < len(f.teams) {
+		resp.NextPage = page + 1
+	}
+	if page > len(f.teams) {
+		return nil, resp, nil
+	}
+	return f.teams[page-1 : page], resp, nil
+}
+
+func (f *fakeClient) GetTeamMembership(ctx context.Context, team int64, user string) (*github.Membership, *github.Response, error) {
+	if f.members[team] != user {
+		return nil, &github.Response{Response: &http.Response{StatusCode: http.StatusNotFound}}, errors.New("not found")
+	}
+	return &github.Membership{State: github.String("active")}, &github.Response{}, nil
+}
+
+func TestUpdateRelease(t *testing.T) {
+	baseTmpDir, err := ioutil.TempDir("", "")
+	require.Nil(t, err)
+	defer os.RemoveAll(baseTmpDir)
+
+	tarball := filepath.Join(baseTmpDir, "kubernetes.tar.gz")
+	require.Nil(t, ioutil.WriteFile(tarball, []byte("test"), os.FileMode(0644)))
+	sums := filepath.Join(baseTmpDir, "SHA256SUMS")
+	require.Nil(t, ioutil.WriteFile(sums, []byte("sums"), os.FileMode(0644)))
+
+	type want struct {
+		created  bool
+		edited   bool
+		deleted  []int64
+		uploaded []string
+		err      bool
+	}
+	cases := map[string]struct {
+		client *fakeClient
+		want   want
+	}{
+		"CreateRelease": {
+			client: &fakeClient{},
+			want: want{
+				created:  true,
+				uploaded: []string{"kubernetes.tar.gz", "SHA256SUMS"},
+			},
+		},
+		"RetryCreate": {
+			client: &fakeClient{createErrs: 1},
+			want: want{
+				created:  true,
+				uploaded: []string{"kubernetes.tar.gz", "SHA256SUMS"},
+			},
+		},
+		"UpdateReleaseAndResume": {
+			client: &fakeClient{
+				releases: []*github.RepositoryRelease{
+					{ID: github.Int64(1), TagName: github.String("v1.17.0")},
+					{
+						ID:      github.Int64(2),
+						TagName: github.String("v1.18.0"),
+						Draft:   github.Bool(true),
+					},
+				},
+				assets: []*github.ReleaseAsset{
+					{
+						ID:    github.Int64(3),
+						Name:  github.String("kubernetes.tar.gz"),
+						Size:  github.Int(4),
+						State: github.String(assetStateUploaded),
+					},
+					{
+						ID:    github.Int64(4),
+						Name:  github.String("SHA256SUMS"),
+						Size:  github.Int(1),
+						State: github.String("starter"),
+					},
+				},
+			},
+			want: want{
+				edited:   true,
+				deleted:  []int64{4},
+				uploaded: []string{"SHA256SUMS"},
+			},
+		},
+		"RetryUpload": {
+			client: &fakeClient{uploadErrs: 1},
+			want: want{
+				created:  true,
+				uploaded: []string{"kubernetes.tar.gz", "SHA256SUMS"},
+			},
+		},
+		"RetryUploadPartialAsset": {
+			client: &fakeClient{uploadErrs: 1, partialAssets: true},
+			want: want{
+				created:  true,
+				deleted:  []int64{100},
+				uploaded: []string{"kubernetes.tar.gz", "SHA256SUMS"},
+			},
+		},
+		"UploadFailure": {
+			client: &fakeClient{uploadErrs: 2},
+			want: want{
+				created: true,
+				err:     true,
+			},
+		},
+	}
+
+	for name, tc := range cases {
+		t.Run(name, func(t *testing.T) {
+			_, err := UpdateRelease(context.Background(), tc.client, &ReleaseOptions{
+				Tag:            "v1.18.0",
+				Assets:         []string{tarball, sums},
+				UploadAttempts: 2,
+			})
+			require.Equal(t, tc.want.err, err != nil)
+			require.Equal(t, tc.want.created, tc.client.created)
+			require.Equal(t, tc.want.edited, tc.client.edited)
+			require.Equal(t, tc.want.deleted, tc.client.deletedAssets)
+			require.Equal(t, tc.want.uploaded, tc.client.uploaded)
+		})
+	}
+}
+
+func TestYankRelease(t *testing.T) {
+	cases := map[string]struct {
+		client *fakeClient
+		edited bool
+		err    bool
+	}{
+		"Success": {
+			client: &fakeClient{releases: []*github.RepositoryRelease{{
+				ID:      github.Int64(1),
+				TagName: github.String("v1.18.0"),
+			}}},
+			edited: true,
+		},
+		"AlreadyPrerelease": {
+			client: &fakeClient{releases: []*github.RepositoryRelease{{
+				ID:         github.Int64(1),
+				TagName:    github.String("v1.18.0"),
+				Prerelease: github.Bool(true),
+			}}},
+		},
+		"NotExisting": {
+			client: &fakeClient{releases: []*github.RepositoryRelease{{
+				ID:      github.Int64(1),
+				TagName: github.String("v1.17.0"),
+			}}},
+			err: true,
+		},
+	}
+
+	for name, tc := range cases {
+		t.Run(name, func(t *testing.T) {
+			release, err := YankRelease(
+				context.Background(), tc.client, "kubernetes", "kubernetes", "v1.18.0",
+			)
+			require.Equal(t, tc.err, err != nil)
+			require.Equal(t, tc.edited, tc.client.edited)
+			if !tc.err {
+				require.True(t, release.GetPrerelease())
+			}
+		})
+	}
+}
@@ -0,0 +1,85 @@
+/*
+Copyright 2020 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package github
+
+import (
+	"context"
+	"time"
+
+	"github.com/google/go-github/v29/github"
+	"github.com/sirupsen/logrus"
+
+	"k8s.io/release/pkg/util"
+)
+
+// defaultRateLimitDelay is the time to wait before retrying a rate limited
+// call, if the error does not tell how long to wait
+const defaultRateLimitDelay = time.Minute
+
+// RetryOptions returns the options of a util.Retrier for GitHub API calls,
+// which retries only the rate limit errors of GitHub. The retries wait for
+// the time GitHub told to wait or until the rate limit gets reset.
+func RetryOptions(ctx context.Context) *util.RetryOptions {
+	opts := util.DefaultRetryOptions()
+	opts.Context = ctx
+	opts.Retryable = IsRateLimitError
+	opts.Delay = RateLimitDelay
+	return opts
+}
+
+// IsRateLimitError returns true if `err` is a primary or abuse rate limit
+// error of GitHub
+func IsRateLimitError(err error) bool {
+	switch err.(type) {
+	case *github.RateLimitError, *github.AbuseRateLimitError:
+		return true
+	}
+	return false
+}
+
+// RateLimitDelay returns the time to wait before retrying a call which
+// failed with a rate limit error. For abuse rate limit errors it is the
+// time GitHub told to wait, for primary rate limit errors the time until
+// the rate limit gets reset.
+func RateLimitDelay(err error) (time.Duration, bool) {
+	if aerr, ok := err.(*github.AbuseRateLimitError); ok {
+		waitDuration := defaultRateLimitDelay
+		if d := aerr.RetryAfter; d != nil {
+			waitDuration = *d
+		}
+		logrus.
+			WithField("err", aerr).
+			Infof("Hit the abuse rate limit, sleeping for %s", waitDuration)
+		return waitDuration, true
+	}
+
+	if rerr, ok := err.(*github.RateLimitError); ok {
+		waitDuration := defaultRateLimitDelay
+		if reset := rerr.Rate.Reset; !reset.IsZero() {
+			waitDuration = time.Until(reset.Time)
+			if waitDuration < 0 {
+				waitDuration = 0
+			}
+		}
+		logrus.
+			WithField("err", rerr).
+			Infof("Hit the rate limit, sleeping for %s", waitDuration)
+		return waitDuration, true
+	}
+
+	return 0, false
+}
@@ -0,0 +1,74 @@
+/*
+Copyright 2020 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package github
+
+import (
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/google/go-github/v29/github"
+	"github.com/stretchr/testify/require"
+)
+
+func TestRateLimitDelay(t *testing.T) {
+	retryAfter := 42 * time.Minute
+	for name, tc := range map[string]struct {
+		err       error
+		expected  time.Duration
+		retryable bool
+	}{
+		"abuse rate limit with retry after": {
+			err:       &github.AbuseRateLimitError{RetryAfter: &retryAfter},
+			expected:  retryAfter,
+			retryable: true,
+		},
+		"abuse rate limit without retry after": {
+			err:       &github.AbuseRateLimitError{},
+			expected:  defaultRateLimitDelay,
+			retryable: true,
+		},
+		"rate limit without reset": {
+			err:       &github.RateLimitError{},
+			expected:  defaultRateLimitDelay,
+			retryable: true,
+		},
+		"rate limit already reset": {
+			err: &github.RateLimitError{
+				Rate: github.Rate{Reset: github.Timestamp{Time: time.Now().Add(-time.Hour)}},
+			},
+			retryable: true,
+		},
+		"other error": {
+			err: errors.New("other"),
+		},
+	} {
+		t.Run(name, func(t *testing.T) {
+			delay, ok := RateLimitDelay(tc.err)
+			require.Equal(t, tc.retryable, ok)
+			require.Equal(t, tc.retryable, IsRateLimitError(tc.err))
+			require.Equal(t, tc.expected, delay)
+		})
+	}
+}
+
+func TestRetryOptions(t *testing.T) {
+	opts := RetryOptions(nil)
+	opts.Sleeper = func(time.Duration) {}
+	require.False(t, opts.Retryable(errors.New("other")))
+	require.True(t, opts.Retryable(&github.RateLimitError{}))
+}
@@ -0,0 +1,84 @@
+/*
+Copyright 2020 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package github
+
+import (
+	"context"
+	"sort"
+	"strings"
+
+	"github.com/google/go-github/v29/github"
+	"github.com/pkg/errors"
+)
+
+const (
+	reviewsPerPage = 100
+
+	reviewApproved         = "APPROVED"
+	reviewChangesRequested = "CHANGES_REQUESTED"
+	reviewDismissed        = "DISMISSED"
+)
+
+// PullRequestApprovers returns the sorted logins of all reviewers whose
+// latest review of the PR `number` is an approval. Comments do not change
+// the decision of a reviewer, but requested changes and dismissals revoke
+// a previous approval. The PR has to mention `reference` in its title or
+// description, which binds the approvals to it, and the approval of its
+// author is never counted.
+func PullRequestApprovers(
+	ctx context.Context, client Client, owner, repo string, number int, reference string,
+) ([]string, error) {
+	pr, _, err := client.GetPullRequest(ctx, owner, repo, number)
+	if err != nil {
+		return nil, errors.Wrapf(err, "getting PR #%d", number)
+	}
+	if !strings.Contains(pr.GetTitle(), reference) && !strings.Contains(pr.GetBody(), reference) {
+		return nil, errors.Errorf("PR #%d does not mention %q", number, reference)
+	}
+	author := pr.GetUser().GetLogin()
+
+	approved := map[string]bool{}
+	opts := &github.ListOptions{PerPage: reviewsPerPage}
+	for {
+		reviews, resp, err := client.ListReviews(ctx, owner, repo, number, opts)
+		if err != nil {
+			return nil, errors.Wrapf(err, "listing the reviews of PR #%d", number)
+		}
+		for _, review := range reviews {
+			login := review.GetUser().GetLogin()
+			switch review.GetState() {
+			case reviewApproved:
+				approved[login] = true
+			case reviewChangesRequested, reviewDismissed:
+				approved[login] = false
+			}
+		}
+		if resp.NextPage == 0 {
+			break
+		}
+		opts.Page = resp.NextPage
+	}
+
+	approvers := []string{}
+	for login, ok := range approved {
+		if ok && login != author {
+			approvers = append(approvers, login)
+		}
+	}
+	sort.Strings(approvers)
+	return approvers, nil
+}
@@ -0,0 +1,106 @@
+/*
+Copyright 2020 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package github
+
+import (
+	"context"
+	"testing"
+
+	"github.com/google/go-github/v29/github"
+	"github.com/stretchr/testify/require"
+)
+
+func review(login, state string) *github.PullRequestReview {
+	return &github.PullRequestReview{
+		User:  &github.User{Login: github.String(login)},
+		State: github.String(state),
+	}
+}
+
+func TestPullRequestApprovers(t *testing.T) {
+	const reference = "push v1.18.0 to gs://kubernetes-release/release/v1.18.0"
+	pr := &github.PullRequest{
+		Title: github.String("Release v1.18.0"),
+		Body:  github.String("Approves " + reference),
+		User:  &github.User{Login: github.String("author")},
+	}
+
+	for name, tc := range map[string]struct {
+		pr          *github.PullRequest
+		reviews     []*github.PullRequestReview
+		expected    []string
+		shouldError bool
+	}{
+		"success approvals": {
+			pr: pr,
+			reviews: []*github.PullRequestReview{
+				review("second", reviewApproved),
+				review("first", reviewApproved),
+				review("first", "COMMENTED"),
+			},
+			expected: []string{"first", "second"},
+		},
+		"success revoked approvals": {
+			pr: pr,
+			reviews: []*github.PullRequestReview{
+				review("first", reviewApproved),
+				review("first", reviewChangesRequested),
+				review("second", reviewApproved),
+				review("second", reviewDismissed),
+				review("third", reviewChangesRequested),
+				review("third", reviewApproved),
+			},
+			expected: []string{"third"},
+		},
+		"success no reviews": {
+			pr:       pr,
+			expected: []string{},
+		},
+		"success author approval ignored": {
+			pr: pr,
+			reviews: []*github.PullRequestReview{
+				review("author", reviewApproved),
+				review("first", reviewApproved),
+			},
+			expected: []string{"first"},
+		},
+		"failure reference missing": {
+			pr: &github.PullRequest{
+				Title: github.String("Release v1.17.0"),
+				Body:  github.String("Approves push v1.17.0 to gs://kubernetes-release/release/v1.17.0"),
+			},
+			reviews:     []*github.PullRequestReview{review("first", reviewApproved)},
+			shouldError: true,
+		},
+		"failure PR not found": {
+			shouldError: true,
+		},
+	} {
+		t.Run(name, func(t *testing.T) {
+			res, err := PullRequestApprovers(
+				context.Background(), &fakeClient{pr: tc.pr, reviews: tc.reviews},
+				"owner", "repo", 1, reference,
+			)
+			if tc.shouldError {
+				require.NotNil(t, err)
+			} else {
+				require.Nil(t, err)
+				require.Equal(t, tc.expected, res)
+			}
+		})
+	}
+}
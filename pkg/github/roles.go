@@ -0,0 +1,124 @@
+/*
+Copyright 2020 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package github
+
+import (
+	"context"
+	"net/http"
+	"strings"
+
+	"github.com/google/go-github/v29/github"
+	"github.com/pkg/errors"
+	"github.com/sirupsen/logrus"
+)
+
+const (
+	// RoleReleaseManager is allowed to publish, roll back and clean up
+	// releases and their images
+	RoleReleaseManager = "release-manager"
+
+	// RoleBranchManager is allowed to create and fast forward release
+	// branches and to open cherry picks against them
+	RoleBranchManager = "branch-manager"
+
+	// RoleComms is allowed to send release announcements
+	RoleComms = "comms"
+
+	teamsPerPage = 100
+)
+
+// Roles maps the release personas to the GitHub teams holding them. A team
+// is either specified by its slug within the default organization, like
+// release-managers, or together with its organization, like
+// kubernetes/release-managers.
+type Roles map[string][]string
+
+// DefaultRoles are the GitHub teams of the kubernetes organization holding
+// the release personas. They are compiled in, because a mapping supplied
+// by the caller would let anybody grant a role to their own team.
+var DefaultRoles = Roles{
+	RoleReleaseManager: {"kubernetes/release-managers"},
+	RoleBranchManager:  {"kubernetes/release-managers"},
+	RoleComms:          {"kubernetes/release-managers", "kubernetes/release-team"},
+}
+
+// RequireRole fails if the user authenticated by the client is not an active
+// member of at least one of the teams holding `role`. Teams without an
+// organization are looked up in `org`.
+func RequireRole(ctx context.Context, client Client, roles Roles, org, role string) error {
+	teams := roles[role]
+	if len(teams) == 0 {
+		return errors.Errorf("no GitHub teams hold the role %s", role)
+	}
+
+	user, _, err := client.GetAuthenticatedUser(ctx)
+	if err != nil {
+		return errors.Wrap(err, "getting the authenticated GitHub user")
+	}
+	login := user.GetLogin()
+
+	for _, team := range teams {
+		teamOrg, slug := org, team
+		if parts := strings.SplitN(team, "/", 2); len(parts) == 2 {
+			teamOrg, slug = parts[0], parts[1]
+		}
+
+		member, err := isTeamMember(ctx, client, teamOrg, slug, login)
+		if err != nil {
+			return err
+		}
+		if member {
+			logrus.Infof(
+				"GitHub user %s holds the role %s as member of %s/%s",
+				login, role, teamOrg, slug,
+			)
+			return nil
+		}
+	}
+	return errors.Errorf(
+		"GitHub user %s does not hold the role %s, which requires the membership "+
+			"in one of the teams %s", login, role, strings.Join(teams, ", "),
+	)
+}
+
+func isTeamMember(ctx context.Context, client Client, org, slug, login string) (bool, error) {
+	opts := &github.ListOptions{PerPage: teamsPerPage}
+	for {
+		teams, resp, err := client.ListTeams(ctx, org, opts)
+		if err != nil {
+			return false, errors.Wrapf(err, "listing teams of %s", org)
+		}
+		for _, team := range teams {
+			if team.GetSlug() != slug {
+				continue
+			}
+			membership, resp, err := client.GetTeamMembership(ctx, team.GetID(), login)
+			if resp != nil && resp.Response != nil && resp.StatusCode == http.StatusNotFound {
+				return false, nil
+			}
+			if err != nil {
+				return false, errors.Wrapf(err, "getting the membership of %s in %s/%s", login, org, slug)
+			}
+			return membership.GetState() == "active", nil
+		}
+		if resp.NextPage == 0 {
+			break
+		}
+		opts.Page = resp.NextPage
+	}
+	return false, errors.Errorf("team %s/%s does not exist", org, slug)
+}
@@ -0,0 +1,86 @@
+/*
+Copyright 2020 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package github
+
+import (
+	"context"
+	"testing"
+
+	"github.com/google/go-github/v29/github"
+	"github.com/stretchr/testify/require"
+)
+
+func TestDefaultRoles(t *testing.T) {
+	for _, role := range []string{RoleReleaseManager, RoleBranchManager, RoleComms} {
+		require.NotEmpty(t, DefaultRoles[role], role)
+	}
+}
+
+func TestRequireRole(t *testing.T) {
+	roles := Roles{
+		RoleReleaseManager: {"release-managers"},
+		RoleComms:          {"release-managers", "other/comms"},
+		RoleBranchManager:  {"not-existing"},
+	}
+	client := &fakeClient{
+		teams: []*github.Team{
+			{ID: github.Int64(1), Slug: github.String("release-managers")},
+			{ID: github.Int64(2), Slug: github.String("comms")},
+		},
+		members: map[int64]string{1: "manager", 2: "writer"},
+	}
+
+	for name, tc := range map[string]struct {
+		login       string
+		role        string
+		shouldError bool
+	}{
+		"success first team": {
+			login: "manager",
+			role:  RoleReleaseManager,
+		},
+		"success team of other organization": {
+			login: "writer",
+			role:  RoleComms,
+		},
+		"failure not a member": {
+			login:       "writer",
+			role:        RoleReleaseManager,
+			shouldError: true,
+		},
+		"failure team does not exist": {
+			login:       "manager",
+			role:        RoleBranchManager,
+			shouldError: true,
+		},
+		"failure role without teams": {
+			login:       "manager",
+			role:        "unknown",
+			shouldError: true,
+		},
+	} {
+		t.Run(name, func(t *testing.T) {
+			client.login = tc.login
+			err := RequireRole(context.Background(), client, roles, "kubernetes", tc.role)
+			if tc.shouldError {
+				require.NotNil(t, err)
+			} else {
+				require.Nil(t, err)
+			}
+		})
+	}
+}
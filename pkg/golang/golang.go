@@ -0,0 +1,264 @@
+/*
+Copyright 2020 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package golang
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"io"
+	"io/ioutil"
+	"net/http"
+	"os"
+	"path/filepath"
+	"runtime"
+	"strings"
+
+	"github.com/pkg/errors"
+	"github.com/sirupsen/logrus"
+
+	"k8s.io/release/pkg/command"
+)
+
+const (
+	// DefaultDownloadURL is the location of the official Go releases and
+	// their checksums
+	DefaultDownloadURL = "https://golang.org/dl/"
+
+	// KubeCrossVersionFile is the path of the file pinning the kube-cross
+	// image within the Kubernetes repository. The image determines the Go
+	// version of the release builds.
+	KubeCrossVersionFile = "build/build-image/cross/VERSION"
+)
+
+// File is a single downloadable file of a Go release
+type File struct {
+	Filename string `json:"filename"`
+	OS       string `json:"os"`
+	Arch     string `json:"arch"`
+	Version  string `json:"version"`
+	SHA256   string `json:"sha256"`
+	Kind     string `json:"kind"`
+}
+
+// Release is a Go release, as listed by the download page
+type Release struct {
+	Version string `json:"version"`
+	Files   []File `json:"files"`
+	Stable  bool   `json:"stable"`
+}
+
+// Toolchain is a pinned Go toolchain, which can be downloaded from the
+// download page and verified against its published checksum
+type Toolchain struct {
+	// Version is the exact Go version, like 1.13.6 or go1.13.6
+	Version string
+
+	// DownloadURL is the download page, which defaults to DefaultDownloadURL
+	DownloadURL string
+
+	// OS and Arch select the archive, they default to the host platform
+	OS   string
+	Arch string
+}
+
+// NewToolchain creates a new Toolchain for the Go `version` on the host
+// platform
+func NewToolchain(version string) *Toolchain {
+	return &Toolchain{
+		Version:     version,
+		DownloadURL: DefaultDownloadURL,
+		OS:          runtime.GOOS,
+		Arch:        runtime.GOARCH,
+	}
+}
+
+// NormalizeVersion returns the version with the go prefix, like go1.13.6
+func NormalizeVersion(version string) string {
+	return "go" + strings.TrimPrefix(strings.TrimSpace(version), "go")
+}
+
+// ParseVersionOutput returns the version of the output of `go version`, like
+// go1.13.6 for "go version go1.13.6 linux/amd64"
+func ParseVersionOutput(output string) (string, error) {
+	fields := strings.Fields(output)
+	if len(fields) < 3 || fields[0] != "go" || fields[1] != "version" {
+		return "", errors.Errorf("unexpected go version output: %q", output)
+	}
+	return fields[2], nil
+}
+
+// KubeCrossGoVersion returns the Go version of a kube-cross image version,
+// like 1.13.6 for v1.13.6-1
+func KubeCrossGoVersion(kubeCrossVersion string) (string, error) {
+	version := strings.TrimPrefix(strings.TrimSpace(kubeCrossVersion), "v")
+	if i := strings.LastIndex(version, "-"); i >= 0 {
+		version = version[:i]
+	}
+	if version == "" || strings.Count(version, ".") < 1 {
+		return "", errors.Errorf("invalid kube-cross version %q", kubeCrossVersion)
+	}
+	return version, nil
+}
+
+// PinnedVersion returns the Go version pinned by the kube-cross version of
+// the Kubernetes repository at `repoPath`
+func PinnedVersion(repoPath string) (string, error) {
+	path := filepath.Join(repoPath, KubeCrossVersionFile)
+	content, err := ioutil.ReadFile(path)
+	if err != nil {
+		return "", errors.Wrapf(err, "reading kube-cross version from %s", path)
+	}
+	return KubeCrossGoVersion(string(content))
+}
+
+// HostVersion returns the version of the go binary in $PATH
+func HostVersion() (string, error) {
+	output, err := command.New("go", "version").RunSilentSuccessOutput()
+	if err != nil {
+		return "", errors.Wrap(err, "retrieving host go version")
+	}
+	return ParseVersionOutput(output.Output())
+}
+
+// VerifyHost fails if the go binary in $PATH is not the pinned version
+func (t *Toolchain) VerifyHost() error {
+	version, err := HostVersion()
+	if err != nil {
+		return err
+	}
+	if expected := NormalizeVersion(t.Version); version != expected {
+		return errors.Errorf(
+			"host Go toolchain %s differs from the pinned version %s",
+			version, expected,
+		)
+	}
+	return nil
+}
+
+// Archive returns the archive of the toolchain for its platform, as listed
+// on the download page
+func (t *Toolchain) Archive() (*File, error) {
+	url := strings.TrimSuffix(t.DownloadURL, "/") + "/?mode=json&include=all"
+	logrus.Infof("Retrieving Go releases from %s", url)
+
+	resp, err := http.Get(url)
+	if err != nil {
+		return nil, errors.Wrapf(err, "an error occurred GET-ing %s", url)
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK {
+		return nil, errors.Errorf("retrieving Go releases: %s", resp.Status)
+	}
+
+	releases := []Release{}
+	if err := json.NewDecoder(resp.Body).Decode(&releases); err != nil {
+		return nil, errors.Wrap(err, "decoding Go releases")
+	}
+
+	version := NormalizeVersion(t.Version)
+	for _, release := range releases {
+		if release.Version != version {
+			continue
+		}
+		for i := range release.Files {
+			file := &release.Files[i]
+			if file.Kind == "archive" && file.OS == t.OS && file.Arch == t.Arch {
+				return file, nil
+			}
+		}
+		return nil, errors.Errorf(
+			"no archive of %s found for %s/%s", version, t.OS, t.Arch,
+		)
+	}
+	return nil, errors.Errorf("Go release %s not found", version)
+}
+
+// Download retrieves the archive of the toolchain into `dir` and verifies its
+// checksum. The path to the verified archive is returned.
+func (t *Toolchain) Download(dir string) (string, error) {
+	archive, err := t.Archive()
+	if err != nil {
+		return "", err
+	}
+
+	url := strings.TrimSuffix(t.DownloadURL, "/") + "/" + archive.Filename
+	path := filepath.Join(dir, archive.Filename)
+	logrus.Infof("Downloading %s to %s", url, path)
+
+	resp, err := http.Get(url)
+	if err != nil {
+		return "", errors.Wrapf(err, "an error occurred GET-ing %s", url)
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK {
+		return "", errors.Errorf("downloading %s: %s", archive.Filename, resp.Status)
+	}
+
+	f, err := os.Create(path)
+	if err != nil {
+		return "", errors.Wrapf(err, "creating %s", path)
+	}
+
+	h := sha256.New()
+	_, err = io.Copy(io.MultiWriter(f, h), resp.Body)
+	if closeErr := f.Close(); err == nil {
+		err = closeErr
+	}
+	if err != nil {
+		os.Remove(path) // nolint: errcheck
+		return "", errors.Wrapf(err, "writing %s", path)
+	}
+
+	if hash := hex.EncodeToString(h.Sum(nil)); hash != archive.SHA256 {
+		os.Remove(path) // nolint: errcheck
+		return "", errors.Errorf(
+			"checksum mismatch of %s: expected %s, got %s",
+			archive.Filename, archive.SHA256, hash,
+		)
+	}
+	logrus.Infof("Verified SHA256 checksum %s", archive.SHA256)
+	return path, nil
+}
+
+// Install downloads and verifies the toolchain and extracts it into `dir`.
+// The GOROOT of the installed toolchain is returned.
+func (t *Toolchain) Install(dir string) (string, error) {
+	path, err := t.Download(dir)
+	if err != nil {
+		return "", err
+	}
+
+	goroot := filepath.Join(dir, "go")
+	if err := os.RemoveAll(goroot); err != nil {
+		return "", errors.Wrapf(err, "removing previous toolchain %s", goroot)
+	}
+
+	logrus.Infof("Extracting %s", path)
+	if err := command.New("tar", "-C", dir, "-xzf", path).RunSilentSuccess(); err != nil {
+		return "", errors.Wrapf(err, "extracting %s", path)
+	}
+	return goroot, nil
+}
+
+// String returns the normalized version and platform of the toolchain
+func (t *Toolchain) String() string {
+	return fmt.Sprintf("%s %s/%s", NormalizeVersion(t.Version), t.OS, t.Arch)
+}
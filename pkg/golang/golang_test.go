@@ -0,0 +1,213 @@
+/*
+Copyright 2020 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package golang
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"fmt"
+	"io/ioutil"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestNormalizeVersion(t *testing.T) {
+	require.Equal(t, "go1.13.6", NormalizeVersion("1.13.6"))
+	require.Equal(t, "go1.13.6", NormalizeVersion("go1.13.6"))
+	require.Equal(t, "go1.14", NormalizeVersion(" 1.14\n"))
+}
+
+func TestParseVersionOutput(t *testing.T) {
+	for name, tc := range map[string]struct {
+		output      string
+		expected    string
+		shouldError bool
+	}{
+		"success": {
+			output:   "go version go1.13.6 linux/amd64\n",
+			expected: "go1.13.6",
+		},
+		"failure empty": {
+			output:      "",
+			shouldError: true,
+		},
+		"failure unexpected output": {
+			output:      "command not found: go",
+			shouldError: true,
+		},
+	} {
+		t.Run(name, func(t *testing.T) {
+			res, err := ParseVersionOutput(tc.output)
+			if tc.shouldError {
+				require.NotNil(t, err)
+			} else {
+				require.Nil(t, err)
+				require.Equal(t, tc.expected, res)
+			}
+		})
+	}
+}
+
+func newTestServer(archive []byte, checksum string, truncated bool) *httptest.Server {
+	return httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		switch r.URL.Path {
+		case "/":
+			fmt.Fprintf(w, `[{
+				"version": "go1.13.6",
+				"stable": true,
+				"files": [
+					{"filename": "go1.13.6.src.tar.gz", "os": "", "arch": "", "kind": "source", "sha256": "abc"},
+					{"filename": "go1.13.6.linux-amd64.tar.gz", "os": "linux", "arch": "amd64", "kind": "archive", "sha256": %q}
+				]
+			}]`, checksum)
+		case "/go1.13.6.linux-amd64.tar.gz":
+			if truncated {
+				w.Header().Set("Content-Length", fmt.Sprint(len(archive)+10))
+			}
+			w.Write(archive) // nolint: errcheck
+		default:
+			w.WriteHeader(http.StatusNotFound)
+		}
+	}))
+}
+
+func TestKubeCrossGoVersion(t *testing.T) {
+	for name, tc := range map[string]struct {
+		kubeCrossVersion string
+		expected         string
+		shouldError      bool
+	}{
+		"success": {
+			kubeCrossVersion: "v1.13.6-1\n",
+			expected:         "1.13.6",
+		},
+		"success without revision": {
+			kubeCrossVersion: "v1.14.4",
+			expected:         "1.14.4",
+		},
+		"failure empty": {
+			kubeCrossVersion: "",
+			shouldError:      true,
+		},
+		"failure invalid": {
+			kubeCrossVersion: "latest",
+			shouldError:      true,
+		},
+	} {
+		t.Run(name, func(t *testing.T) {
+			res, err := KubeCrossGoVersion(tc.kubeCrossVersion)
+			if tc.shouldError {
+				require.NotNil(t, err)
+			} else {
+				require.Nil(t, err)
+				require.Equal(t, tc.expected, res)
+			}
+		})
+	}
+}
+
+func TestPinnedVersion(t *testing.T) {
+	dir, err := ioutil.TempDir("", "golang-test-")
+	require.Nil(t, err)
+	defer os.RemoveAll(dir)
+
+	_, err = PinnedVersion(dir)
+	require.NotNil(t, err)
+
+	path := filepath.Join(dir, KubeCrossVersionFile)
+	require.Nil(t, os.MkdirAll(filepath.Dir(path), os.FileMode(0755)))
+	require.Nil(t, ioutil.WriteFile(path, []byte("v1.13.6-1\n"), os.FileMode(0644)))
+
+	version, err := PinnedVersion(dir)
+	require.Nil(t, err)
+	require.Equal(t, "1.13.6", version)
+}
+
+func TestDownload(t *testing.T) {
+	archive := []byte("archive content")
+	sum := sha256.Sum256(archive)
+
+	for name, tc := range map[string]struct {
+		version     string
+		arch        string
+		checksum    string
+		truncated   bool
+		shouldError bool
+	}{
+		"success": {
+			version:  "1.13.6",
+			arch:     "amd64",
+			checksum: hex.EncodeToString(sum[:]),
+		},
+		"failure checksum mismatch": {
+			version:     "1.13.6",
+			arch:        "amd64",
+			checksum:    "wrong",
+			shouldError: true,
+		},
+		"failure truncated download": {
+			version:     "1.13.6",
+			arch:        "amd64",
+			checksum:    hex.EncodeToString(sum[:]),
+			truncated:   true,
+			shouldError: true,
+		},
+		"failure unknown version": {
+			version:     "1.12.0",
+			arch:        "amd64",
+			checksum:    hex.EncodeToString(sum[:]),
+			shouldError: true,
+		},
+		"failure unknown platform": {
+			version:     "1.13.6",
+			arch:        "arm64",
+			checksum:    hex.EncodeToString(sum[:]),
+			shouldError: true,
+		},
+	} {
+		t.Run(name, func(t *testing.T) {
+			server := newTestServer(archive, tc.checksum, tc.truncated)
+			defer server.Close()
+
+			dir, err := ioutil.TempDir("", "golang-test-")
+			require.Nil(t, err)
+			defer os.RemoveAll(dir)
+
+			toolchain := NewToolchain(tc.version)
+			toolchain.DownloadURL = server.URL
+			toolchain.OS = "linux"
+			toolchain.Arch = tc.arch
+
+			path, err := toolchain.Download(dir)
+			if tc.shouldError {
+				require.NotNil(t, err)
+				_, err := os.Stat(filepath.Join(dir, "go1.13.6.linux-amd64.tar.gz"))
+				require.True(t, os.IsNotExist(err))
+			} else {
+				require.Nil(t, err)
+				content, err := ioutil.ReadFile(path)
+				require.Nil(t, err)
+				require.Equal(t, archive, content)
+			}
+		})
+	}
+}
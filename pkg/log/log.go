@@ -20,12 +20,19 @@ import (
 	"io/ioutil"
 	"strings"
 
+	"github.com/pkg/errors"
 	"github.com/sirupsen/logrus"
 )
 
 const (
 	logTraceKey = "trace"
 	logTraceSep = "."
+
+	// FormatText is the default, human readable log format
+	FormatText = "text"
+
+	// FormatJSON logs every entry as single JSON object
+	FormatJSON = "json"
 )
 
 func SetupGlobalLogger(level string) error {
@@ -40,6 +47,23 @@ func SetupGlobalLogger(level string) error {
 	return nil
 }
 
+// SetFormat changes the format of the global logger to either FormatText or
+// FormatJSON.
+func SetFormat(format string) error {
+	switch format {
+	case FormatText:
+		logrus.SetFormatter(&logrus.TextFormatter{DisableTimestamp: true})
+	case FormatJSON:
+		logrus.SetFormatter(&logrus.JSONFormatter{})
+	default:
+		return errors.Errorf(
+			"unsupported log format %q, either %q or %q",
+			format, FormatText, FormatJSON,
+		)
+	}
+	return nil
+}
+
 // AddTracePath adds a path element to the logrus entry's field 'trace'. This
 // is meant to be done everytime you hand off a logger/entry to a different
 // component to have a clear trace how we ended up here. When logs are emitted
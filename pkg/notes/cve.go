@@ -0,0 +1,167 @@
+/*
+Copyright 2020 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package notes
+
+import (
+	"fmt"
+	"io/ioutil"
+	"regexp"
+	"strings"
+
+	"github.com/pkg/errors"
+	"sigs.k8s.io/yaml"
+)
+
+// cveIDRegex matches valid CVE identifiers like `CVE-2020-8555`
+var cveIDRegex = regexp.MustCompile(`^CVE-\d{4}-\d{4,}$`)
+
+// CVE is a vulnerability fixed in a release, as declared by the release
+// managers in a YAML datafile, for example:
+//
+//	# cves.yaml
+//	- id: CVE-2020-8555
+//	  title: Half-Blind SSRF in kube-controller-manager
+//	  description: A security issue was discovered in ...
+//	  cvss_score: 6.3
+//	  cvss_vector: CVSS:3.0/AV:N/AC:H/PR:L/UI:N/S:C/C:H/I:N/A:N
+//	  linked_prs:
+//	  - 89794
+type CVE struct {
+	ID          string  `json:"id"`
+	Title       string  `json:"title"`
+	Description string  `json:"description"`
+	CVSSVector  string  `json:"cvss_vector,omitempty"`
+	LinkedPRs   []int   `json:"linked_prs"`
+	CVSSScore   float64 `json:"cvss_score"`
+}
+
+// ParseCVEs reads and validates the CVEs from the provided YAML file
+func ParseCVEs(path string) ([]CVE, error) {
+	content, err := ioutil.ReadFile(path)
+	if err != nil {
+		return nil, errors.Wrapf(err, "reading CVE file %s", path)
+	}
+
+	cves := []CVE{}
+	if err := yaml.UnmarshalStrict(content, &cves); err != nil {
+		return nil, errors.Wrapf(err, "parsing CVE file %s", path)
+	}
+
+	seen := map[string]bool{}
+	for i := range cves {
+		if err := cves[i].Validate(); err != nil {
+			return nil, errors.Wrapf(err, "validating CVE file %s", path)
+		}
+		if seen[cves[i].ID] {
+			return nil, errors.Errorf(
+				"validating CVE file %s: %s is listed more than once",
+				path, cves[i].ID,
+			)
+		}
+		seen[cves[i].ID] = true
+	}
+
+	return cves, nil
+}
+
+// Validate checks if all fields of the CVE are set and well formed
+func (c *CVE) Validate() error {
+	if !cveIDRegex.MatchString(c.ID) {
+		return errors.Errorf("invalid CVE ID %q", c.ID)
+	}
+	if strings.TrimSpace(c.Title) == "" {
+		return errors.Errorf("%s has no title", c.ID)
+	}
+	if strings.TrimSpace(c.Description) == "" {
+		return errors.Errorf("%s has no description", c.ID)
+	}
+	if c.CVSSScore < 0 || c.CVSSScore > 10 {
+		return errors.Errorf(
+			"%s has an invalid CVSS score %.1f, must be between 0 and 10",
+			c.ID, c.CVSSScore,
+		)
+	}
+	if len(c.LinkedPRs) == 0 {
+		return errors.Errorf("%s has no linked PRs", c.ID)
+	}
+	for _, pr := range c.LinkedPRs {
+		if pr <= 0 {
+			return errors.Errorf("%s has an invalid linked PR %d", c.ID, pr)
+		}
+	}
+	return nil
+}
+
+// CVSSRating returns the qualitative severity rating of the CVSS score, as
+// defined by the CVSS v3 specification.
+func (c *CVE) CVSSRating() string {
+	switch {
+	case c.CVSSScore >= 9:
+		return "Critical"
+	case c.CVSSScore >= 7:
+		return "High"
+	case c.CVSSScore >= 4:
+		return "Medium"
+	case c.CVSSScore > 0:
+		return "Low"
+	default:
+		return "None"
+	}
+}
+
+// Markdown renders the CVE as a markdown section
+func (c *CVE) Markdown() string {
+	prs := []string{}
+	for _, pr := range c.LinkedPRs {
+		prs = append(prs, fmt.Sprintf("#%d", pr))
+	}
+
+	o := &strings.Builder{}
+	fmt.Fprintf(o, "### %s: %s\n\n", c.ID, c.Title)
+	fmt.Fprintf(o, "%s\n\n", strings.TrimSpace(c.Description))
+	fmt.Fprintf(o, "**CVSS Rating:** %s (%.1f)", c.CVSSRating(), c.CVSSScore)
+	if c.CVSSVector != "" {
+		fmt.Fprintf(o, " %s", c.CVSSVector)
+	}
+	fmt.Fprintf(o, "\n\n**Fixed by:** %s\n", strings.Join(prs, ", "))
+	return o.String()
+}
+
+// CVEsMarkdown renders the CVEs as a markdown section, like it is used in the
+// release notes and the patch release announcement
+func CVEsMarkdown(cves []CVE) string {
+	o := &strings.Builder{}
+	o.WriteString("## Changes by CVE\n\n")
+	for i := range cves {
+		o.WriteString(cves[i].Markdown())
+		if i < len(cves)-1 {
+			o.WriteString("\n")
+		}
+	}
+	return o.String()
+}
+
+// AddCVEs adds the CVEs to the release notes of the PRs which fixed them
+func (r ReleaseNotes) AddCVEs(cves []CVE) {
+	for _, cve := range cves {
+		for _, pr := range cve.LinkedPRs {
+			if note, ok := r[pr]; ok {
+				note.CVEs = append(note.CVEs, cve)
+			}
+		}
+	}
+}
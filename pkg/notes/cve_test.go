@@ -0,0 +1,144 @@
+/*
+Copyright 2020 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package notes
+
+import (
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+const testCVE = `- id: CVE-2020-8555
+  title: Half-Blind SSRF in kube-controller-manager
+  description: Some volume types allow a leak of up to 500 bytes.
+  cvss_score: 6.3
+  cvss_vector: CVSS:3.0/AV:N/AC:H/PR:L/UI:N/S:C/C:H/I:N/A:N
+  linked_prs:
+  - 89794
+  - 89796
+`
+
+func TestParseCVEs(t *testing.T) {
+	cases := map[string]struct {
+		content string
+		ids     []string
+		wantErr bool
+	}{
+		"Success": {
+			content: testCVE,
+			ids:     []string{"CVE-2020-8555"},
+		},
+		"Empty": {
+			content: "[]",
+			ids:     []string{},
+		},
+		"Duplicate": {
+			content: testCVE + testCVE,
+			wantErr: true,
+		},
+		"InvalidID": {
+			content: "- id: CVE-20-1\n  title: t\n  description: d\n  linked_prs: [1]\n",
+			wantErr: true,
+		},
+		"InvalidScore": {
+			content: "- id: CVE-2020-1234\n  title: t\n  description: d\n  cvss_score: 11\n  linked_prs: [1]\n",
+			wantErr: true,
+		},
+		"NoLinkedPRs": {
+			content: "- id: CVE-2020-1234\n  title: t\n  description: d\n",
+			wantErr: true,
+		},
+		"UnknownField": {
+			content: "- id: CVE-2020-1234\n  titel: typo\n",
+			wantErr: true,
+		},
+	}
+
+	for name, tc := range cases {
+		t.Run(name, func(t *testing.T) {
+			dir, err := ioutil.TempDir("", "cve-")
+			require.Nil(t, err)
+			defer os.RemoveAll(dir)
+
+			path := filepath.Join(dir, "cves.yaml")
+			require.Nil(t, ioutil.WriteFile(path, []byte(tc.content), os.FileMode(0644)))
+
+			cves, err := ParseCVEs(path)
+			if tc.wantErr {
+				require.NotNil(t, err)
+				return
+			}
+			require.Nil(t, err)
+			ids := []string{}
+			for _, cve := range cves {
+				ids = append(ids, cve.ID)
+			}
+			require.Equal(t, tc.ids, ids)
+		})
+	}
+}
+
+func TestCVSSRating(t *testing.T) {
+	for score, rating := range map[float64]string{
+		0:   "None",
+		3.9: "Low",
+		4:   "Medium",
+		7.5: "High",
+		10:  "Critical",
+	} {
+		cve := &CVE{CVSSScore: score}
+		require.Equal(t, rating, cve.CVSSRating())
+	}
+}
+
+func TestRenderMarkdownCVEs(t *testing.T) {
+	doc := &Document{
+		CVEs: []CVE{{
+			ID:          "CVE-2020-8555",
+			Title:       "Half-Blind SSRF in kube-controller-manager",
+			Description: "Some volume types allow a leak of up to 500 bytes.\n",
+			CVSSScore:   6.3,
+			LinkedPRs:   []int{89794, 89796},
+		}},
+		Kinds: map[string][]string{},
+	}
+
+	markdown, err := doc.RenderMarkdown("", "", "", "")
+	require.Nil(t, err)
+	require.Equal(t, "## Changes by CVE\n\n"+
+		"### CVE-2020-8555: Half-Blind SSRF in kube-controller-manager\n\n"+
+		"Some volume types allow a leak of up to 500 bytes.\n\n"+
+		"**CVSS Rating:** Medium (6.3)\n\n"+
+		"**Fixed by:** #89794, #89796",
+		markdown,
+	)
+}
+
+func TestAddCVEs(t *testing.T) {
+	cve := CVE{ID: "CVE-2020-8555", LinkedPRs: []int{1, 3}}
+	notes := ReleaseNotes{
+		1: &ReleaseNote{PrNumber: 1},
+		2: &ReleaseNote{PrNumber: 2},
+	}
+
+	notes.AddCVEs([]CVE{cve})
+	require.Equal(t, []CVE{cve}, notes[1].CVEs)
+	require.Empty(t, notes[2].CVEs)
+}
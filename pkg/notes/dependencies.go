@@ -0,0 +1,252 @@
+/*
+Copyright 2020 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package notes
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+
+	"github.com/pkg/errors"
+
+	"k8s.io/release/pkg/git"
+)
+
+// Dependency is a Go module required by a release
+type Dependency struct {
+	Name    string `json:"name"`
+	Version string `json:"version"`
+}
+
+// DependencyUpdate is a Go module whose required version changed between two
+// releases
+type DependencyUpdate struct {
+	Name string `json:"name"`
+	From string `json:"from"`
+	To   string `json:"to"`
+}
+
+// DependencyChanges are the differences between the Go module requirements
+// of two releases
+type DependencyChanges struct {
+	Added   []Dependency       `json:"added"`
+	Updated []DependencyUpdate `json:"updated"`
+	Removed []Dependency       `json:"removed"`
+}
+
+// ParseGoModRequires returns the required module versions of the go.mod
+// file content, mapped by the module path. The `replace` directives are
+// applied, because they pin the actual versions of the requirements:
+// a module replaced by the same path at another version is required at
+// that version, one replaced by another module or a local directory has the
+// replacement as version, like `github.com/fork/errors v0.9.1` or
+// `./staging/src/k8s.io/api`.
+func ParseGoModRequires(content string) (map[string]string, error) {
+	requires := map[string]string{}
+	replaces := []goModReplace{}
+	block := ""
+	for i, line := range strings.Split(content, "\n") {
+		if idx := strings.Index(line, "//"); idx >= 0 {
+			line = line[:idx]
+		}
+		fields := strings.Fields(line)
+		if len(fields) == 0 {
+			continue
+		}
+
+		switch {
+		case block != "" && fields[0] == ")":
+			block = ""
+			continue
+		case block != "":
+			fields = append([]string{block}, fields...)
+		case len(fields) == 2 && fields[1] == "(":
+			block = fields[0]
+			continue
+		}
+
+		switch fields[0] {
+		case "require":
+			if len(fields) != 3 {
+				return nil, errors.Errorf("invalid require directive in line %d: %q", i+1, line)
+			}
+			requires[fields[1]] = fields[2]
+		case "replace":
+			replace, err := parseReplace(fields[1:])
+			if err != nil {
+				return nil, errors.Wrapf(err, "invalid replace directive in line %d: %q", i+1, line)
+			}
+			replaces = append(replaces, replace)
+		}
+	}
+
+	// A replacement of a specific version takes precedence over the one of
+	// all versions of a module
+	replaced := map[string]string{}
+	for _, replace := range replaces {
+		version, ok := requires[replace.module]
+		if ok && replace.version == version {
+			replaced[replace.module] = replace.replacement
+		}
+	}
+	for _, replace := range replaces {
+		_, ok := requires[replace.module]
+		if _, done := replaced[replace.module]; ok && !done && replace.version == "" {
+			replaced[replace.module] = replace.replacement
+		}
+	}
+	for module, replacement := range replaced {
+		requires[module] = replacement
+	}
+	return requires, nil
+}
+
+// goModReplace is a single replace directive of a go.mod file
+type goModReplace struct {
+	module      string
+	version     string
+	replacement string
+}
+
+// parseReplace parses the fields of a replace directive, which are
+// `module [version] => replacement [version]`
+func parseReplace(fields []string) (goModReplace, error) {
+	arrow := -1
+	for i := range fields {
+		if fields[i] == "=>" {
+			arrow = i
+		}
+	}
+	if arrow < 1 || arrow > 2 || len(fields) == arrow+1 || len(fields) > arrow+3 {
+		return goModReplace{}, errors.New("must be <module> [<version>] => <replacement> [<version>]")
+	}
+
+	res := goModReplace{module: fields[0]}
+	if arrow == 2 {
+		res.version = fields[1]
+	}
+	replacement := fields[arrow+1:]
+	if len(replacement) == 2 && replacement[0] == res.module {
+		res.replacement = replacement[1]
+	} else {
+		res.replacement = strings.Join(replacement, " ")
+	}
+	return res, nil
+}
+
+// CompareDependencies returns the changes from the `from` to the `to` module
+// requirements, sorted by the module path
+func CompareDependencies(from, to map[string]string) *DependencyChanges {
+	changes := &DependencyChanges{
+		Added:   []Dependency{},
+		Updated: []DependencyUpdate{},
+		Removed: []Dependency{},
+	}
+	for name, version := range to {
+		fromVersion, ok := from[name]
+		if !ok {
+			changes.Added = append(changes.Added, Dependency{Name: name, Version: version})
+		} else if fromVersion != version {
+			changes.Updated = append(changes.Updated, DependencyUpdate{
+				Name: name, From: fromVersion, To: version,
+			})
+		}
+	}
+	for name, version := range from {
+		if _, ok := to[name]; !ok {
+			changes.Removed = append(changes.Removed, Dependency{Name: name, Version: version})
+		}
+	}
+
+	sort.Slice(changes.Added, func(i, j int) bool {
+		return changes.Added[i].Name < changes.Added[j].Name
+	})
+	sort.Slice(changes.Updated, func(i, j int) bool {
+		return changes.Updated[i].Name < changes.Updated[j].Name
+	})
+	sort.Slice(changes.Removed, func(i, j int) bool {
+		return changes.Removed[i].Name < changes.Removed[j].Name
+	})
+	return changes
+}
+
+// DependenciesBetween returns the changes of the go.mod requirements between
+// the revisions `from` and `to` of the repository
+func DependenciesBetween(repo *git.Repo, from, to string) (*DependencyChanges, error) {
+	requires := []map[string]string{}
+	for _, rev := range []string{from, to} {
+		goMod, err := repo.ShowFile(rev, "go.mod")
+		if err != nil {
+			return nil, err
+		}
+		parsed, err := ParseGoModRequires(goMod)
+		if err != nil {
+			return nil, errors.Wrapf(err, "parsing go.mod of %s", rev)
+		}
+		requires = append(requires, parsed)
+	}
+	return CompareDependencies(requires[0], requires[1]), nil
+}
+
+// Markdown renders the dependency changes as markdown section with one table
+// per type of change, suitable for release notes
+func (c *DependencyChanges) Markdown() string {
+	o := &strings.Builder{}
+	o.WriteString("## Dependencies\n\n")
+
+	o.WriteString("### Added\n")
+	writeDependencies(o, []string{"Dependency", "Version"}, len(c.Added), func(i int) []string {
+		return []string{c.Added[i].Name, c.Added[i].Version}
+	})
+
+	o.WriteString("\n### Changed\n")
+	writeDependencies(o, []string{"Dependency", "From", "To"}, len(c.Updated), func(i int) []string {
+		return []string{c.Updated[i].Name, c.Updated[i].From, c.Updated[i].To}
+	})
+
+	o.WriteString("\n### Removed\n")
+	writeDependencies(o, []string{"Dependency", "Version"}, len(c.Removed), func(i int) []string {
+		return []string{c.Removed[i].Name, c.Removed[i].Version}
+	})
+	return o.String()
+}
+
+// writeDependencies writes a markdown table with the `header` and `count`
+// rows returned by `row`
+func writeDependencies(o *strings.Builder, header []string, count int, row func(int) []string) {
+	if count == 0 {
+		o.WriteString("_Nothing has changed._\n")
+		return
+	}
+	separator := make([]string, len(header))
+	for i := range separator {
+		separator[i] = "---"
+	}
+	writeTableRow(o, header)
+	writeTableRow(o, separator)
+	for i := 0; i < count; i++ {
+		writeTableRow(o, row(i))
+	}
+}
+
+func writeTableRow(o *strings.Builder, cells []string) {
+	escaped := make([]string, len(cells))
+	for i := range cells {
+		escaped[i] = strings.ReplaceAll(cells[i], "|", "\\|")
+	}
+	fmt.Fprintf(o, "| %s |\n", strings.Join(escaped, " | "))
+}
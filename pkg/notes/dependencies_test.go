@@ -0,0 +1,143 @@
+/*
+Copyright 2020 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package notes
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestParseGoModRequires(t *testing.T) {
+	for name, tc := range map[string]struct {
+		content   string
+		expected  map[string]string
+		shouldErr bool
+	}{
+		"Block": {
+			content: `module k8s.io/kubernetes
+
+go 1.13
+
+require (
+	github.com/blang/semver v3.5.1+incompatible
+	// a comment
+	github.com/pkg/errors v0.8.1 // indirect
+)
+
+require k8s.io/utils v0.0.0-20200117235808-5f6fbceb4c31
+
+replace github.com/pkg/errors => github.com/pkg/errors v0.9.1
+`,
+			expected: map[string]string{
+				"github.com/blang/semver": "v3.5.1+incompatible",
+				"github.com/pkg/errors":   "v0.9.1",
+				"k8s.io/utils":            "v0.0.0-20200117235808-5f6fbceb4c31",
+			},
+		},
+		"Replace": {
+			content: `module k8s.io/kubernetes
+
+require (
+	github.com/blang/semver v3.5.1+incompatible
+	github.com/pkg/errors v0.8.1
+	k8s.io/api v0.0.0
+	k8s.io/utils v0.0.0-20200117235808-5f6fbceb4c31
+)
+
+replace (
+	github.com/blang/semver => github.com/blang/semver v3.5.0+incompatible
+	github.com/pkg/errors => github.com/fork/errors v0.9.1
+	github.com/pkg/errors v0.8.1 => github.com/pkg/errors v0.9.0
+	github.com/not/required => github.com/not/required v1.0.0
+	k8s.io/api => ./staging/src/k8s.io/api
+	k8s.io/utils v0.0.0-20190801114015-581e00157fb1 => k8s.io/utils v0.0.0-20200414100711-2df71ebbae66
+)
+`,
+			expected: map[string]string{
+				"github.com/blang/semver": "v3.5.0+incompatible",
+				"github.com/pkg/errors":   "v0.9.0",
+				"k8s.io/api":              "./staging/src/k8s.io/api",
+				"k8s.io/utils":            "v0.0.0-20200117235808-5f6fbceb4c31",
+			},
+		},
+		"Fork": {
+			content:  "require github.com/pkg/errors v0.8.1\nreplace github.com/pkg/errors => github.com/fork/errors v0.9.1\n",
+			expected: map[string]string{"github.com/pkg/errors": "github.com/fork/errors v0.9.1"},
+		},
+		"InvalidReplace": {
+			content:   "replace github.com/pkg/errors v0.8.1\n",
+			shouldErr: true,
+		},
+		"Empty": {
+			content:  "module k8s.io/kubernetes\n",
+			expected: map[string]string{},
+		},
+		"Invalid": {
+			content:   "require (\n\tgithub.com/pkg/errors\n)\n",
+			shouldErr: true,
+		},
+	} {
+		t.Run(name, func(t *testing.T) {
+			res, err := ParseGoModRequires(tc.content)
+			if tc.shouldErr {
+				require.NotNil(t, err)
+			} else {
+				require.Nil(t, err)
+				require.Equal(t, tc.expected, res)
+			}
+		})
+	}
+}
+
+func TestCompareDependencies(t *testing.T) {
+	changes := CompareDependencies(
+		map[string]string{"a": "v1.0.0", "b": "v1.0.0", "c": "v1.0.0"},
+		map[string]string{"b": "v1.1.0", "c": "v1.0.0", "e": "v2.0.0", "d": "v0.1.0"},
+	)
+	require.Equal(t, &DependencyChanges{
+		Added: []Dependency{
+			{Name: "d", Version: "v0.1.0"},
+			{Name: "e", Version: "v2.0.0"},
+		},
+		Updated: []DependencyUpdate{{Name: "b", From: "v1.0.0", To: "v1.1.0"}},
+		Removed: []Dependency{{Name: "a", Version: "v1.0.0"}},
+	}, changes)
+}
+
+func TestDependencyChangesMarkdown(t *testing.T) {
+	changes := &DependencyChanges{
+		Added:   []Dependency{{Name: "d", Version: "v0.1.0"}},
+		Updated: []DependencyUpdate{{Name: "b", From: "v1.0.0", To: "v1.1.0"}},
+		Removed: []Dependency{},
+	}
+	require.Equal(t, `## Dependencies
+
+### Added
+| Dependency | Version |
+| --- | --- |
+| d | v0.1.0 |
+
+### Changed
+| Dependency | From | To |
+| --- | --- | --- |
+| b | v1.0.0 | v1.1.0 |
+
+### Removed
+_Nothing has changed._
+`, changes.Markdown())
+}
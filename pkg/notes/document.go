@@ -30,6 +30,7 @@ import (
 
 // Document represents the underlying structure of a release notes document.
 type Document struct {
+	CVEs           []CVE               `json:"cves,omitempty"`
 	ActionRequired []string            `json:"action_required"`
 	Kinds          map[string][]string `json:"kinds"`
 	Uncategorized  []string            `json:"uncategorized"`
@@ -136,6 +137,12 @@ func (d *Document) RenderMarkdown(bucket, tars, prevTag, newTag string) (string,
 		nl()
 	}
 
+	// fixed vulnerabilities come first
+	if len(d.CVEs) > 0 {
+		o.WriteString(CVEsMarkdown(d.CVEs))
+		nl()
+	}
+
 	// notes with action required get their own section
 	if len(d.ActionRequired) > 0 {
 		o.WriteString("## Urgent Upgrade Notes")
@@ -262,11 +269,6 @@ func createDownloadsTable(w io.Writer, bucket, tars, prevTag, newTag string) err
 
 	fmt.Fprintf(w, "## Downloads for %s\n\n", newTag)
 
-	urlPrefix := fmt.Sprintf("https://storage.googleapis.com/%s/release", bucket)
-	if bucket == "kubernetes-release" {
-		urlPrefix = "https://dl.k8s.io"
-	}
-
 	for _, item := range []struct {
 		heading  string
 		patterns []string
@@ -282,39 +284,73 @@ func createDownloadsTable(w io.Writer, bucket, tars, prevTag, newTag string) err
 		fmt.Fprintln(w, "filename | sha512 hash")
 		fmt.Fprintln(w, "-------- | -----------")
 
-		for _, pattern := range item.patterns {
-			pattern := filepath.Join(tars, pattern)
+		artifacts, err := listArtifacts(bucket, tars, newTag, item.patterns...)
+		if err != nil {
+			return err
+		}
+		for _, artifact := range artifacts {
+			fmt.Fprintf(w,
+				"[%s](%s) | `%s`\n", artifact.Name, artifact.URL, artifact.SHA512,
+			)
+		}
 
-			matches, err := filepath.Glob(pattern)
-			if err != nil {
-				return err
-			}
+		fmt.Fprintln(w, "")
+	}
 
-			for _, file := range matches {
-				f, err := os.Open(file)
-				if err != nil {
-					return err
-				}
-				defer f.Close()
+	fmt.Fprintf(w, "## Changelog since %s\n\n", prevTag)
+	return nil
+}
 
-				h := sha512.New()
-				if _, err := io.Copy(h, f); err != nil {
-					return err
-				}
+// Artifact is a downloadable file of a release
+type Artifact struct {
+	Name   string `json:"name"`
+	URL    string `json:"url"`
+	SHA512 string `json:"sha512"`
+}
+
+// listArtifacts returns the files in the `tars` directory which match the
+// patterns, together with their download URL and SHA512 hash
+func listArtifacts(bucket, tars, tag string, patterns ...string) ([]Artifact, error) {
+	urlPrefix := fmt.Sprintf("https://storage.googleapis.com/%s/release", bucket)
+	if bucket == "kubernetes-release" {
+		urlPrefix = "https://dl.k8s.io"
+	}
+
+	artifacts := []Artifact{}
+	for _, pattern := range patterns {
+		matches, err := filepath.Glob(filepath.Join(tars, pattern))
+		if err != nil {
+			return nil, err
+		}
 
-				fileName := filepath.Base(file)
-				fmt.Fprintf(w,
-					"[%s](%s/%s/%s) | `%x`\n",
-					fileName, urlPrefix, newTag, fileName, h.Sum(nil),
-				)
+		for _, file := range matches {
+			hash, err := sha512File(file)
+			if err != nil {
+				return nil, err
 			}
+			fileName := filepath.Base(file)
+			artifacts = append(artifacts, Artifact{
+				Name:   fileName,
+				URL:    fmt.Sprintf("%s/%s/%s", urlPrefix, tag, fileName),
+				SHA512: hash,
+			})
 		}
+	}
+	return artifacts, nil
+}
 
-		fmt.Fprintln(w, "")
+func sha512File(path string) (string, error) {
+	f, err := os.Open(path)
+	if err != nil {
+		return "", err
 	}
+	defer f.Close()
 
-	fmt.Fprintf(w, "## Changelog since %s\n\n", prevTag)
-	return nil
+	h := sha512.New()
+	if _, err := io.Copy(h, f); err != nil {
+		return "", err
+	}
+	return fmt.Sprintf("%x", h.Sum(nil)), nil
 }
 
 func highestPriorityKind(kinds []string) string {
@@ -0,0 +1,66 @@
+/*
+Copyright 2020 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package notes
+
+import (
+	"bytes"
+	"text/template"
+
+	"gopkg.in/russross/blackfriday.v2"
+)
+
+const htmlTemplate = `<!DOCTYPE html>
+<html>
+  <head>
+    <meta charset="utf-8" />
+    <meta name="viewport" content="width=device-width" />
+    <title>{{ .Title }}</title>
+    <style type="text/css">
+      table,
+      th,
+      tr,
+      td {
+        border: 1px solid gray;
+        border-collapse: collapse;
+        padding: 5px;
+      }
+    </style>
+  </head>
+  <body>
+    {{ .Content }}
+  </body>
+</html>`
+
+// MarkdownToHTML converts the provided markdown into a standalone HTML page
+// with the given title.
+func MarkdownToHTML(title, markdown string) (string, error) {
+	content := blackfriday.Run([]byte(markdown))
+
+	t, err := template.New("html").Parse(htmlTemplate)
+	if err != nil {
+		return "", err
+	}
+
+	output := bytes.Buffer{}
+	if err := t.Execute(&output, struct {
+		Title, Content string
+	}{title, string(content)}); err != nil {
+		return "", err
+	}
+
+	return output.String(), nil
+}
@@ -0,0 +1,32 @@
+/*
+Copyright 2020 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package notes
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestMarkdownToHTML(t *testing.T) {
+	html, err := MarkdownToHTML("v1.18.0", "- Some note")
+	require.Nil(t, err)
+	require.True(t, strings.HasPrefix(html, "<!DOCTYPE html>"))
+	require.Contains(t, html, "<title>v1.18.0</title>")
+	require.Contains(t, html, "Some note")
+}
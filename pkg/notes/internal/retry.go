@@ -19,8 +19,8 @@ package internal
 import (
 	"time"
 
-	"github.com/google/go-github/v29/github"
-	"github.com/sirupsen/logrus"
+	kgithub "k8s.io/release/pkg/github"
+	"k8s.io/release/pkg/util"
 )
 
 const (
@@ -28,11 +28,6 @@ const (
 	// retryable before we give up and do not flag the same call as retryable
 	// anymore.
 	MaxGithubRetries = 3
-
-	// defaultGithubSleep is the amount of time we wait between two consecutive
-	// GitHub calls in case we cannot extract that information from the error
-	// itself.
-	defaultGithubSleep = time.Minute
 )
 
 // DefaultGithubErrChecker is a GithubErrChecker set up with a default amount
@@ -47,10 +42,10 @@ func DefaultGithubErrChecker() func(error) bool {
 // should be retried at max, and `sleeper`, a function which implements the
 // sleeping.
 //
-// Currently the only special error that is flagged as retryable is the
-// `AbuseRateLimitError`. If such an error occurs, we sleep for a while (the
-// amount of time the error told us to wait) and then report back that we can
-// retry.
+// Currently the special errors that are flagged as retryable are the
+// `AbuseRateLimitError` and the `RateLimitError`. If such an error occurs, we
+// sleep for a while (the amount of time the error told us to wait, or until
+// the rate limit gets reset) and then report back that we can retry.
 // Other special errors should be easy to implement too.
 //
 // It can be used like this:
@@ -61,31 +56,10 @@ func DefaultGithubErrChecker() func(error) bool {
 //    }
 //  }
 func GithubErrChecker(maxTries int, sleeper func(time.Duration)) func(error) bool {
-	try := 0
-
-	return func(err error) bool {
-		if err == nil {
-			return false
-		}
-		if try >= maxTries {
-			logrus.Errorf("Max retries (%d) reached, not retrying anymore: %v", maxTries, err)
-			return false
-		}
-
-		try++
-
-		if aerr, ok := err.(*github.AbuseRateLimitError); ok {
-			waitDuration := defaultGithubSleep
-			if d := aerr.RetryAfter; d != nil {
-				waitDuration = *d
-			}
-			logrus.
-				WithField("err", aerr).
-				Infof("Hit the abuse rate limit on try %d, sleeping for %s", try, waitDuration)
-			sleeper(waitDuration)
-			return true
-		}
-
-		return false
-	}
+	return util.Retrier(&util.RetryOptions{
+		MaxRetries: maxTries,
+		Retryable:  kgithub.IsRateLimitError,
+		Delay:      kgithub.RateLimitDelay,
+		Sleeper:    sleeper,
+	})
 }
@@ -80,6 +80,31 @@ func TestGithubRetryer(t *testing.T) {
 			errs:            []error{&github.AbuseRateLimitError{}},
 			expectedResults: []bool{true},
 		},
+		"when the error is a github rate limit error, retry": {
+			maxTries:        1,
+			sleeper:         nilSleeper,
+			errs:            []error{&github.RateLimitError{}},
+			expectedResults: []bool{true},
+		},
+		"when the error is a github rate limit error but max tries have been reached, don't retry": {
+			maxTries: 1,
+			sleeper:  nilSleeper,
+			errs: []error{
+				&github.RateLimitError{},
+				&github.RateLimitError{},
+			},
+			expectedResults: []bool{
+				true, false,
+			},
+		},
+		"when the rate limit has already been reset, retry": {
+			maxTries: 1,
+			sleeper:  nilSleeper,
+			errs: []error{&github.RateLimitError{
+				Rate: github.Rate{Reset: github.Timestamp{Time: time.Now().Add(-time.Hour)}},
+			}},
+			expectedResults: []bool{true},
+		},
 		"when a RetryAfter is specified on the abuse rate limit error, sleep that amount of time": {
 			maxTries:        1,
 			sleeper:         sleepChecker(t, 42*time.Minute),
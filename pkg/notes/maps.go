@@ -0,0 +1,120 @@
+/*
+Copyright 2020 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package notes
+
+import (
+	"io/ioutil"
+	"path/filepath"
+
+	"github.com/pkg/errors"
+	"sigs.k8s.io/yaml"
+)
+
+// ReleaseNotesMap is a single override for the release note of a PR. It is
+// usually stored as YAML, for example:
+//
+//	pr: 12345
+//	releasenote:
+//	  text: Fixed a typo in the original release note.
+//	  kinds:
+//	  - bug
+//
+// Maps can only amend PRs which already contain a release note.
+type ReleaseNotesMap struct {
+	// PR is the number of the pull request the map applies to
+	PR int `json:"pr"`
+
+	// ReleaseNote contains the fields to be overridden. Fields which are not
+	// set keep their original value.
+	ReleaseNote struct {
+		Text           *string   `json:"text,omitempty"`
+		Kinds          *[]string `json:"kinds,omitempty"`
+		SIGs           *[]string `json:"sigs,omitempty"`
+		Areas          *[]string `json:"areas,omitempty"`
+		ActionRequired *bool     `json:"action_required,omitempty"`
+
+		// DoNotPublish suppresses the release note completely
+		DoNotPublish bool `json:"do_not_publish,omitempty"`
+	} `json:"releasenote"`
+}
+
+// ParseReleaseNotesMaps reads all YAML release notes maps from the provided
+// directory and returns them indexed by their PR number.
+func ParseReleaseNotesMaps(dir string) (map[int]*ReleaseNotesMap, error) {
+	files, err := ioutil.ReadDir(dir)
+	if err != nil {
+		return nil, errors.Wrapf(err, "reading release notes maps directory %s", dir)
+	}
+
+	maps := make(map[int]*ReleaseNotesMap)
+	for _, file := range files {
+		ext := filepath.Ext(file.Name())
+		if file.IsDir() || (ext != ".yaml" && ext != ".yml") {
+			continue
+		}
+
+		path := filepath.Join(dir, file.Name())
+		content, err := ioutil.ReadFile(path)
+		if err != nil {
+			return nil, errors.Wrapf(err, "reading release notes map %s", path)
+		}
+
+		noteMap := &ReleaseNotesMap{}
+		if err := yaml.UnmarshalStrict(content, noteMap); err != nil {
+			return nil, errors.Wrapf(err, "parsing release notes map %s", path)
+		}
+		if noteMap.PR <= 0 {
+			return nil, errors.Errorf("release notes map %s has no valid PR number", path)
+		}
+		if _, ok := maps[noteMap.PR]; ok {
+			return nil, errors.Errorf(
+				"release notes map %s: PR #%d is already mapped", path, noteMap.PR,
+			)
+		}
+		maps[noteMap.PR] = noteMap
+	}
+
+	return maps, nil
+}
+
+// ApplyTo overrides the fields of the release note with the ones set in the
+// map and re-renders its markdown.
+func (m *ReleaseNotesMap) ApplyTo(note *ReleaseNote) {
+	if m.ReleaseNote.Text != nil {
+		note.Text = *m.ReleaseNote.Text
+	}
+	if m.ReleaseNote.Kinds != nil {
+		note.Kinds = *m.ReleaseNote.Kinds
+		note.Feature = HasString(note.Kinds, "feature")
+		note.DuplicateKind = len(note.Kinds) > 1
+	}
+	if m.ReleaseNote.SIGs != nil {
+		note.SIGs = *m.ReleaseNote.SIGs
+		note.Duplicate = len(note.SIGs) > 1
+	}
+	if m.ReleaseNote.Areas != nil {
+		note.Areas = *m.ReleaseNote.Areas
+	}
+	if m.ReleaseNote.ActionRequired != nil {
+		note.ActionRequired = *m.ReleaseNote.ActionRequired
+	}
+
+	note.Markdown = noteMarkdown(
+		note.Text, note.PrNumber, note.PrURL, note.Author, note.AuthorURL,
+		note.SIGs,
+	)
+}
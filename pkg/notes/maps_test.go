@@ -0,0 +1,128 @@
+/*
+Copyright 2020 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package notes
+
+import (
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestParseReleaseNotesMaps(t *testing.T) {
+	cases := map[string]struct {
+		files   map[string]string
+		prs     []int
+		wantErr bool
+	}{
+		"Success": {
+			files: map[string]string{
+				"pr-1.yaml": "pr: 1\nreleasenote:\n  text: new text\n",
+				"pr-2.yml":  "pr: 2\nreleasenote:\n  do_not_publish: true\n",
+				"README.md": "not a map",
+			},
+			prs: []int{1, 2},
+		},
+		"MissingPR": {
+			files: map[string]string{
+				"map.yaml": "releasenote:\n  text: new text\n",
+			},
+			wantErr: true,
+		},
+		"DuplicatePR": {
+			files: map[string]string{
+				"a.yaml": "pr: 1\n",
+				"b.yaml": "pr: 1\n",
+			},
+			wantErr: true,
+		},
+		"UnknownField": {
+			files: map[string]string{
+				"map.yaml": "pr: 1\nreleasenote:\n  txt: typo\n",
+			},
+			wantErr: true,
+		},
+	}
+
+	for name, tc := range cases {
+		t.Run(name, func(t *testing.T) {
+			dir, err := ioutil.TempDir("", "maps-")
+			require.Nil(t, err)
+			defer os.RemoveAll(dir)
+
+			for file, content := range tc.files {
+				require.Nil(t, ioutil.WriteFile(
+					filepath.Join(dir, file), []byte(content), os.FileMode(0644),
+				))
+			}
+
+			maps, err := ParseReleaseNotesMaps(dir)
+			if tc.wantErr {
+				require.NotNil(t, err)
+				return
+			}
+			require.Nil(t, err)
+			require.Len(t, maps, len(tc.prs))
+			for _, pr := range tc.prs {
+				require.Contains(t, maps, pr)
+			}
+		})
+	}
+}
+
+func TestParseReleaseNotesMapsNotExistingDir(t *testing.T) {
+	_, err := ParseReleaseNotesMaps("/not/existing")
+	require.NotNil(t, err)
+}
+
+func TestReleaseNotesMapApplyTo(t *testing.T) {
+	text := "fixed a typo"
+	kinds := []string{"bug", "feature"}
+	sigs := []string{"release"}
+
+	noteMap := &ReleaseNotesMap{PR: 1}
+	noteMap.ReleaseNote.Text = &text
+	noteMap.ReleaseNote.Kinds = &kinds
+	noteMap.ReleaseNote.SIGs = &sigs
+
+	note := &ReleaseNote{
+		Text:      "fixed a tpyo",
+		PrNumber:  1,
+		PrURL:     "https://github.com/kubernetes/kubernetes/pull/1",
+		Author:    "user",
+		AuthorURL: "https://github.com/user",
+		Areas:     []string{"test"},
+		SIGs:      []string{"node", "cli"},
+		Duplicate: true,
+	}
+	noteMap.ApplyTo(note)
+
+	require.Equal(t, text, note.Text)
+	require.Equal(t, kinds, note.Kinds)
+	require.Equal(t, sigs, note.SIGs)
+	require.Equal(t, []string{"test"}, note.Areas)
+	require.True(t, note.Feature)
+	require.True(t, note.DuplicateKind)
+	require.False(t, note.Duplicate)
+	require.Equal(t,
+		"Fixed a typo ([#1](https://github.com/kubernetes/kubernetes/pull/1), "+
+			"[@user](https://github.com/user)) [SIG Release]",
+		note.Markdown,
+	)
+}
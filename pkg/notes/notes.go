@@ -97,6 +97,9 @@ type ReleaseNote struct {
 	// Tags each note with a release version if specified
 	// If not specified, omitted
 	ReleaseVersion string `json:"release_version,omitempty"`
+
+	// CVEs are the vulnerabilities fixed by the PR, if declared in a CVE file
+	CVEs []CVE `json:"cves,omitempty"`
 }
 
 type Documentation struct {
@@ -169,6 +172,15 @@ func (g *Gatherer) ListReleaseNotes() (ReleaseNotes, ReleaseNotesHistory, error)
 		return nil, nil, err
 	}
 
+	var maps map[int]*ReleaseNotesMap
+	if g.options.MapsDir != "" {
+		maps, err = ParseReleaseNotesMaps(g.options.MapsDir)
+		if err != nil {
+			return nil, nil, err
+		}
+		logrus.Infof("using %d release notes maps from %s", len(maps), g.options.MapsDir)
+	}
+
 	dedupeCache := map[string]struct{}{}
 	notes := make(ReleaseNotes)
 	history := ReleaseNotesHistory{}
@@ -189,6 +201,15 @@ func (g *Gatherer) ListReleaseNotes() (ReleaseNotes, ReleaseNotesHistory, error)
 			continue
 		}
 
+		if noteMap, ok := maps[note.PrNumber]; ok {
+			if noteMap.ReleaseNote.DoNotPublish {
+				logrus.Infof("suppressing release note of PR #%d as requested by map", note.PrNumber)
+				continue
+			}
+			logrus.Infof("applying release notes map to PR #%d", note.PrNumber)
+			noteMap.ApplyTo(note)
+		}
+
 		// exclusionFilters is a list of regular expressions that match notes text that
 		// are deemed to have no content and should NOT be added to release notes.
 		exclusionFilters := []string{
@@ -336,7 +357,6 @@ func (g *Gatherer) ReleaseNoteFromCommit(result *Result, relVer string) (*Releas
 		g.options.GithubOrg, g.options.GithubRepo, pr.GetNumber(),
 	)
 	isFeature := HasString(LabelsWithPrefix(pr, "kind"), "feature")
-	noteSuffix := prettifySIGList(LabelsWithPrefix(pr, "sig"))
 
 	isDuplicateSIG := false
 	if len(LabelsWithPrefix(pr, "sig")) > 1 {
@@ -348,16 +368,10 @@ func (g *Gatherer) ReleaseNoteFromCommit(result *Result, relVer string) (*Releas
 		isDuplicateKind = true
 	}
 
-	indented := strings.ReplaceAll(text, "\n", "\n  ")
-	markdown := fmt.Sprintf("%s ([#%d](%s), [@%s](%s))",
-		indented, pr.GetNumber(), prURL, author, authorURL)
-
-	if noteSuffix != "" {
-		markdown = fmt.Sprintf("%s [%s]", markdown, noteSuffix)
-	}
-
-	// Uppercase the first character of the markdown to make it look uniform
-	markdown = strings.ToUpper(string(markdown[0])) + markdown[1:]
+	markdown := noteMarkdown(
+		text, pr.GetNumber(), prURL, author, authorURL,
+		LabelsWithPrefix(pr, "sig"),
+	)
 
 	return &ReleaseNote{
 		Commit:         result.commit.GetSHA(),
@@ -379,6 +393,22 @@ func (g *Gatherer) ReleaseNoteFromCommit(result *Result, relVer string) (*Releas
 	}, nil
 }
 
+// noteMarkdown renders the markdown representation of a single release note
+func noteMarkdown(
+	text string, prNumber int, prURL, author, authorURL string, sigs []string,
+) string {
+	indented := strings.ReplaceAll(text, "\n", "\n  ")
+	markdown := fmt.Sprintf("%s ([#%d](%s), [@%s](%s))",
+		indented, prNumber, prURL, author, authorURL)
+
+	if noteSuffix := prettifySIGList(sigs); noteSuffix != "" {
+		markdown = fmt.Sprintf("%s [%s]", markdown, noteSuffix)
+	}
+
+	// Uppercase the first character of the markdown to make it look uniform
+	return strings.ToUpper(string(markdown[0])) + markdown[1:]
+}
+
 // ListCommits lists all commits starting from a given commit SHA and ending at
 // a given commit SHA.
 func (g *Gatherer) ListCommits(branch, start, end string) ([]*github.RepositoryCommit, error) {
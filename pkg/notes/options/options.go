@@ -48,8 +48,11 @@ type Options struct {
 	TableOfContents bool
 	Debug           bool
 	Pull            bool
+	Dependencies    bool
 	RecordDir       string
 	ReplayDir       string
+	MapsDir         string
+	CVEFile         string
 	githubToken     string
 	gitCloneFn      func(string, string, string, bool) (*git.Repo, error)
 }
@@ -107,7 +110,7 @@ func (o *Options) ValidateAndFinish() (err error) {
 
 	// Check if we want to automatically discover the revisions
 	if o.DiscoverMode != RevisionDiscoveryModeNONE {
-		repo, err := o.repo()
+		repo, err := o.Repo()
 		if err != nil {
 			return err
 		}
@@ -148,7 +151,7 @@ func (o *Options) ValidateAndFinish() (err error) {
 
 	// Check if we have to parse a revision
 	if (o.StartRev != "" && o.StartSHA == "") || (o.EndRev != "" && o.EndSHA == "") {
-		repo, err := o.repo()
+		repo, err := o.Repo()
 		if err != nil {
 			return err
 		}
@@ -181,7 +184,9 @@ func (o *Options) ValidateAndFinish() (err error) {
 	return nil
 }
 
-func (o *Options) repo() (repo *git.Repo, err error) {
+// Repo returns the local repository at RepoPath, which gets cloned or
+// updated first if Pull is set
+func (o *Options) Repo() (repo *git.Repo, err error) {
 	if o.Pull {
 		logrus.Infof("cloning/updating repository %s/%s", o.GithubOrg, o.GithubRepo)
 		repo, err = o.gitCloneFn(
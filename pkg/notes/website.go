@@ -0,0 +1,89 @@
+/*
+Copyright 2020 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package notes
+
+import (
+	"github.com/pkg/errors"
+
+	"k8s.io/release/pkg/git"
+)
+
+// WebsiteData is the structured release data consumed by release notes
+// websites like relnotes.k8s.io
+type WebsiteData struct {
+	// Version is the release the data belongs to
+	Version string `json:"version"`
+
+	// Notes are the release notes in the order of their commits
+	Notes []*ReleaseNote `json:"notes"`
+
+	// SIGs maps the SIGs to the PR numbers of their release notes
+	SIGs map[string][]int `json:"sigs"`
+
+	// Downloads are the release artifacts, if available
+	Downloads []Artifact `json:"downloads,omitempty"`
+
+	// Dependencies are the changed Go module requirements, if available
+	Dependencies *DependencyChanges `json:"dependencies,omitempty"`
+
+	// CVEs are the vulnerabilities fixed in the release, if available
+	CVEs []CVE `json:"cves,omitempty"`
+}
+
+// CreateWebsiteData assembles the website data of the release notes
+func CreateWebsiteData(
+	notes ReleaseNotes, history ReleaseNotesHistory, version string,
+) *WebsiteData {
+	data := &WebsiteData{
+		Version: version,
+		Notes:   []*ReleaseNote{},
+		SIGs:    map[string][]int{},
+	}
+	for _, pr := range history {
+		note, ok := notes[pr]
+		if !ok {
+			continue
+		}
+		data.Notes = append(data.Notes, note)
+		for _, sig := range note.SIGs {
+			data.SIGs[sig] = append(data.SIGs[sig], note.PrNumber)
+		}
+	}
+	return data
+}
+
+// AddDownloads adds all release tarballs in the `tars` directory to the
+// website data, linked to the release `tag` in the `bucket`
+func (d *WebsiteData) AddDownloads(bucket, tars, tag string) error {
+	artifacts, err := listArtifacts(bucket, tars, tag, "kubernetes*.tar.gz")
+	if err != nil {
+		return errors.Wrap(err, "listing release artifacts")
+	}
+	d.Downloads = artifacts
+	return nil
+}
+
+// AddDependencies adds the changes of the go.mod requirements between the
+// revisions `from` and `to` of the repository to the website data
+func (d *WebsiteData) AddDependencies(repo *git.Repo, from, to string) error {
+	changes, err := DependenciesBetween(repo, from, to)
+	if err != nil {
+		return err
+	}
+	d.Dependencies = changes
+	return nil
+}
@@ -0,0 +1,67 @@
+/*
+Copyright 2020 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package notes
+
+import (
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestCreateWebsiteData(t *testing.T) {
+	notes := ReleaseNotes{
+		1: {PrNumber: 1, SIGs: []string{"node", "cli"}},
+		2: {PrNumber: 2, SIGs: []string{"node"}},
+		3: {PrNumber: 3},
+	}
+
+	data := CreateWebsiteData(notes, ReleaseNotesHistory{3, 2, 1, 4}, "v1.18.0")
+	require.Equal(t, "v1.18.0", data.Version)
+	require.Len(t, data.Notes, 3)
+	require.Equal(t, 3, data.Notes[0].PrNumber)
+	require.Equal(t, 1, data.Notes[2].PrNumber)
+	require.Equal(t, map[string][]int{"node": {2, 1}, "cli": {1}}, data.SIGs)
+}
+
+func TestWebsiteDataAddDownloads(t *testing.T) {
+	dir, err := ioutil.TempDir("", "")
+	require.Nil(t, err)
+	defer os.RemoveAll(dir)
+
+	for _, file := range []string{
+		"kubernetes.tar.gz",
+		"kubernetes-client-linux-amd64.tar.gz",
+		"other.txt",
+	} {
+		require.Nil(t, ioutil.WriteFile(
+			filepath.Join(dir, file), []byte{1, 2, 3}, os.FileMode(0644),
+		))
+	}
+
+	data := CreateWebsiteData(ReleaseNotes{}, ReleaseNotesHistory{}, "v1.18.0")
+	require.Nil(t, data.AddDownloads("kubernetes-release", dir, "v1.18.0"))
+	require.Len(t, data.Downloads, 2)
+	require.Equal(t, "kubernetes-client-linux-amd64.tar.gz", data.Downloads[0].Name)
+	require.Equal(t,
+		"https://dl.k8s.io/v1.18.0/kubernetes-client-linux-amd64.tar.gz",
+		data.Downloads[0].URL,
+	)
+	require.Len(t, data.Downloads[0].SHA512, 128)
+}
@@ -19,11 +19,15 @@ package patch
 import (
 	"bytes"
 	"fmt"
+	"io/ioutil"
+	"os"
+	"path/filepath"
 	"strings"
 	"text/template"
 	"time"
 
 	"k8s.io/release/pkg/log"
+	"k8s.io/release/pkg/notes"
 	"k8s.io/release/pkg/patch/internal"
 )
 
@@ -37,6 +41,18 @@ type AnnounceOptions struct {
 	ReleaseRepoPath string
 	SendgridAPIKey  string
 	GithubToken     string
+
+	// TemplateDir contains templates overriding the built-in mail
+	// templates, if set. See MailData for the data passed to them.
+	TemplateDir string
+
+	// Locale selects the localized variants of the templates in
+	// TemplateDir, like de or pt_BR
+	Locale string
+
+	// CVEFile is the YAML file of the CVEs fixed in the release, which are
+	// announced in a separate section if set
+	CVEFile string
 }
 
 type Announcer struct {
@@ -47,6 +63,10 @@ type Announcer struct {
 	MailSender   MailSender
 	Formatter    Formatter
 	Workspace    Workspace
+
+	// Sent gets called after the announcement of `version` has been sent to
+	// the `recipients` with --nomock, if set
+	Sent func(version string, recipients []string) error
 }
 
 const (
@@ -81,9 +101,33 @@ func (a *Announcer) Run() error {
 		return err
 	}
 
-	subject := "Kubernetes " + ver + " cut planned for " + dateFormatHuman(cutDate)
+	cves := []notes.CVE{}
+	if a.Opts.CVEFile != "" {
+		cves, err = notes.ParseCVEs(a.Opts.CVEFile)
+		if err != nil {
+			a.Logger().WithError(err).Debug("reading CVEs failed")
+			return err
+		}
+	}
+
+	data := &MailData{
+		DateFreeze:                 freezeDate,
+		DateCut:                    cutDate,
+		Version:                    ver,
+		ReleaseManagerName:         ReleaseManagerName,
+		ReleaseManagerTag:          ReleaseManagerTag,
+		ReleaseManagerEmail:        ReleaseManagerEmail,
+		ReleaseManagerSlackChannel: ReleaseManagerSlackChannel,
+	}
+
+	subject, err := a.renderTemplate(MailSubjectTemplate, MailSubject, data)
+	if err != nil {
+		a.Logger().WithError(err).Debug("getting mail subject failed")
+		return err
+	}
+	subject = strings.TrimSpace(subject)
 
-	head, err := a.getMailHead(ver, freezeDate, cutDate)
+	head, err := a.renderTemplate(MailHeadTemplate, MailHeadMarkdown, data)
 	if err != nil {
 		a.Logger().WithError(err).Debug("getting mail head failed")
 		return err
@@ -95,7 +139,11 @@ func (a *Announcer) Run() error {
 		return err
 	}
 
-	body, err := a.formatAsHTML(subject, head, relNotes)
+	parts := []string{head}
+	if len(cves) > 0 {
+		parts = append(parts, notes.CVEsMarkdown(cves))
+	}
+	body, err := a.formatAsHTML(subject, append(parts, relNotes)...)
 	if err != nil {
 		a.Logger().WithError(err).Debug("formatting mail as html failed")
 		return err
@@ -111,6 +159,12 @@ func (a *Announcer) Run() error {
 		return err
 	}
 
+	if a.Opts.Nomock && a.Sent != nil {
+		if err := a.Sent(ver, []string{KDevEmail, KDevAnnounceEmail}); err != nil {
+			return err
+		}
+	}
+
 	return nil
 }
 
@@ -159,39 +213,84 @@ func loadTemplate(tmplString string) (*template.Template, error) {
 	return template.New("main").Funcs(funcs).Parse(tmplString)
 }
 
-func (a *Announcer) getMailHead(version string, freezeDate, cutDate time.Time) (string, error) {
-	tmpl, err := loadTemplate(MailHeadMarkdown)
+// MailData is the data passed to the mail templates
+type MailData struct {
+	// DateFreeze is the last day cherry picks get merged for the release
+	DateFreeze time.Time
+
+	// DateCut is the day the release is planned to be cut
+	DateCut time.Time
+
+	// Version is the upcoming version, like v1.18.3
+	Version string
+
+	// ReleaseManagerName, ReleaseManagerEmail, ReleaseManagerTag and
+	// ReleaseManagerSlackChannel are the contacts of the patch release team
+	ReleaseManagerName         string
+	ReleaseManagerEmail        string
+	ReleaseManagerTag          string
+	ReleaseManagerSlackChannel string
+}
+
+func (a *Announcer) renderTemplate(name, builtin string, data *MailData) (string, error) {
+	content, err := a.templateContent(name, builtin)
 	if err != nil {
 		return "", err
 	}
 
-	templated := &bytes.Buffer{}
-	templateData := struct {
-		DateFreeze                 time.Time
-		DateCut                    time.Time
-		Version                    string
-		ReleaseManagerName         string
-		ReleaseManagerEmail        string
-		ReleaseManagerTag          string
-		ReleaseManagerSlackChannel string
-	}{
-		DateFreeze:                 freezeDate,
-		DateCut:                    cutDate,
-		Version:                    version,
-		ReleaseManagerName:         ReleaseManagerName,
-		ReleaseManagerTag:          ReleaseManagerTag,
-		ReleaseManagerEmail:        ReleaseManagerEmail,
-		ReleaseManagerSlackChannel: ReleaseManagerSlackChannel,
+	tmpl, err := loadTemplate(content)
+	if err != nil {
+		return "", fmt.Errorf("parsing template %s: %v", name, err)
 	}
 
-	err = tmpl.Execute(templated, templateData)
-	if err != nil {
-		return "", err
+	templated := &bytes.Buffer{}
+	if err := tmpl.Execute(templated, data); err != nil {
+		return "", fmt.Errorf("executing template %s: %v", name, err)
 	}
 
 	return templated.String(), nil
 }
 
+// templateContent returns the content of the template `name` in the
+// TemplateDir. Localized variants are preferred, for the locale pt_BR the
+// template mail-head.md is looked up as mail-head.pt_BR.md, mail-head.pt.md
+// and mail-head.md. The `builtin` template is used if none of them exists.
+func (a *Announcer) templateContent(name, builtin string) (string, error) {
+	if a.Opts.TemplateDir == "" {
+		return builtin, nil
+	}
+
+	for _, file := range templateCandidates(name, a.Opts.Locale) {
+		path := filepath.Join(a.Opts.TemplateDir, file)
+		content, err := ioutil.ReadFile(path)
+		if os.IsNotExist(err) {
+			continue
+		}
+		if err != nil {
+			return "", err
+		}
+		a.Logger().WithField("template", path).Debug("using custom template")
+		return string(content), nil
+	}
+
+	return builtin, nil
+}
+
+func templateCandidates(name, locale string) []string {
+	ext := filepath.Ext(name)
+	base := strings.TrimSuffix(name, ext)
+
+	res := []string{}
+	locale = strings.Replace(locale, "-", "_", -1)
+	if locale != "" {
+		res = append(res, base+"."+locale+ext)
+		if i := strings.Index(locale, "_"); i > 0 {
+			res = append(res, base+"."+locale[:i]+ext)
+		}
+	}
+	return append(res, name)
+}
+
 func (a *Announcer) getUpcomingVer() (string, error) {
 	if a.Workspace == nil {
 		w := &internal.Workspace{
@@ -18,6 +18,15 @@ package patch
 
 const hr = "\n\n----\n\n"
 
+// The file names of the templates in AnnounceOptions.TemplateDir, which
+// override MailSubject and MailHeadMarkdown
+const (
+	MailSubjectTemplate = "mail-subject.txt"
+	MailHeadTemplate    = "mail-head.md"
+)
+
+const MailSubject = `Kubernetes {{ .Version }} cut planned for {{ dateFormatHuman .DateCut }}`
+
 const MailHeadMarkdown = `
 Below is a draft of the generated changelog for {{ .Version }}. If you submitted a cherrypick, please make sure it's listed and has an **accurate release note**.
 
@@ -51,6 +51,7 @@ type testCase struct {
 	expectedMailerSubject     []*regexp.Regexp
 	expectedErrMsg            string
 	expectedRecipients        *[]string
+	expectedSentVersion       string
 	expectedSender            [2]string
 }
 
@@ -91,6 +92,43 @@ func TestAnnounce(t *testing.T) {
 			expectedMailerBody:    res("^some formatted html$"),
 			expectedMailerSubject: res("^Kubernetes v1.13.10 cut planned for Friday, 2010-11-12$"),
 		},
+		"when a template dir is set, its templates override the built-in ones": {
+			opts:                     getOpts(func(o *opts) { o.TemplateDir = "testdata/templates" }),
+			workspaceStatus:          map[string]string{"gitVersion": "v1.13.10-beta.0-16-g48844ef5e7"},
+			expectedFormatterSubject: res("^Kubernetes v1.13.10 cut planned for Friday, 2010-11-12$"),
+			expectedFormatterMarkdown: res(
+				"^Custom changelog draft of v1.13.10, the freeze is on Friday, 2010-11-05.",
+			),
+		},
+		"when a locale is set, the localized templates are used": {
+			opts: getOpts(func(o *opts) {
+				o.TemplateDir = "testdata/templates"
+				o.Locale = "de-DE"
+			}),
+			workspaceStatus:          map[string]string{"gitVersion": "v1.13.10-beta.0-16-g48844ef5e7"},
+			expectedFormatterSubject: res("^Kubernetes v1.13.10 ist für den 12.11.2010 geplant$"),
+			expectedFormatterMarkdown: res(
+				"^Unten ist ein Entwurf des Changelogs für v1.13.10.",
+				"bis zum \\*\\*05.11.2010\\*\\*",
+			),
+			expectedMailerSubject: res("^Kubernetes v1.13.10 ist für den 12.11.2010 geplant$"),
+		},
+		"when a CVE file is set, the CVEs are announced before the release notes": {
+			opts:               getOpts(func(o *opts) { o.CVEFile = "testdata/cves.yaml" }),
+			workspaceStatus:    map[string]string{"gitVersion": "v1.13.10-beta.0-16-g48844ef5e7"},
+			releaseNoterOutput: "some release notes content",
+			expectedFormatterMarkdown: res(
+				"(?s)## Changes by CVE.*### CVE-2020-8555: Half-Blind SSRF.*some release notes content",
+			),
+		},
+		"when the CVE file is invalid, the error bubbles up and the mail is never sent": {
+			opts:                              getOpts(func(o *opts) { o.CVEFile = "testdata/templates/mail-head.md" }),
+			workspaceStatus:                   map[string]string{"gitVersion": "v1.13.10-beta.0-16-g48844ef5e7"},
+			expectedErrMsg:                    "parsing CVE file",
+			expectedReleaseNoterNOTToBeCalled: true,
+			expectedMailerNOTToBeCalled:       true,
+			expectedFormatterNOTToBeCalled:    true,
+		},
 		"when getting the workspace status returns an error, the error bubbles up and the mail is never sent": {
 			workspaceErr:                      fmt.Errorf("git describe err"),
 			expectedErrMsg:                    "git describe err",
@@ -142,6 +180,7 @@ func TestAnnounce(t *testing.T) {
 				patch.KDevName, patch.KDevEmail,
 				patch.KDevAnnounceName, patch.KDevAnnounceEmail,
 			},
+			expectedSentVersion: "v1.13.10",
 		},
 		"when setting the recipients fails, the error bubbles up": {
 			workspaceStatus:             map[string]string{"gitVersion": "v1.13.10-beta.0-16-g48844ef5e7"},
@@ -193,16 +232,22 @@ func TestAnnounce(t *testing.T) {
 			ms.SetRecipientsReturns(tc.mailerSetRecipientsErr)
 			ms.SetSenderReturns(tc.mailerSetSenderErr)
 
+			sentVersion := ""
 			announcer := &patch.Announcer{
 				Opts:         tc.opts,
 				Workspace:    ws,
 				ReleaseNoter: rn,
 				MailSender:   ms,
 				Formatter:    f,
+				Sent: func(version string, recipients []string) error {
+					sentVersion = version
+					return nil
+				},
 			}
 
 			err := announcer.Run()
 			it.CheckErrSub(t, err, tc.expectedErrMsg)
+			require.Equal(t, tc.expectedSentVersion, sentVersion, "Announcer#Sent version")
 
 			require.Equal(t, 1, ws.StatusCallCount(), "Workspace#Status call count")
 
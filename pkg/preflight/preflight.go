@@ -0,0 +1,322 @@
+/*
+Copyright 2020 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package preflight
+
+import (
+	"context"
+	"fmt"
+	"sort"
+	"strconv"
+	"strings"
+
+	"cloud.google.com/go/storage"
+	"github.com/google/go-github/v29/github"
+	"github.com/pkg/errors"
+	"github.com/sirupsen/logrus"
+
+	"k8s.io/release/pkg/command"
+	"k8s.io/release/pkg/git"
+	kgithub "k8s.io/release/pkg/github"
+	"k8s.io/release/pkg/golang"
+	"k8s.io/release/pkg/testgrid"
+)
+
+// Check is a single preflight check
+type Check struct {
+	// Name describes the check in the report
+	Name string
+
+	// Run returns an error if the check did not pass
+	Run func() error
+}
+
+// Result is the outcome of a single Check
+type Result struct {
+	Name string
+	Err  error
+}
+
+// Run executes all checks, independent of failing ones, and returns their
+// results in the same order.
+func Run(checks []Check) []Result {
+	res := []Result{}
+	for _, check := range checks {
+		logrus.Infof("Checking %s", check.Name)
+		res = append(res, Result{Name: check.Name, Err: check.Run()})
+	}
+	return res
+}
+
+// Passed returns true if all results passed
+func Passed(results []Result) bool {
+	for _, result := range results {
+		if result.Err != nil {
+			return false
+		}
+	}
+	return true
+}
+
+// Tools checks that all `tools` are available in $PATH
+func Tools(tools ...string) Check {
+	return Check{
+		Name: fmt.Sprintf("required tools (%s)", strings.Join(tools, ", ")),
+		Run: func() error {
+			missing := []string{}
+			for _, tool := range tools {
+				if !command.Available(tool) {
+					missing = append(missing, tool)
+				}
+			}
+			if len(missing) > 0 {
+				return errors.Errorf("not found in $PATH: %s", strings.Join(missing, ", "))
+			}
+			return nil
+		},
+	}
+}
+
+// DiskSpace checks that at least `minGB` gigabytes are available on the
+// file system of `path`.
+// Replaces common::disk_space_check
+func DiskSpace(path string, minGB uint64) Check {
+	return Check{
+		Name: fmt.Sprintf("%d GB of disk space on %s", minGB, path),
+		Run: func() error {
+			res, err := command.New("df", "-Pk", path).RunSilentSuccessOutput()
+			if err != nil {
+				return errors.Wrapf(err, "getting disk space of %s", path)
+			}
+			availableKB, err := parseDfAvailable(res.Output())
+			if err != nil {
+				return err
+			}
+			if availableGB := availableKB / 1024 / 1024; availableGB < minGB {
+				return errors.Errorf("only %d GB available", availableGB)
+			}
+			return nil
+		},
+	}
+}
+
+// parseDfAvailable returns the available kilobytes from the POSIX output of
+// `df -Pk`
+func parseDfAvailable(output string) (uint64, error) {
+	lines := strings.Split(strings.TrimSpace(output), "\n")
+	if len(lines) < 2 {
+		return 0, errors.Errorf("unexpected df output: %q", output)
+	}
+	fields := strings.Fields(lines[len(lines)-1])
+	if len(fields) < 4 {
+		return 0, errors.Errorf("unexpected df output: %q", output)
+	}
+	available, err := strconv.ParseUint(fields[3], 10, 64)
+	if err != nil {
+		return 0, errors.Wrapf(err, "parsing available disk space %q", fields[3])
+	}
+	return available, nil
+}
+
+// GitClean checks that the repository at `repoPath` has no uncommitted
+// changes or untracked files.
+func GitClean(repoPath string) Check {
+	return Check{
+		Name: fmt.Sprintf("clean git state of %s", repoPath),
+		Run: func() error {
+			repo, err := git.OpenRepo(repoPath)
+			if err != nil {
+				return err
+			}
+			dirty, err := repo.IsDirty()
+			if err != nil {
+				return err
+			}
+			if dirty {
+				return errors.New("repository contains uncommitted changes")
+			}
+			return nil
+		},
+	}
+}
+
+// RateLimiter returns the current GitHub API rate limits, it is implemented
+// by *github.Client
+type RateLimiter interface {
+	RateLimits(ctx context.Context) (*github.RateLimits, *github.Response, error)
+}
+
+// GitHubRateLimit checks that at least `minRemaining` GitHub API requests are
+// left for the current rate limit window.
+func GitHubRateLimit(ctx context.Context, client RateLimiter, minRemaining int) Check {
+	return Check{
+		Name: fmt.Sprintf("%d remaining GitHub API requests", minRemaining),
+		Run: func() error {
+			limits, _, err := client.RateLimits(ctx)
+			if err != nil {
+				return errors.Wrap(err, "getting GitHub rate limits")
+			}
+			core := limits.GetCore()
+			if core == nil {
+				return errors.New("GitHub did not return a core rate limit")
+			}
+			if core.Remaining < minRemaining {
+				return errors.Errorf(
+					"only %d of %d requests remaining until %s",
+					core.Remaining, core.Limit, core.Reset.Time,
+				)
+			}
+			return nil
+		},
+	}
+}
+
+// GCSWritable checks that the current credentials are allowed to create
+// objects in the GCS bucket `name`.
+func GCSWritable(ctx context.Context, bucket *storage.BucketHandle, name string) Check {
+	return Check{
+		Name: fmt.Sprintf("write access to gs://%s", name),
+		Run: func() error {
+			const perm = "storage.objects.create"
+			perms, err := bucket.IAM().TestPermissions(ctx, []string{perm})
+			if err != nil {
+				return errors.Wrapf(err, "testing permissions on gs://%s", name)
+			}
+			if len(perms) != 1 {
+				return errors.Errorf("missing %s permission", perm)
+			}
+			return nil
+		},
+	}
+}
+
+// ParseWaivers parses release blocker waivers in the format
+// `<number>=<justification>`
+func ParseWaivers(waivers []string) (map[int]string, error) {
+	res := map[int]string{}
+	for _, waiver := range waivers {
+		parts := strings.SplitN(waiver, "=", 2)
+		number, err := strconv.Atoi(strings.TrimPrefix(parts[0], "#"))
+		if err != nil {
+			return nil, errors.Errorf("invalid waiver %q, must be <number>=<justification>", waiver)
+		}
+		if len(parts) != 2 || strings.TrimSpace(parts[1]) == "" {
+			return nil, errors.Errorf("waiver of #%d has no justification", number)
+		}
+		res[number] = strings.TrimSpace(parts[1])
+	}
+	return res, nil
+}
+
+// ReleaseBlockers checks that no issue or PR labeled with `label` is open in
+// the milestone. Blockers contained in `waivers` are only logged together
+// with the justification of their waiver and passed to `waived`, if not nil.
+func ReleaseBlockers(
+	ctx context.Context, client kgithub.Client,
+	owner, repo, milestone, label string, waivers map[int]string,
+	waived func(number int, justification string) error,
+) Check {
+	return Check{
+		Name: fmt.Sprintf("no open %s issues or PRs in %s", label, milestone),
+		Run: func() error {
+			blockers, err := kgithub.ReleaseBlockers(ctx, client, owner, repo, milestone, label)
+			if err != nil {
+				return err
+			}
+			unwaived := []string{}
+			for i := range blockers {
+				number := blockers[i].GetNumber()
+				if justification, ok := waivers[number]; ok {
+					logrus.Warnf(
+						"Release blocker #%d (%s) waived: %s",
+						number, blockers[i].GetTitle(), justification,
+					)
+					if waived != nil {
+						if err := waived(number, justification); err != nil {
+							return err
+						}
+					}
+					continue
+				}
+				unwaived = append(unwaived, fmt.Sprintf("#%d", number))
+			}
+			if len(unwaived) > 0 {
+				sort.Strings(unwaived)
+				return errors.Errorf("open release blockers: %s", strings.Join(unwaived, ", "))
+			}
+			return nil
+		},
+	}
+}
+
+// CISignal checks that no tab of the Testgrid `dashboard` is failing. If
+// `commit` is not empty, the most recent run of the commit has to be passed
+// in every tab instead, independent of the latest results.
+func CISignal(testgridURL, dashboard, commit string) Check {
+	name := fmt.Sprintf("green CI signal on %s", dashboard)
+	if commit != "" {
+		name += " for " + commit
+	}
+	return Check{
+		Name: name,
+		Run: func() error {
+			summary, err := testgrid.DashboardSummary(testgridURL, dashboard)
+			if err != nil {
+				return err
+			}
+			if commit == "" {
+				if failing := summary.Failing(); len(failing) > 0 {
+					return errors.Errorf("failing jobs: %s", strings.Join(failing, ", "))
+				}
+				return nil
+			}
+
+			tabs := []string{}
+			for tab := range summary {
+				tabs = append(tabs, tab)
+			}
+			sort.Strings(tabs)
+
+			failing := []string{}
+			for _, tab := range tabs {
+				table, err := testgrid.DashboardTab(testgridURL, dashboard, tab)
+				if err != nil {
+					return err
+				}
+				result, found := table.CommitResult(commit)
+				if !found {
+					failing = append(failing, tab+" (no run)")
+				} else if !testgrid.Passed(result) {
+					failing = append(failing, tab)
+				}
+			}
+			if len(failing) > 0 {
+				return errors.Errorf(
+					"jobs not passed for %s: %s", commit, strings.Join(failing, ", "),
+				)
+			}
+			return nil
+		},
+	}
+}
+
+// GoVersion checks that the host Go toolchain matches the pinned `version`
+func GoVersion(version string) Check {
+	return Check{
+		Name: fmt.Sprintf("Go toolchain %s", golang.NormalizeVersion(version)),
+		Run:  golang.NewToolchain(version).VerifyHost,
+	}
+}
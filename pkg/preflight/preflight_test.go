@@ -0,0 +1,281 @@
+/*
+Copyright 2020 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package preflight
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/google/go-github/v29/github"
+	"github.com/pkg/errors"
+	"github.com/stretchr/testify/require"
+
+	kgithub "k8s.io/release/pkg/github"
+	"k8s.io/release/pkg/testgrid"
+)
+
+func TestRun(t *testing.T) {
+	results := Run([]Check{
+		{Name: "first", Run: func() error { return nil }},
+		{Name: "second", Run: func() error { return errors.New("failed") }},
+		{Name: "third", Run: func() error { return nil }},
+	})
+	require.Len(t, results, 3)
+	require.Equal(t, "first", results[0].Name)
+	require.Nil(t, results[0].Err)
+	require.Equal(t, "second", results[1].Name)
+	require.NotNil(t, results[1].Err)
+	require.Equal(t, "third", results[2].Name)
+	require.Nil(t, results[2].Err)
+	require.False(t, Passed(results))
+	require.True(t, Passed(results[2:]))
+}
+
+func TestTools(t *testing.T) {
+	require.Nil(t, Tools("sh").Run())
+	require.NotNil(t, Tools("sh", "not-existing-tool").Run())
+}
+
+func TestParseDfAvailable(t *testing.T) {
+	for name, tc := range map[string]struct {
+		output      string
+		expected    uint64
+		shouldError bool
+	}{
+		"success": {
+			output: `Filesystem     1024-blocks      Used Available Capacity Mounted on
+/dev/sda1        490691512 123456789 342277140      27% /
+`,
+			expected: 342277140,
+		},
+		"failure no data line": {
+			output:      "Filesystem     1024-blocks      Used Available Capacity Mounted on\n",
+			shouldError: true,
+		},
+		"failure too few fields": {
+			output:      "Filesystem\n/dev/sda1 1\n",
+			shouldError: true,
+		},
+		"failure not a number": {
+			output:      "Filesystem\n/dev/sda1 1 2 wrong 27% /\n",
+			shouldError: true,
+		},
+	} {
+		t.Run(name, func(t *testing.T) {
+			res, err := parseDfAvailable(tc.output)
+			if tc.shouldError {
+				require.NotNil(t, err)
+			} else {
+				require.Nil(t, err)
+				require.Equal(t, tc.expected, res)
+			}
+		})
+	}
+}
+
+type fakeRateLimiter struct {
+	limits *github.RateLimits
+	err    error
+}
+
+func (f *fakeRateLimiter) RateLimits(ctx context.Context) (*github.RateLimits, *github.Response, error) {
+	return f.limits, &github.Response{}, f.err
+}
+
+func TestGitHubRateLimit(t *testing.T) {
+	reset := github.Timestamp{Time: time.Now().Add(time.Hour)}
+	for name, tc := range map[string]struct {
+		client      *fakeRateLimiter
+		shouldError bool
+	}{
+		"success": {
+			client: &fakeRateLimiter{limits: &github.RateLimits{
+				Core: &github.Rate{Limit: 5000, Remaining: 1000, Reset: reset},
+			}},
+		},
+		"failure budget too low": {
+			client: &fakeRateLimiter{limits: &github.RateLimits{
+				Core: &github.Rate{Limit: 5000, Remaining: 999, Reset: reset},
+			}},
+			shouldError: true,
+		},
+		"failure no core rate limit": {
+			client:      &fakeRateLimiter{limits: &github.RateLimits{}},
+			shouldError: true,
+		},
+		"failure API error": {
+			client:      &fakeRateLimiter{err: errors.New("unauthorized")},
+			shouldError: true,
+		},
+	} {
+		t.Run(name, func(t *testing.T) {
+			err := GitHubRateLimit(context.Background(), tc.client, 1000).Run()
+			if tc.shouldError {
+				require.NotNil(t, err)
+			} else {
+				require.Nil(t, err)
+			}
+		})
+	}
+}
+
+func TestParseWaivers(t *testing.T) {
+	for name, tc := range map[string]struct {
+		waivers     []string
+		expected    map[int]string
+		shouldError bool
+	}{
+		"success": {
+			waivers:  []string{"123=fixed in the next patch release", "#456= not a regression "},
+			expected: map[int]string{123: "fixed in the next patch release", 456: "not a regression"},
+		},
+		"success empty": {
+			waivers:  []string{},
+			expected: map[int]string{},
+		},
+		"failure no justification": {
+			waivers:     []string{"123"},
+			shouldError: true,
+		},
+		"failure empty justification": {
+			waivers:     []string{"123= "},
+			shouldError: true,
+		},
+		"failure invalid number": {
+			waivers:     []string{"abc=reason"},
+			shouldError: true,
+		},
+	} {
+		t.Run(name, func(t *testing.T) {
+			res, err := ParseWaivers(tc.waivers)
+			if tc.shouldError {
+				require.NotNil(t, err)
+			} else {
+				require.Nil(t, err)
+				require.Equal(t, tc.expected, res)
+			}
+		})
+	}
+}
+
+type fakeIssueSearcher struct {
+	kgithub.Client
+	issues []github.Issue
+}
+
+func (f *fakeIssueSearcher) SearchIssues(ctx context.Context, query string, opts *github.SearchOptions) (*github.IssuesSearchResult, *github.Response, error) {
+	return &github.IssuesSearchResult{Issues: f.issues}, &github.Response{}, nil
+}
+
+func TestReleaseBlockers(t *testing.T) {
+	client := &fakeIssueSearcher{issues: []github.Issue{
+		{Number: github.Int(1), Title: github.String("first")},
+		{Number: github.Int(2), Title: github.String("second")},
+	}}
+
+	for name, tc := range map[string]struct {
+		waivers     map[int]string
+		waived      []int
+		shouldError bool
+	}{
+		"success all waived": {
+			waivers: map[int]string{1: "reason", 2: "reason"},
+			waived:  []int{1, 2},
+		},
+		"failure one not waived": {
+			waivers:     map[int]string{1: "reason"},
+			waived:      []int{1},
+			shouldError: true,
+		},
+		"failure none waived": {
+			waivers:     map[int]string{},
+			waived:      []int{},
+			shouldError: true,
+		},
+	} {
+		t.Run(name, func(t *testing.T) {
+			waived := []int{}
+			err := ReleaseBlockers(
+				context.Background(), client, "owner", "repo", "v1.19",
+				kgithub.DefaultReleaseBlockerLabel, tc.waivers,
+				func(number int, justification string) error {
+					require.Equal(t, tc.waivers[number], justification)
+					waived = append(waived, number)
+					return nil
+				},
+			).Run()
+			if tc.shouldError {
+				require.NotNil(t, err)
+			} else {
+				require.Nil(t, err)
+			}
+			require.Equal(t, tc.waived, waived)
+		})
+	}
+
+	require.NotNil(t, ReleaseBlockers(
+		context.Background(), client, "owner", "repo", "v1.19",
+		kgithub.DefaultReleaseBlockerLabel, map[int]string{1: "reason", 2: "reason"},
+		func(int, string) error { return errors.New("recording failed") },
+	).Run())
+
+	require.Nil(t, ReleaseBlockers(
+		context.Background(), &fakeIssueSearcher{}, "owner", "repo", "v1.19",
+		kgithub.DefaultReleaseBlockerLabel, nil, nil,
+	).Run())
+}
+
+func TestCISignal(t *testing.T) {
+	status := "PASSING"
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		fmt.Fprintf(w, `{"verify-1.18": {"overall_status": %q}}`, status)
+	}))
+	defer server.Close()
+
+	check := CISignal(server.URL, "sig-release-1.18-blocking", "")
+	require.Nil(t, check.Run())
+
+	status = "FAILING"
+	require.NotNil(t, check.Run())
+}
+
+func TestCISignalCommit(t *testing.T) {
+	result := testgrid.ResultPass
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path == "/sig-release-1.18-blocking/summary" {
+			fmt.Fprint(w, `{"verify-1.18": {"overall_status": "FAILING"}}`)
+			return
+		}
+		fmt.Fprintf(w, `{
+			"changelists": ["1002", "1001"],
+			"custom-columns": [["fedcba987"], ["abcdef012"]],
+			"tests": [{"name": "Overall", "statuses": [{"count": 1, "value": 12}, {"count": 1, "value": %d}]}]
+		}`, result)
+	}))
+	defer server.Close()
+
+	require.Nil(t, CISignal(server.URL, "sig-release-1.18-blocking", "abcdef0123").Run())
+	require.NotNil(t, CISignal(server.URL, "sig-release-1.18-blocking", "fedcba9876").Run())
+	require.NotNil(t, CISignal(server.URL, "sig-release-1.18-blocking", "0123456789").Run())
+
+	result = testgrid.ResultFail
+	require.NotNil(t, CISignal(server.URL, "sig-release-1.18-blocking", "abcdef0123").Run())
+}
@@ -0,0 +1,309 @@
+/*
+Copyright 2020 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package release
+
+import (
+	"encoding/json"
+	"io"
+	"io/ioutil"
+	"os"
+	"path"
+	"path/filepath"
+	"regexp"
+	"strings"
+
+	"github.com/pkg/errors"
+	"github.com/sirupsen/logrus"
+
+	"k8s.io/release/pkg/command"
+	"k8s.io/release/pkg/util"
+)
+
+const (
+	// ImagesPath is the directory where the release image tarballs are
+	// created.
+	ImagesPath = "release-images"
+
+	// BundleImagesPath is the directory of the image tarballs within a bundle
+	BundleImagesPath = "images"
+
+	// BundleReleasePath is the directory of the staged release within a
+	// bundle
+	BundleReleasePath = "release"
+
+	// BundleManifestFile is the manifest listing every file of a bundle
+	BundleManifestFile = "bundle-manifest.json"
+)
+
+// loadedImageRegex matches the images reported by `docker load`
+var loadedImageRegex = regexp.MustCompile(`(?m)^Loaded image: (\S+)$`)
+
+// LocalOpen returns an OpenFunc for the release files in the local directory
+// `dir`. Names outside of `dir` cannot be opened.
+func LocalOpen(dir string) OpenFunc {
+	return func(name string) (io.ReadCloser, error) {
+		file, err := util.JoinWithin(dir, name)
+		if err != nil {
+			return nil, err
+		}
+		return os.Open(file)
+	}
+}
+
+// CreateBundle packs the release staged by `krel push` in `buildDir`
+// together with the release images into the single tarball `bundlePath`.
+// The staged release is bundled unmodified, including its signed manifest,
+// checksums and signatures, which is why it has to be staged with
+// --gpg-sign. The bundle manifest lists every file of the bundle, including
+// the images, and the bundle gets a detached signature of the GPG key
+// `keyID` next to it. Both are used by ExtractBundle to verify the bundle
+// before it gets installed.
+func CreateBundle(buildDir, version, bundlePath, keyID string) error {
+	stagePath := filepath.Join(buildDir, GCSStagePath)
+	staged, err := ReadManifest(LocalOpen(stagePath))
+	if err != nil {
+		return errors.Wrapf(err, "reading staged release in %s", stagePath)
+	}
+	if staged.Version != version {
+		return errors.Errorf(
+			"staged release is version %s, not %s", staged.Version, version,
+		)
+	}
+	if !util.Exists(filepath.Join(stagePath, ManifestFile+signatureExtension)) {
+		return errors.Errorf(
+			"staged release %s is not signed, push it with --gpg-sign", version,
+		)
+	}
+
+	tmpDir, err := ioutil.TempDir("", "release-bundle-")
+	if err != nil {
+		return errors.Wrap(err, "creating temp dir")
+	}
+	defer os.RemoveAll(tmpDir)
+
+	logrus.Infof("Adding staged release %s to the bundle", stagePath)
+	if err := util.CopyDirContentsLocal(
+		stagePath, filepath.Join(tmpDir, BundleReleasePath),
+	); err != nil {
+		return errors.Wrapf(err, "copying %s", stagePath)
+	}
+
+	imagesPath := filepath.Join(buildDir, ImagesPath)
+	if util.Exists(imagesPath) {
+		logrus.Infof("Adding release images %s to the bundle", imagesPath)
+		if err := util.CopyDirContentsLocal(
+			imagesPath, filepath.Join(tmpDir, BundleImagesPath),
+		); err != nil {
+			return errors.Wrapf(err, "copying %s", imagesPath)
+		}
+	} else {
+		logrus.Warnf("No release images found in %s", imagesPath)
+	}
+
+	if err := writeBundleManifest(tmpDir, version, staged.Location); err != nil {
+		return err
+	}
+
+	logrus.Infof("Writing bundle %s", bundlePath)
+	if err := command.New(
+		"tar", "-czf", bundlePath, "-C", tmpDir, ".",
+	).RunSilentSuccess(); err != nil {
+		return errors.Wrapf(err, "creating bundle %s", bundlePath)
+	}
+	return SignFile(bundlePath, keyID)
+}
+
+// writeBundleManifest writes the bundle manifest into `dir` for all files
+// found recursively within it, including checksum and signature files
+func writeBundleManifest(dir, version, location string) error {
+	files, err := findFilesWithSuffix(dir, "")
+	if err != nil {
+		return errors.Wrapf(err, "finding files in %s", dir)
+	}
+
+	manifest := &Manifest{
+		Version:   version,
+		Location:  location,
+		Artifacts: []ManifestArtifact{},
+	}
+	for _, file := range files {
+		artifact, err := manifestArtifact(dir, file, location, false, Digests{})
+		if err != nil {
+			return err
+		}
+		// The bundled files are not published at the location of the
+		// release and the signatures are listed on their own
+		artifact.URL = ""
+		artifact.Signature = ""
+		manifest.Artifacts = append(manifest.Artifacts, *artifact)
+	}
+
+	content, err := json.MarshalIndent(manifest, "", "  ")
+	if err != nil {
+		return errors.Wrap(err, "marshalling bundle manifest")
+	}
+	manifestPath := filepath.Join(dir, BundleManifestFile)
+	if err := ioutil.WriteFile(manifestPath, content, os.FileMode(0644)); err != nil {
+		return errors.Wrapf(err, "writing %s", manifestPath)
+	}
+	return nil
+}
+
+// ExtractBundle verifies the signature of the bundle at `bundlePath` with
+// the keys of `keyring`, extracts it into `dir` and verifies all files
+// against the bundle manifest. The staged release in BundleReleasePath is
+// verified again like `krel verify` does, including the signatures of its
+// manifest, checksums and artifacts. The manifest of the staged release is
+// returned if all verifications passed.
+func ExtractBundle(bundlePath, dir, keyring string) (*Manifest, error) {
+	if keyring == "" {
+		return nil, errors.New("verifying a bundle requires a keyring")
+	}
+	if !util.Exists(bundlePath + signatureExtension) {
+		return nil, errors.Errorf("bundle signature %s does not exist", bundlePath+signatureExtension)
+	}
+	if err := VerifySignature(bundlePath, keyring); err != nil {
+		return nil, err
+	}
+
+	logrus.Infof("Extracting bundle %s to %s", bundlePath, dir)
+	if err := command.New(
+		"tar", "-xzf", bundlePath, "-C", dir,
+	).RunSilentSuccess(); err != nil {
+		return nil, errors.Wrapf(err, "extracting bundle %s", bundlePath)
+	}
+
+	bundle, err := readManifestFile(LocalOpen(dir), BundleManifestFile)
+	if err != nil {
+		return nil, err
+	}
+	if err := verifyBundleFiles(bundle, dir); err != nil {
+		return nil, err
+	}
+
+	releaseDir := filepath.Join(dir, BundleReleasePath)
+	staged, err := ReadManifest(LocalOpen(releaseDir))
+	if err != nil {
+		return nil, errors.Wrap(err, "reading bundled release manifest")
+	}
+	if staged.Version != bundle.Version {
+		return nil, errors.Errorf(
+			"bundled release is version %s, not %s", staged.Version, bundle.Version,
+		)
+	}
+	results, err := VerifyManifest(staged, releaseDir, keyring)
+	if err != nil {
+		return nil, err
+	}
+	failed := []string{}
+	for i := range results {
+		if !results[i].Passed() {
+			failed = append(failed, results[i].Name)
+		}
+	}
+	if len(failed) > 0 {
+		return nil, errors.Errorf(
+			"bundled release verification failed for: %s", strings.Join(failed, ", "),
+		)
+	}
+
+	logrus.Infof(
+		"Verified %d files of bundle %s", len(bundle.Artifacts), bundle.Version,
+	)
+	return staged, nil
+}
+
+// verifyBundleFiles checks that the files extracted into `dir` are exactly
+// the ones listed in the bundle manifest, with the recorded size and hashes.
+// This covers the images, which are not part of the staged release.
+func verifyBundleFiles(bundle *Manifest, dir string) error {
+	listed := map[string]bool{BundleManifestFile: true}
+	failed := []string{}
+	for i := range bundle.Artifacts {
+		listed[bundle.Artifacts[i].Name] = true
+		if res := verifyArtifact(&bundle.Artifacts[i], dir, "", nil); !res.Passed() {
+			failed = append(failed, res.Name)
+		}
+	}
+
+	files, err := findFilesWithSuffix(dir, "")
+	if err != nil {
+		return errors.Wrapf(err, "finding files in %s", dir)
+	}
+	for _, file := range files {
+		rel, err := filepath.Rel(dir, file)
+		if err != nil {
+			return err
+		}
+		if !listed[filepath.ToSlash(rel)] {
+			failed = append(failed, filepath.ToSlash(rel)+" (not listed)")
+		}
+	}
+
+	if len(failed) > 0 {
+		return errors.Errorf(
+			"bundle verification failed for: %s", strings.Join(failed, ", "),
+		)
+	}
+	return nil
+}
+
+// BundleImages returns the image tarballs of the bundle extracted into
+// `dir`.
+func BundleImages(dir string) ([]string, error) {
+	imagesPath := filepath.Join(dir, BundleImagesPath)
+	if !util.Exists(imagesPath) {
+		return []string{}, nil
+	}
+	return findFilesWithSuffix(imagesPath, ".tar")
+}
+
+// LoadImage loads the image tarball into the local docker daemon and returns
+// the loaded images.
+func LoadImage(tarball string) ([]string, error) {
+	output, err := command.New("docker", "load", "-i", tarball).RunSilentSuccessOutput()
+	if err != nil {
+		return nil, errors.Wrapf(err, "loading image %s", tarball)
+	}
+	images := parseLoadedImages(output.Output())
+	if len(images) == 0 {
+		return nil, errors.Errorf("no tagged image found in %s", tarball)
+	}
+	return images, nil
+}
+
+func parseLoadedImages(output string) []string {
+	images := []string{}
+	for _, match := range loadedImageRegex.FindAllStringSubmatch(output, -1) {
+		images = append(images, match[1])
+	}
+	return images
+}
+
+// PushImage retags the local `image` into `registry` and pushes it. The
+// pushed image is returned.
+func PushImage(image, registry string) (string, error) {
+	target := strings.TrimSuffix(registry, "/") + "/" + path.Base(image)
+	logrus.Infof("Pushing image %s as %s", image, target)
+	if err := command.New("docker", "tag", image, target).RunSilentSuccess(); err != nil {
+		return "", errors.Wrapf(err, "tagging image %s", image)
+	}
+	if err := command.New("docker", "push", target).RunSilentSuccess(); err != nil {
+		return "", errors.Wrapf(err, "pushing image %s", target)
+	}
+	return target, nil
+}
@@ -0,0 +1,146 @@
+/*
+Copyright 2020 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package release
+
+import (
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"sort"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+
+	"k8s.io/release/pkg/command"
+	"k8s.io/release/pkg/util"
+)
+
+func writeTestBuildDir(t *testing.T, buildDir string) {
+	stagePath := filepath.Join(buildDir, GCSStagePath)
+	imagesPath := filepath.Join(buildDir, ImagesPath, "amd64")
+	for _, dir := range []string{stagePath, imagesPath} {
+		require.Nil(t, os.MkdirAll(dir, os.FileMode(0755)))
+	}
+	for _, file := range []string{
+		filepath.Join(stagePath, "kubernetes.tar.gz"),
+		filepath.Join(imagesPath, "kube-apiserver.tar"),
+	} {
+		require.Nil(t, ioutil.WriteFile(file, []byte("test"), os.FileMode(0644)))
+	}
+	require.Nil(t, WriteManifest(stagePath, "v1.18.0", "gs://bucket/release/v1.18.0", true, Digests{}))
+	require.Nil(t, WriteChecksums(stagePath, Digests{}))
+}
+
+func TestBundle(t *testing.T) {
+	if !command.Available(GPGExecutable) {
+		t.Skipf("%s is not available", GPGExecutable)
+	}
+
+	baseTmpDir, err := ioutil.TempDir("", "")
+	require.Nil(t, err)
+	defer cleanupTmps(t, baseTmpDir)
+
+	keyring, cleanup := newTestKeyring(t, baseTmpDir)
+	defer cleanup()
+
+	buildDir := filepath.Join(baseTmpDir, "build")
+	writeTestBuildDir(t, buildDir)
+
+	// Only signed releases can be bundled
+	bundlePath := filepath.Join(baseTmpDir, "bundle.tar.gz")
+	require.NotNil(t, CreateBundle(buildDir, "v1.18.0", bundlePath, ""))
+	require.Nil(t, SignArtifacts(filepath.Join(buildDir, GCSStagePath), ""))
+
+	require.NotNil(t, CreateBundle(buildDir, "v1.19.0", bundlePath, ""))
+	require.Nil(t, CreateBundle(buildDir, "v1.18.0", bundlePath, ""))
+	require.True(t, util.Exists(bundlePath+signatureExtension))
+
+	extract := func(name string) (*Manifest, string, error) {
+		dir := filepath.Join(baseTmpDir, name)
+		require.Nil(t, os.Mkdir(dir, os.FileMode(0755)))
+		manifest, err := ExtractBundle(bundlePath, dir, keyring)
+		return manifest, dir, err
+	}
+
+	_, err = ExtractBundle(bundlePath, baseTmpDir, "")
+	require.NotNil(t, err)
+
+	manifest, extractDir, err := extract("extract")
+	require.Nil(t, err)
+	require.Equal(t, "v1.18.0", manifest.Version)
+	require.Equal(t, "gs://bucket/release/v1.18.0", manifest.Location)
+
+	// The staged release is bundled unmodified
+	names := []string{}
+	for _, artifact := range manifest.Artifacts {
+		names = append(names, artifact.Name)
+	}
+	sort.Strings(names)
+	require.Equal(t, []string{"kubernetes.tar.gz"}, names)
+
+	bundle, err := readManifestFile(LocalOpen(extractDir), BundleManifestFile)
+	require.Nil(t, err)
+	names = []string{}
+	for _, artifact := range bundle.Artifacts {
+		names = append(names, artifact.Name)
+	}
+	require.Contains(t, names, "images/amd64/kube-apiserver.tar")
+	require.Contains(t, names, "release/"+ManifestFile+signatureExtension)
+
+	images, err := BundleImages(extractDir)
+	require.Nil(t, err)
+	require.Equal(t, []string{
+		filepath.Join(extractDir, BundleImagesPath, "amd64", "kube-apiserver.tar"),
+	}, images)
+
+	// A repacked bundle does not match its signature
+	require.Nil(t, ioutil.WriteFile(
+		images[0], []byte("modified"), os.FileMode(0644),
+	))
+	require.Nil(t, command.New(
+		"tar", "-czf", bundlePath, "-C", extractDir, ".",
+	).RunSilentSuccess())
+	_, _, err = extract("tampered")
+	require.NotNil(t, err)
+
+	// Re-signed bundles are checked against the bundle manifest
+	require.Nil(t, SignFile(bundlePath, ""))
+	_, _, err = extract("resigned")
+	require.NotNil(t, err)
+	require.Contains(t, err.Error(), "kube-apiserver.tar")
+
+	// A missing signature fails the verification
+	require.Nil(t, os.Remove(bundlePath+signatureExtension))
+	_, _, err = extract("unsigned")
+	require.NotNil(t, err)
+}
+
+func TestBundleImagesNoImages(t *testing.T) {
+	images, err := BundleImages("/not/existing")
+	require.Nil(t, err)
+	require.Empty(t, images)
+}
+
+func TestParseLoadedImages(t *testing.T) {
+	require.Equal(t, []string{
+		"k8s.gcr.io/kube-apiserver-amd64:v1.18.0",
+		"k8s.gcr.io/kube-apiserver:v1.18.0",
+	}, parseLoadedImages(`Loaded image: k8s.gcr.io/kube-apiserver-amd64:v1.18.0
+Loaded image: k8s.gcr.io/kube-apiserver:v1.18.0
+`))
+	require.Empty(t, parseLoadedImages("Loaded image ID: sha256:abc\n"))
+}
@@ -0,0 +1,178 @@
+/*
+Copyright 2020 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package release
+
+import (
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"regexp"
+	"strings"
+
+	"github.com/pkg/errors"
+	"github.com/sirupsen/logrus"
+	"sigs.k8s.io/yaml"
+
+	"k8s.io/release/pkg/command"
+	"k8s.io/release/pkg/util"
+)
+
+const (
+	// HelmExecutable is the name of the Helm executable
+	HelmExecutable = "helm"
+
+	// ChartRepositoryIndex is the index file of a chart repository
+	ChartRepositoryIndex = "index.yaml"
+
+	chartValuesFile = "values.yaml"
+)
+
+// chartPackageRegex matches the path of the packaged chart in the output of
+// `helm package`
+var chartPackageRegex = regexp.MustCompile(`(?m)saved it to: (\S+\.tgz)\s*$`)
+
+// ChartOptions are the settings used to package a Helm chart
+type ChartOptions struct {
+	// Version is the release version, like v1.18.0. It becomes the
+	// appVersion of the chart, while the chart version is the same
+	// version without the leading "v".
+	Version string
+
+	// PinnedImages replace the `images` of the chart values, if set
+	PinnedImages []PinnedImage
+
+	// Sign creates a provenance file next to the package, using the GPG Key
+	// of the Keyring
+	Sign    bool
+	Key     string
+	Keyring string
+}
+
+// PackageChart packages the chart in `chartDir` with the release version into
+// `outputDir` and returns the path of the package. The chart directory is
+// not modified, the images are pinned within a copy of it.
+func PackageChart(chartDir, outputDir string, opts *ChartOptions) (string, error) {
+	tempDir, err := ioutil.TempDir("", "chart-")
+	if err != nil {
+		return "", errors.Wrap(err, "creating temp directory")
+	}
+	defer os.RemoveAll(tempDir)
+
+	chart := filepath.Join(tempDir, filepath.Base(chartDir))
+	if err := util.CopyDirContentsLocal(chartDir, chart); err != nil {
+		return "", errors.Wrapf(err, "copying chart %s", chartDir)
+	}
+	if len(opts.PinnedImages) > 0 {
+		if err := SetChartImages(chart, opts.PinnedImages); err != nil {
+			return "", err
+		}
+	}
+
+	args := []string{
+		"package", chart,
+		"--destination", outputDir,
+		"--version", util.TrimTagPrefix(opts.Version),
+		"--app-version", opts.Version,
+	}
+	if opts.Sign {
+		args = append(args, "--sign", "--key", opts.Key)
+		if opts.Keyring != "" {
+			args = append(args, "--keyring", opts.Keyring)
+		}
+	}
+
+	logrus.Infof("Packaging chart %s as %s", chartDir, opts.Version)
+	output, err := command.New(HelmExecutable, args...).RunSilentSuccessOutput()
+	if err != nil {
+		return "", errors.Wrapf(err, "packaging chart %s", chartDir)
+	}
+	return parseChartPackage(output.Output())
+}
+
+// SetChartImages replaces the image references of the chart values by the
+// pinned images. The images are set below the top level key `images`, in the
+// same format as HelmValues.
+func SetChartImages(chartDir string, pinned []PinnedImage) error {
+	valuesFile := filepath.Join(chartDir, chartValuesFile)
+	values := map[string]interface{}{}
+	if util.Exists(valuesFile) {
+		content, err := ioutil.ReadFile(valuesFile)
+		if err != nil {
+			return errors.Wrapf(err, "reading %s", valuesFile)
+		}
+		if err := yaml.Unmarshal(content, &values); err != nil {
+			return errors.Wrapf(err, "decoding %s", valuesFile)
+		}
+	}
+
+	images, ok := values["images"].(map[string]interface{})
+	if !ok {
+		images = map[string]interface{}{}
+	}
+	for i := range pinned {
+		images[helmKey(pinned[i].Name)] = map[string]interface{}{
+			"repository": pinned[i].Repository,
+			"tag":        pinned[i].Tag,
+			"digest":     pinned[i].Digest,
+		}
+	}
+	values["images"] = images
+
+	content, err := yaml.Marshal(values)
+	if err != nil {
+		return errors.Wrapf(err, "encoding %s", valuesFile)
+	}
+	if err := ioutil.WriteFile(valuesFile, content, os.FileMode(0644)); err != nil {
+		return errors.Wrapf(err, "writing %s", valuesFile)
+	}
+	return nil
+}
+
+// PushChart pushes the chart package to the OCI `registry`, like
+// gcr.io/k8s-staging-charts
+func PushChart(chartPackage, registry string) error {
+	ref := "oci://" + strings.TrimPrefix(strings.TrimSuffix(registry, "/"), "oci://")
+	logrus.Infof("Pushing chart %s to %s", chartPackage, ref)
+	if err := command.New(
+		HelmExecutable, "push", chartPackage, ref,
+	).RunSilentSuccess(); err != nil {
+		return errors.Wrapf(err, "pushing chart %s", chartPackage)
+	}
+	return nil
+}
+
+// IndexChartRepository writes the chart repository index of all packages in
+// `dir`, which are served from `url`. If `existingIndex` is not empty, the
+// entries of that index are kept.
+func IndexChartRepository(dir, url, existingIndex string) error {
+	args := []string{"repo", "index", dir, "--url", url}
+	if existingIndex != "" {
+		args = append(args, "--merge", existingIndex)
+	}
+	if err := command.New(HelmExecutable, args...).RunSilentSuccess(); err != nil {
+		return errors.Wrapf(err, "indexing chart repository %s", dir)
+	}
+	return nil
+}
+
+func parseChartPackage(output string) (string, error) {
+	match := chartPackageRegex.FindStringSubmatch(output)
+	if match == nil {
+		return "", errors.Errorf("no chart package found in output %q", output)
+	}
+	return match[1], nil
+}
@@ -0,0 +1,115 @@
+/*
+Copyright 2020 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package release
+
+import (
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+	"sigs.k8s.io/yaml"
+)
+
+func TestParseChartPackage(t *testing.T) {
+	for name, tc := range map[string]struct {
+		output      string
+		expected    string
+		shouldError bool
+	}{
+		"success": {
+			output:   "Successfully packaged chart and saved it to: /tmp/out/kubernetes-1.18.0.tgz\n",
+			expected: "/tmp/out/kubernetes-1.18.0.tgz",
+		},
+		"success with warnings": {
+			output: "walk.go:74: found symbolic link in path\n" +
+				"Successfully packaged chart and saved it to: /tmp/out/kubernetes-1.18.0.tgz",
+			expected: "/tmp/out/kubernetes-1.18.0.tgz",
+		},
+		"failure no package": {
+			output:      "Error: chart metadata (Chart.yaml) missing\n",
+			shouldError: true,
+		},
+	} {
+		t.Run(name, func(t *testing.T) {
+			res, err := parseChartPackage(tc.output)
+			if tc.shouldError {
+				require.NotNil(t, err)
+			} else {
+				require.Nil(t, err)
+				require.Equal(t, tc.expected, res)
+			}
+		})
+	}
+}
+
+func TestSetChartImages(t *testing.T) {
+	dir, err := ioutil.TempDir("", "chart-")
+	require.Nil(t, err)
+	defer os.RemoveAll(dir)
+
+	valuesFile := filepath.Join(dir, chartValuesFile)
+	require.Nil(t, ioutil.WriteFile(valuesFile, []byte(
+		`{"replicas": 3, "images": {"conformance": {"tag": "v1.17.0"}, "other": {"tag": "v1"}}}`,
+	), os.FileMode(0644)))
+
+	require.Nil(t, SetChartImages(dir, testPinnedImages))
+
+	content, err := ioutil.ReadFile(valuesFile)
+	require.Nil(t, err)
+	values := map[string]interface{}{}
+	require.Nil(t, yaml.Unmarshal(content, &values))
+	require.Equal(t, map[string]interface{}{
+		"replicas": float64(3),
+		"images": map[string]interface{}{
+			"kubeApiserver": map[string]interface{}{
+				"repository": "gcr.io/a/kube-apiserver",
+				"tag":        "v1.18.0",
+				"digest":     "sha256:111",
+			},
+			"conformance": map[string]interface{}{
+				"repository": "gcr.io/a/conformance",
+				"tag":        "v1.18.0",
+				"digest":     "sha256:222",
+			},
+			"other": map[string]interface{}{"tag": "v1"},
+		},
+	}, values)
+}
+
+func TestSetChartImagesNoValues(t *testing.T) {
+	dir, err := ioutil.TempDir("", "chart-")
+	require.Nil(t, err)
+	defer os.RemoveAll(dir)
+
+	require.Nil(t, SetChartImages(dir, testPinnedImages[:1]))
+
+	content, err := ioutil.ReadFile(filepath.Join(dir, chartValuesFile))
+	require.Nil(t, err)
+	values := map[string]interface{}{}
+	require.Nil(t, yaml.Unmarshal(content, &values))
+	require.Equal(t, map[string]interface{}{
+		"images": map[string]interface{}{
+			"kubeApiserver": map[string]interface{}{
+				"repository": "gcr.io/a/kube-apiserver",
+				"tag":        "v1.18.0",
+				"digest":     "sha256:111",
+			},
+		},
+	}, values)
+}
@@ -0,0 +1,169 @@
+/*
+Copyright 2020 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package release
+
+import (
+	"fmt"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"github.com/pkg/errors"
+	"github.com/sirupsen/logrus"
+
+	"k8s.io/release/pkg/util"
+)
+
+// checksumAlgorithm is a hash algorithm used for release artifact checksums.
+type checksumAlgorithm struct {
+	bits   int
+	digest func(*util.FileDigests) string
+}
+
+// sumsFile returns the name of the consolidated checksum file, for example
+// SHA256SUMS.
+func (c checksumAlgorithm) sumsFile() string {
+	return fmt.Sprintf("SHA%dSUMS", c.bits)
+}
+
+// extension returns the extension of a single file checksum, for example
+// `.sha256`.
+func (c checksumAlgorithm) extension() string {
+	return fmt.Sprintf(".sha%d", c.bits)
+}
+
+var checksumAlgorithms = []checksumAlgorithm{
+	{bits: 256, digest: func(d *util.FileDigests) string { return d.SHA256 }},
+	{bits: 512, digest: func(d *util.FileDigests) string { return d.SHA512 }},
+}
+
+// ChecksumFiles returns the names of the consolidated checksum files written
+// by WriteChecksums.
+func ChecksumFiles() []string {
+	res := []string{}
+	for _, algo := range checksumAlgorithms {
+		res = append(res, algo.sumsFile())
+	}
+	return res
+}
+
+// Digests are the digests of local files mapped by their path. Staging a
+// release shares them between the checksums, the manifest and the upload,
+// so that every artifact is read only once. The files must not change while
+// their digests are in use.
+type Digests map[string]*util.FileDigests
+
+// Of returns the digests of the file at `path`, which is only hashed if its
+// digests are not known yet.
+func (d Digests) Of(path string) (*util.FileDigests, error) {
+	if digests, ok := d[path]; ok {
+		return digests, nil
+	}
+	digests, err := util.DigestFile(path)
+	if err != nil {
+		return nil, err
+	}
+	d[path] = digests
+	return digests, nil
+}
+
+// SHA256 returns the known SHA256 digests mapped by the file path
+func (d Digests) SHA256() map[string]string {
+	res := map[string]string{}
+	for path, digests := range d {
+		res[path] = digests.SHA256
+	}
+	return res
+}
+
+// WriteChecksums writes the SHA256SUMS and SHA512SUMS files into `rootPath`,
+// covering all files found recursively within it by their relative path.
+// Afterwards a `.sha256` and `.sha512` file containing only the hash is
+// written next to every file, including the consolidated checksum files.
+// Checksum files of a previous run are overwritten instead of being hashed.
+// Replaces the hashing part of release::gcs::locally_stage_release_artifacts
+func WriteChecksums(rootPath string, digests Digests) error {
+	found, err := findFilesWithSuffix(rootPath, "")
+	if err != nil {
+		return errors.Wrapf(err, "finding files in %s", rootPath)
+	}
+	files := []string{}
+	for _, file := range found {
+		if !isChecksumFile(rootPath, file) {
+			files = append(files, file)
+		}
+	}
+
+	logrus.Info("Writing artifact hashes to SHA256SUMS/SHA512SUMS files")
+	sums := make([]strings.Builder, len(checksumAlgorithms))
+	for _, file := range files {
+		fileDigests, err := digests.Of(file)
+		if err != nil {
+			return err
+		}
+		rel, err := filepath.Rel(rootPath, file)
+		if err != nil {
+			return err
+		}
+		for i, algo := range checksumAlgorithms {
+			fmt.Fprintf(&sums[i], "%s  %s\n", algo.digest(fileDigests), rel)
+		}
+	}
+
+	// The consolidated files are written after all hashes are calculated to
+	// not include them in each other
+	for i, algo := range checksumAlgorithms {
+		path := filepath.Join(rootPath, algo.sumsFile())
+		if err := ioutil.WriteFile(
+			path, []byte(sums[i].String()), os.FileMode(0644),
+		); err != nil {
+			return errors.Wrapf(err, "writing %s", path)
+		}
+		delete(digests, path)
+		files = append(files, path)
+	}
+
+	logrus.Infof("Hashing files in %s", rootPath)
+	for _, file := range files {
+		fileDigests, err := digests.Of(file)
+		if err != nil {
+			return err
+		}
+		for _, algo := range checksumAlgorithms {
+			if err := ioutil.WriteFile(
+				file+algo.extension(), []byte(algo.digest(fileDigests)+"\n"), os.FileMode(0644),
+			); err != nil {
+				return errors.Wrapf(err, "writing checksum for %s", file)
+			}
+		}
+	}
+
+	return nil
+}
+
+// isChecksumFile returns true if the file is a checksum sidecar file or one
+// of the consolidated checksum files in `rootPath`.
+func isChecksumFile(rootPath, file string) bool {
+	for _, algo := range checksumAlgorithms {
+		if strings.HasSuffix(file, algo.extension()) ||
+			file == filepath.Join(rootPath, algo.sumsFile()) {
+			return true
+		}
+	}
+	return false
+}
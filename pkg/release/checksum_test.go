@@ -0,0 +1,102 @@
+/*
+Copyright 2020 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package release
+
+import (
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestWriteChecksums(t *testing.T) {
+	baseTmpDir, err := ioutil.TempDir("", "")
+	require.Nil(t, err)
+	defer cleanupTmps(t, baseTmpDir)
+
+	require.Nil(t, os.MkdirAll(filepath.Join(baseTmpDir, "bin"), os.ModePerm))
+	require.Nil(t, ioutil.WriteFile(
+		filepath.Join(baseTmpDir, "kubernetes.tar.gz"),
+		[]byte("test"),
+		os.FileMode(0644),
+	))
+	require.Nil(t, ioutil.WriteFile(
+		filepath.Join(baseTmpDir, "bin", "kubectl"),
+		[]byte("test"),
+		os.FileMode(0644),
+	))
+
+	require.Nil(t, WriteChecksums(baseTmpDir, Digests{}))
+
+	const (
+		sha256 = "9f86d081884c7d659a2feaa0c55ad015a3bf4f1b2b0b822cd15d6c15b0f00a08"
+		sha512 = "ee26b0dd4af7e749aa1a8ee3c10ae9923f618980772e473f8819a5d4940e0db2" +
+			"7ac185f8a0e1d5f84f88bc887fd67b143732c304cc5fa9ad8e6f57f50028a8ff"
+	)
+
+	sha256Sums, err := ioutil.ReadFile(filepath.Join(baseTmpDir, "SHA256SUMS"))
+	require.Nil(t, err)
+	require.Equal(t,
+		sha256+"  bin/kubectl\n"+sha256+"  kubernetes.tar.gz\n",
+		string(sha256Sums),
+	)
+
+	sha512Sums, err := ioutil.ReadFile(filepath.Join(baseTmpDir, "SHA512SUMS"))
+	require.Nil(t, err)
+	require.Equal(t,
+		sha512+"  bin/kubectl\n"+sha512+"  kubernetes.tar.gz\n",
+		string(sha512Sums),
+	)
+
+	for _, file := range []string{"bin/kubectl", "kubernetes.tar.gz"} {
+		content, err := ioutil.ReadFile(filepath.Join(baseTmpDir, file+".sha256"))
+		require.Nil(t, err)
+		require.Equal(t, sha256+"\n", string(content))
+
+		content, err = ioutil.ReadFile(filepath.Join(baseTmpDir, file+".sha512"))
+		require.Nil(t, err)
+		require.Equal(t, sha512+"\n", string(content))
+	}
+
+	for _, file := range ChecksumFiles() {
+		require.FileExists(t, filepath.Join(baseTmpDir, file+".sha256"))
+		require.FileExists(t, filepath.Join(baseTmpDir, file+".sha512"))
+	}
+
+	// A re-run does not include the checksum files of the previous run
+	require.Nil(t, WriteChecksums(baseTmpDir, Digests{}))
+	rerunSums, err := ioutil.ReadFile(filepath.Join(baseTmpDir, "SHA256SUMS"))
+	require.Nil(t, err)
+	require.Equal(t, string(sha256Sums), string(rerunSums))
+}
+
+func TestDigestsOf(t *testing.T) {
+	digests := Digests{"/known": {SHA256: "known"}}
+	res, err := digests.Of("/known")
+	require.Nil(t, err)
+	require.Equal(t, "known", res.SHA256)
+	require.Equal(t, map[string]string{"/known": "known"}, digests.SHA256())
+
+	_, err = digests.Of("/not/existing")
+	require.NotNil(t, err)
+}
+
+func TestWriteChecksumsNotExistingDir(t *testing.T) {
+	require.NotNil(t, WriteChecksums("/not/existing", Digests{}))
+}
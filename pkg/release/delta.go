@@ -0,0 +1,306 @@
+/*
+Copyright 2020 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package release
+
+import (
+	"compress/gzip"
+	"fmt"
+	"io"
+	"io/ioutil"
+	"os"
+	"path"
+	"path/filepath"
+	"strings"
+
+	"github.com/pkg/errors"
+	"github.com/sirupsen/logrus"
+
+	"k8s.io/release/pkg/command"
+	"k8s.io/release/pkg/util"
+)
+
+const (
+	// ZstdExecutable is the binary used for creating and applying deltas.
+	ZstdExecutable = "zstd"
+
+	// DeltaPath is the directory of the binary deltas within a staged release
+	DeltaPath = "deltas"
+
+	// DeltaTargetsFile lists the SHA256 digests of the uncompressed tarballs
+	// resulting from the deltas of a previous version, in the format of
+	// `sha256sum`
+	DeltaTargetsFile = "TARGET-SHA256SUMS"
+
+	deltaExtension = ".zst-patch"
+
+	// zstdWindowLog allows matching content of the previous artifact across
+	// the whole size of large tarballs. The same value has to be used when
+	// applying the delta.
+	zstdWindowLog = "--long=31"
+)
+
+// DeltaOptions select the artifacts of the previous release to create binary
+// deltas from.
+type DeltaOptions struct {
+	// PreviousDir contains the artifacts of the previous release, using the
+	// same layout as the staged release
+	PreviousDir string
+
+	// PreviousVersion is the version of the artifacts in PreviousDir
+	PreviousVersion string
+
+	// MinSize is the minimum size in bytes of an artifact to create a delta for
+	MinSize int64
+}
+
+// DeltaName returns the path of the delta from `previousVersion` for the
+// tarball `name` within the staged release, for example
+// `deltas/v1.18.0/kubernetes-server-linux-amd64.tar.zst-patch`. Deltas are
+// created between the uncompressed tarballs, because compression spreads
+// every change across the whole archive.
+func DeltaName(previousVersion, name string) string {
+	return filepath.Join(
+		DeltaPath, previousVersion, uncompressedName(name)+deltaExtension,
+	)
+}
+
+// uncompressedName returns the name of the uncompressed tarball `name`, like
+// kubernetes.tar for kubernetes.tar.gz
+func uncompressedName(name string) string {
+	return strings.TrimSuffix(name, ".gz")
+}
+
+// WriteDeltas creates binary deltas for all tarballs within the staged
+// release in `rootPath` which exceed the minimum size and also exist in the
+// previous release. The deltas transform the uncompressed previous tarball
+// into the uncompressed staged one, whose digests are written to the
+// DeltaTargetsFile next to the deltas. They are written below the DeltaPath
+// of `rootPath`, which means they have to be created before the checksums,
+// the signatures and the manifest to be published alongside the full
+// archives. Every delta gets verified after creation. The paths of the
+// created deltas are returned.
+func WriteDeltas(rootPath string, opts *DeltaOptions) ([]string, error) {
+	files, err := findFilesWithSuffix(rootPath, tarballExtension)
+	if err != nil {
+		return nil, errors.Wrapf(err, "finding tarballs in %s", rootPath)
+	}
+
+	tmpDir, err := ioutil.TempDir("", "release-delta-")
+	if err != nil {
+		return nil, errors.Wrap(err, "creating temp dir")
+	}
+	defer os.RemoveAll(tmpDir)
+
+	deltas := []string{}
+	targets := &strings.Builder{}
+	for _, file := range files {
+		rel, err := filepath.Rel(rootPath, file)
+		if err != nil {
+			return nil, err
+		}
+
+		fileInfo, err := os.Stat(file)
+		if err != nil {
+			return nil, errors.Wrapf(err, "reading %s", file)
+		}
+		if fileInfo.Size() < opts.MinSize {
+			logrus.Debugf("Skipping delta for %s, which is too small", rel)
+			continue
+		}
+
+		previous := filepath.Join(opts.PreviousDir, rel)
+		if !util.Exists(previous) {
+			logrus.Infof(
+				"Skipping delta for %s, which is not part of %s",
+				rel, opts.PreviousVersion,
+			)
+			continue
+		}
+
+		from := filepath.Join(tmpDir, "from.tar")
+		to := filepath.Join(tmpDir, "to.tar")
+		if err := Gunzip(previous, from); err != nil {
+			return nil, err
+		}
+		if err := Gunzip(file, to); err != nil {
+			return nil, err
+		}
+
+		delta := filepath.Join(rootPath, DeltaName(opts.PreviousVersion, rel))
+		if err := CreateDelta(from, to, delta); err != nil {
+			return nil, err
+		}
+		target, err := util.DigestFile(to)
+		if err != nil {
+			return nil, err
+		}
+		fmt.Fprintf(targets, "%s  %s\n", target.SHA256, filepath.ToSlash(uncompressedName(rel)))
+		deltas = append(deltas, delta)
+	}
+
+	if len(deltas) > 0 {
+		targetsFile := filepath.Join(rootPath, DeltaPath, opts.PreviousVersion, DeltaTargetsFile)
+		if err := ioutil.WriteFile(
+			targetsFile, []byte(targets.String()), os.FileMode(0644),
+		); err != nil {
+			return nil, errors.Wrapf(err, "writing %s", targetsFile)
+		}
+	}
+	return deltas, nil
+}
+
+// CreateDelta writes the binary delta `delta`, which transforms the file
+// `from` into the file `to`. The delta gets verified by applying it to
+// `from` and comparing the result with the digest of `to`.
+func CreateDelta(from, to, delta string) error {
+	if err := os.MkdirAll(filepath.Dir(delta), os.FileMode(0755)); err != nil {
+		return errors.Wrapf(err, "creating directory for %s", delta)
+	}
+
+	logrus.Infof("Creating delta %s", delta)
+	if err := command.New(ZstdExecutable,
+		"-q", "-f", "-19", zstdWindowLog, "--patch-from="+from, to, "-o", delta,
+	).RunSilentSuccess(); err != nil {
+		return errors.Wrapf(err, "creating delta for %s", to)
+	}
+
+	expected, err := util.DigestFile(to)
+	if err != nil {
+		return err
+	}
+	return VerifyDelta(from, delta, expected.SHA256)
+}
+
+// VerifyDelta applies the binary delta to the file `from` and checks that
+// the result matches the SHA256 digest `expected`.
+func VerifyDelta(from, delta, expected string) error {
+	tmpDir, err := ioutil.TempDir("", "release-delta-")
+	if err != nil {
+		return errors.Wrap(err, "creating temp dir")
+	}
+	defer os.RemoveAll(tmpDir)
+
+	return applyAndVerifyDelta(from, delta, filepath.Join(tmpDir, "result"), expected)
+}
+
+// ApplyTarballDelta applies the delta published with a release to the
+// compressed tarball `previousTarball` of the previous release. The result
+// is the uncompressed tarball `output`, which is verified against the
+// DeltaTargetsFile next to the delta. If `keyring` is set, the signatures of
+// the delta and the DeltaTargetsFile are verified first.
+func ApplyTarballDelta(previousTarball, delta, output, keyring string) error {
+	targetsFile := filepath.Join(filepath.Dir(delta), DeltaTargetsFile)
+	if keyring != "" {
+		for _, file := range []string{delta, targetsFile} {
+			if err := VerifySignature(file, keyring); err != nil {
+				return err
+			}
+		}
+	}
+
+	expected, err := deltaTarget(targetsFile, filepath.Base(delta))
+	if err != nil {
+		return err
+	}
+
+	tmpDir, err := ioutil.TempDir("", "release-delta-")
+	if err != nil {
+		return errors.Wrap(err, "creating temp dir")
+	}
+	defer os.RemoveAll(tmpDir)
+
+	from := filepath.Join(tmpDir, "from.tar")
+	if err := Gunzip(previousTarball, from); err != nil {
+		return err
+	}
+	return applyAndVerifyDelta(from, delta, output, expected)
+}
+
+// deltaTarget returns the expected SHA256 digest of the result of the delta
+// `deltaName` from the DeltaTargetsFile `targetsFile`
+func deltaTarget(targetsFile, deltaName string) (string, error) {
+	content, err := ioutil.ReadFile(targetsFile)
+	if err != nil {
+		return "", errors.Wrapf(err, "reading %s", targetsFile)
+	}
+	target := strings.TrimSuffix(deltaName, deltaExtension)
+	for _, line := range strings.Split(string(content), "\n") {
+		fields := strings.Fields(line)
+		if len(fields) == 2 && path.Base(fields[1]) == target {
+			return fields[0], nil
+		}
+	}
+	return "", errors.Errorf("%s is not listed in %s", target, targetsFile)
+}
+
+// applyAndVerifyDelta applies the delta to `from`, writes the result to
+// `output` and checks that it matches the SHA256 digest `expected`. The
+// output is removed if it does not match.
+func applyAndVerifyDelta(from, delta, output, expected string) error {
+	if err := ApplyDelta(from, delta, output); err != nil {
+		return err
+	}
+
+	digests, err := util.DigestFile(output)
+	if err != nil {
+		return err
+	}
+	if digests.SHA256 != expected {
+		os.Remove(output) // nolint: errcheck
+		return errors.Errorf(
+			"applying delta %s results in sha256 %s, expected %s",
+			delta, digests.SHA256, expected,
+		)
+	}
+	return nil
+}
+
+// ApplyDelta applies the binary delta to the file `from` and writes the
+// result to `output`.
+func ApplyDelta(from, delta, output string) error {
+	if err := command.New(ZstdExecutable,
+		"-q", "-f", "-d", zstdWindowLog, "--patch-from="+from, delta, "-o", output,
+	).RunSilentSuccess(); err != nil {
+		return errors.Wrapf(err, "applying delta %s", delta)
+	}
+	return nil
+}
+
+// Gunzip decompresses the gzip file `src` into `dst`
+func Gunzip(src, dst string) error {
+	in, err := os.Open(src)
+	if err != nil {
+		return errors.Wrapf(err, "opening %s", src)
+	}
+	defer in.Close()
+
+	r, err := gzip.NewReader(in)
+	if err != nil {
+		return errors.Wrapf(err, "reading gzip file %s", src)
+	}
+	defer r.Close()
+
+	out, err := os.Create(dst)
+	if err != nil {
+		return errors.Wrapf(err, "creating %s", dst)
+	}
+	_, err = io.Copy(out, r)
+	if closeErr := out.Close(); err == nil {
+		err = closeErr
+	}
+	return errors.Wrapf(err, "decompressing %s", src)
+}
@@ -0,0 +1,126 @@
+/*
+Copyright 2020 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package release
+
+import (
+	"compress/gzip"
+	"io/ioutil"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+
+	"k8s.io/release/pkg/util"
+)
+
+func TestDeltaName(t *testing.T) {
+	require.Equal(t,
+		"deltas/v1.18.0/kubernetes-server-linux-amd64.tar.zst-patch",
+		DeltaName("v1.18.0", "kubernetes-server-linux-amd64.tar.gz"),
+	)
+}
+
+func writeTestReleases(t *testing.T, baseTmpDir string) (previousDir, stageDir string) {
+	previousDir = filepath.Join(baseTmpDir, "previous")
+	stageDir = filepath.Join(baseTmpDir, "stage")
+	for dir, content := range map[string]string{
+		previousDir: "previous release content",
+		stageDir:    "staged release content",
+	} {
+		require.Nil(t, os.MkdirAll(dir, os.FileMode(0755)))
+		writeTestGzip(t,
+			filepath.Join(dir, "kubernetes.tar.gz"), strings.Repeat(content, 100),
+		)
+	}
+	writeTestGzip(t,
+		filepath.Join(stageDir, "kubernetes-client-linux-amd64.tar.gz"),
+		"new artifact",
+	)
+	return previousDir, stageDir
+}
+
+func writeTestGzip(t *testing.T, path, content string) {
+	f, err := os.Create(path)
+	require.Nil(t, err)
+	w := gzip.NewWriter(f)
+	_, err = w.Write([]byte(content))
+	require.Nil(t, err)
+	require.Nil(t, w.Close())
+	require.Nil(t, f.Close())
+}
+
+func TestWriteDeltasSkipped(t *testing.T) {
+	baseTmpDir, err := ioutil.TempDir("", "")
+	require.Nil(t, err)
+	defer cleanupTmps(t, baseTmpDir)
+
+	previousDir, stageDir := writeTestReleases(t, baseTmpDir)
+
+	// All artifacts are smaller than the minimum size
+	deltas, err := WriteDeltas(stageDir, &DeltaOptions{
+		PreviousDir:     previousDir,
+		PreviousVersion: "v1.18.0",
+		MinSize:         1 << 20,
+	})
+	require.Nil(t, err)
+	require.Empty(t, deltas)
+}
+
+func TestWriteDeltas(t *testing.T) {
+	if _, err := exec.LookPath(ZstdExecutable); err != nil {
+		t.Skipf("%s is not available", ZstdExecutable)
+	}
+
+	baseTmpDir, err := ioutil.TempDir("", "")
+	require.Nil(t, err)
+	defer cleanupTmps(t, baseTmpDir)
+
+	previousDir, stageDir := writeTestReleases(t, baseTmpDir)
+
+	deltas, err := WriteDeltas(stageDir, &DeltaOptions{
+		PreviousDir:     previousDir,
+		PreviousVersion: "v1.18.0",
+	})
+	require.Nil(t, err)
+	require.Equal(t, []string{
+		filepath.Join(stageDir, DeltaName("v1.18.0", "kubernetes.tar.gz")),
+	}, deltas)
+
+	targets, err := ioutil.ReadFile(
+		filepath.Join(stageDir, DeltaPath, "v1.18.0", DeltaTargetsFile),
+	)
+	require.Nil(t, err)
+	require.Regexp(t, "^[0-9a-f]{64}  kubernetes.tar\n$", string(targets))
+
+	output := filepath.Join(baseTmpDir, "kubernetes.tar")
+	require.Nil(t, ApplyTarballDelta(
+		filepath.Join(previousDir, "kubernetes.tar.gz"), deltas[0], output, "",
+	))
+	content, err := ioutil.ReadFile(output)
+	require.Nil(t, err)
+	require.Equal(t, strings.Repeat("staged release content", 100), string(content))
+
+	// Applying the delta to another tarball does not match the target digest
+	require.NotNil(t, ApplyTarballDelta(
+		filepath.Join(stageDir, "kubernetes-client-linux-amd64.tar.gz"),
+		deltas[0], output, "",
+	))
+	require.False(t, util.Exists(output))
+}
@@ -0,0 +1,156 @@
+/*
+Copyright 2020 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package release
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+)
+
+// ManifestDiff are the differences between the release manifests of two
+// versions
+type ManifestDiff struct {
+	From    string             `json:"from"`
+	To      string             `json:"to"`
+	Added   []ManifestArtifact `json:"added"`
+	Removed []ManifestArtifact `json:"removed"`
+	Changed []ArtifactChange   `json:"changed"`
+}
+
+// ArtifactChange is an artifact contained in both releases which differs in
+// its content or signature
+type ArtifactChange struct {
+	Name       string `json:"name"`
+	FromSize   int64  `json:"from_size"`
+	ToSize     int64  `json:"to_size"`
+	FromSHA256 string `json:"from_sha256"`
+	ToSHA256   string `json:"to_sha256"`
+
+	// Signature is "added" or "removed" if only one of the artifacts is
+	// signed, otherwise empty
+	Signature string `json:"signature,omitempty"`
+}
+
+// SizeDelta returns the size difference of the artifact in bytes
+func (c *ArtifactChange) SizeDelta() int64 {
+	return c.ToSize - c.FromSize
+}
+
+// DigestChanged returns true if the content of the artifact differs
+func (c *ArtifactChange) DigestChanged() bool {
+	return c.FromSHA256 != c.ToSHA256
+}
+
+// DiffManifests compares the artifacts of the `from` and `to` release
+// manifests by their name. The results are sorted by the artifact name.
+func DiffManifests(from, to *Manifest) *ManifestDiff {
+	diff := &ManifestDiff{
+		From:    from.Version,
+		To:      to.Version,
+		Added:   []ManifestArtifact{},
+		Removed: []ManifestArtifact{},
+		Changed: []ArtifactChange{},
+	}
+
+	fromArtifacts := map[string]*ManifestArtifact{}
+	for i := range from.Artifacts {
+		fromArtifacts[from.Artifacts[i].Name] = &from.Artifacts[i]
+	}
+	toArtifacts := map[string]bool{}
+	for i := range to.Artifacts {
+		artifact := &to.Artifacts[i]
+		toArtifacts[artifact.Name] = true
+
+		previous, ok := fromArtifacts[artifact.Name]
+		if !ok {
+			diff.Added = append(diff.Added, *artifact)
+			continue
+		}
+
+		change := ArtifactChange{
+			Name:       artifact.Name,
+			FromSize:   previous.Size,
+			ToSize:     artifact.Size,
+			FromSHA256: previous.SHA256,
+			ToSHA256:   artifact.SHA256,
+		}
+		if previous.Signature == "" && artifact.Signature != "" {
+			change.Signature = "added"
+		} else if previous.Signature != "" && artifact.Signature == "" {
+			change.Signature = "removed"
+		}
+		if change.DigestChanged() || change.Signature != "" {
+			diff.Changed = append(diff.Changed, change)
+		}
+	}
+	for i := range from.Artifacts {
+		if !toArtifacts[from.Artifacts[i].Name] {
+			diff.Removed = append(diff.Removed, from.Artifacts[i])
+		}
+	}
+
+	sort.Slice(diff.Added, func(i, j int) bool {
+		return diff.Added[i].Name < diff.Added[j].Name
+	})
+	sort.Slice(diff.Removed, func(i, j int) bool {
+		return diff.Removed[i].Name < diff.Removed[j].Name
+	})
+	sort.Slice(diff.Changed, func(i, j int) bool {
+		return diff.Changed[i].Name < diff.Changed[j].Name
+	})
+	return diff
+}
+
+// Text renders the differences as plain text, one line per artifact
+func (d *ManifestDiff) Text() string {
+	o := &strings.Builder{}
+	fmt.Fprintf(o, "Artifacts of %s compared to %s:\n", d.To, d.From)
+	if len(d.Added)+len(d.Removed)+len(d.Changed) == 0 {
+		o.WriteString("No differences.\n")
+		return o.String()
+	}
+
+	for i := range d.Added {
+		fmt.Fprintf(o, "+ %s (%d bytes)\n", d.Added[i].Name, d.Added[i].Size)
+	}
+	for i := range d.Removed {
+		fmt.Fprintf(o, "- %s (%d bytes)\n", d.Removed[i].Name, d.Removed[i].Size)
+	}
+	for i := range d.Changed {
+		change := &d.Changed[i]
+		details := []string{}
+		if change.DigestChanged() {
+			details = append(details,
+				fmt.Sprintf("%+d bytes", change.SizeDelta()),
+				fmt.Sprintf("sha256 %s → %s", shortDigest(change.FromSHA256), shortDigest(change.ToSHA256)),
+			)
+		}
+		if change.Signature != "" {
+			details = append(details, "signature "+change.Signature)
+		}
+		fmt.Fprintf(o, "~ %s (%s)\n", change.Name, strings.Join(details, ", "))
+	}
+	return o.String()
+}
+
+func shortDigest(digest string) string {
+	if len(digest) > 12 {
+		return digest[:12]
+	}
+	return digest
+}
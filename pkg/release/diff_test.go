@@ -0,0 +1,85 @@
+/*
+Copyright 2020 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package release
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestDiffManifests(t *testing.T) {
+	from := &Manifest{Version: "v1.18.0", Artifacts: []ManifestArtifact{
+		{Name: "kubernetes.tar.gz", Size: 100, SHA256: "aaa"},
+		{Name: "kubernetes-client-linux-amd64.tar.gz", Size: 200, SHA256: "bbb"},
+		{Name: "kubernetes-server-linux-amd64.tar.gz", Size: 300, SHA256: "ccc", Signature: "sig"},
+		{Name: "kubernetes-node-linux-s390x.tar.gz", Size: 400, SHA256: "ddd"},
+	}}
+	to := &Manifest{Version: "v1.18.1", Artifacts: []ManifestArtifact{
+		{Name: "kubernetes.tar.gz", Size: 100, SHA256: "aaa"},
+		{Name: "kubernetes-client-linux-amd64.tar.gz", Size: 250, SHA256: "eee"},
+		{Name: "kubernetes-server-linux-amd64.tar.gz", Size: 300, SHA256: "ccc"},
+		{Name: "kubernetes-node-linux-arm64.tar.gz", Size: 500, SHA256: "fff"},
+	}}
+
+	diff := DiffManifests(from, to)
+	require.Equal(t, &ManifestDiff{
+		From: "v1.18.0",
+		To:   "v1.18.1",
+		Added: []ManifestArtifact{
+			{Name: "kubernetes-node-linux-arm64.tar.gz", Size: 500, SHA256: "fff"},
+		},
+		Removed: []ManifestArtifact{
+			{Name: "kubernetes-node-linux-s390x.tar.gz", Size: 400, SHA256: "ddd"},
+		},
+		Changed: []ArtifactChange{
+			{
+				Name:       "kubernetes-client-linux-amd64.tar.gz",
+				FromSize:   200,
+				ToSize:     250,
+				FromSHA256: "bbb",
+				ToSHA256:   "eee",
+			},
+			{
+				Name:       "kubernetes-server-linux-amd64.tar.gz",
+				FromSize:   300,
+				ToSize:     300,
+				FromSHA256: "ccc",
+				ToSHA256:   "ccc",
+				Signature:  "removed",
+			},
+		},
+	}, diff)
+
+	require.Equal(t, `Artifacts of v1.18.1 compared to v1.18.0:
++ kubernetes-node-linux-arm64.tar.gz (500 bytes)
+- kubernetes-node-linux-s390x.tar.gz (400 bytes)
+~ kubernetes-client-linux-amd64.tar.gz (+50 bytes, sha256 bbb → eee)
+~ kubernetes-server-linux-amd64.tar.gz (signature removed)
+`, diff.Text())
+}
+
+func TestDiffManifestsEqual(t *testing.T) {
+	manifest := &Manifest{Version: "v1.18.0", Artifacts: []ManifestArtifact{
+		{Name: "kubernetes.tar.gz", Size: 100, SHA256: "aaa"},
+	}}
+	diff := DiffManifests(manifest, manifest)
+	require.Empty(t, diff.Added)
+	require.Empty(t, diff.Removed)
+	require.Empty(t, diff.Changed)
+	require.Equal(t, "Artifacts of v1.18.0 compared to v1.18.0:\nNo differences.\n", diff.Text())
+}
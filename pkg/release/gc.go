@@ -0,0 +1,120 @@
+/*
+Copyright 2020 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package release
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"cloud.google.com/go/storage"
+	"github.com/pkg/errors"
+
+	"k8s.io/release/pkg/util"
+)
+
+// StagedBuildsPath is the directory in a release bucket where anago stages
+// the builds for later release runs
+const StagedBuildsPath = "stage"
+
+// GCPolicy decides which staged builds can be garbage collected
+type GCPolicy struct {
+	// Now is the reference time for the retention
+	Now time.Time
+
+	// Keep are the build versions which are never collected, for example
+	// because a release process still uses them
+	Keep []string
+
+	// Retention is the time after which a staged build gets collected
+	Retention time.Duration
+}
+
+// Collect returns the reason why the staged build `buildVersion`, last
+// updated at `updated`, can be deleted, or an empty string if it has to be
+// kept. `published` is the latest version released for the minor version of
+// the build, or empty if there is none. Builds are superseded if they are
+// older than that version, like v1.18.3-rc.0.12+0123456789abcd by v1.18.3.
+func (p *GCPolicy) Collect(buildVersion string, updated time.Time, published string) (string, error) {
+	for _, keep := range p.Keep {
+		if keep == buildVersion {
+			return "", nil
+		}
+	}
+
+	sv, err := util.TagStringToSemver(buildVersion)
+	if err != nil {
+		return "", errors.Wrapf(err, "parsing build version %s", buildVersion)
+	}
+
+	if published != "" {
+		psv, err := util.TagStringToSemver(published)
+		if err != nil {
+			return "", errors.Wrapf(err, "parsing published version %s", published)
+		}
+		if sv.LT(psv) {
+			return fmt.Sprintf("superseded by %s", published), nil
+		}
+	}
+
+	if age := p.Now.Sub(updated); p.Retention > 0 && age > p.Retention {
+		return fmt.Sprintf("older than %s", p.Retention), nil
+	}
+	return "", nil
+}
+
+// LatestPublished returns the newest official release or pre-release of the
+// minor version of `version` in `bucket`, or an empty string if nothing has
+// been released for it yet.
+func LatestPublished(
+	ctx context.Context, bucket *storage.BucketHandle, version string,
+) (string, error) {
+	sv, err := util.TagStringToSemver(version)
+	if err != nil {
+		return "", errors.Wrapf(err, "parsing version %s", version)
+	}
+
+	latest := ""
+	for _, markerType := range []string{"stable", "latest"} {
+		marker, err := MarkerPath(
+			BuildTypeRelease,
+			fmt.Sprintf("%s-%d.%d", markerType, sv.Major, sv.Minor),
+		)
+		if err != nil {
+			return "", err
+		}
+		published, err := ReadMarker(ctx, bucket, marker)
+		if err != nil {
+			return "", err
+		}
+		if published == "" {
+			continue
+		}
+		if latest == "" {
+			latest = published
+			continue
+		}
+		newer, err := IsNewerVersion(BuildTypeRelease, published, latest)
+		if err != nil {
+			return "", err
+		}
+		if newer {
+			latest = published
+		}
+	}
+	return latest, nil
+}
@@ -0,0 +1,88 @@
+/*
+Copyright 2020 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package release
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestGCPolicyCollect(t *testing.T) {
+	now := time.Date(2020, 5, 1, 0, 0, 0, 0, time.UTC)
+	policy := &GCPolicy{
+		Now:       now,
+		Keep:      []string{"v1.18.3-rc.0.1+0123456789abcd"},
+		Retention: 7 * 24 * time.Hour,
+	}
+
+	for name, tc := range map[string]struct {
+		buildVersion string
+		updated      time.Time
+		published    string
+		collect      bool
+		shouldErr    bool
+	}{
+		"Recent": {
+			buildVersion: "v1.18.4-rc.0.12+0123456789abcd",
+			updated:      now.Add(-time.Hour),
+			published:    "v1.18.3",
+		},
+		"Expired": {
+			buildVersion: "v1.18.4-rc.0.12+0123456789abcd",
+			updated:      now.Add(-8 * 24 * time.Hour),
+			published:    "v1.18.3",
+			collect:      true,
+		},
+		"Superseded": {
+			buildVersion: "v1.18.3-rc.0.12+0123456789abcd",
+			updated:      now.Add(-time.Hour),
+			published:    "v1.18.3",
+			collect:      true,
+		},
+		"SupersededByPreRelease": {
+			buildVersion: "v1.19.0-alpha.3.50+0123456789abcd",
+			updated:      now.Add(-time.Hour),
+			published:    "v1.19.0-beta.0",
+			collect:      true,
+		},
+		"NothingPublished": {
+			buildVersion: "v1.19.0-alpha.0.50+0123456789abcd",
+			updated:      now.Add(-time.Hour),
+		},
+		"Kept": {
+			buildVersion: "v1.18.3-rc.0.1+0123456789abcd",
+			updated:      now.Add(-8 * 24 * time.Hour),
+			published:    "v1.18.3",
+		},
+		"InvalidBuild": {
+			buildVersion: "not-a-build",
+			shouldErr:    true,
+		},
+	} {
+		t.Run(name, func(t *testing.T) {
+			reason, err := policy.Collect(tc.buildVersion, tc.updated, tc.published)
+			if tc.shouldErr {
+				require.NotNil(t, err)
+				return
+			}
+			require.Nil(t, err)
+			require.Equal(t, tc.collect, reason != "")
+		})
+	}
+}
@@ -0,0 +1,304 @@
+/*
+Copyright 2020 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package release
+
+import (
+	"encoding/json"
+	"fmt"
+	"strings"
+
+	"github.com/pkg/errors"
+	"github.com/sirupsen/logrus"
+
+	"k8s.io/release/pkg/command"
+	"k8s.io/release/pkg/util"
+)
+
+// GCloudExecutable is the binary used for modifying image tags
+const GCloudExecutable = "gcloud"
+
+// DockerExecutable is the binary used for inspecting image manifests
+const DockerExecutable = "docker"
+
+// DefaultImageRegistry is the push alias of the production registry
+// k8s.gcr.io
+const DefaultImageRegistry = "gcr.io/google-containers"
+
+// DefaultImages are the images published by a release
+var DefaultImages = []string{
+	"conformance",
+	"kube-apiserver",
+	"kube-controller-manager",
+	"kube-proxy",
+	"kube-scheduler",
+}
+
+// DefaultImageArchitectures are the architectures the images are published
+// for. Every image is published as `<image>-<arch>` and as manifest list
+// `<image>` referencing all architectures.
+var DefaultImageArchitectures = []string{
+	"amd64",
+	"arm",
+	"arm64",
+	"ppc64le",
+	"s390x",
+}
+
+// ImageRetag adds the tag To to the image referenced by From
+type ImageRetag struct {
+	From string
+	To   string
+}
+
+// ImageRetags returns the retags for all manifest lists and architecture
+// specific images of `images` in all `registries` from the tag `from` to the
+// tag `to`.
+func ImageRetags(registries, images, arches []string, from, to string) []ImageRetag {
+	res := []ImageRetag{}
+	for _, registry := range registries {
+		registry = strings.TrimSuffix(registry, "/")
+		for _, name := range ImageNames(images, arches) {
+			ref := registry + "/" + name
+			res = append(res, ImageRetag{
+				From: ref + ":" + from,
+				To:   ref + ":" + to,
+			})
+		}
+	}
+	return res
+}
+
+// Run adds the new tag in the registry. The new tag references the digest
+// of the existing one, which means that manifest lists keep referencing the
+// same architecture specific images and nothing gets rebuilt or pushed.
+func (r ImageRetag) Run() error {
+	logrus.Infof("Tagging %s as %s", r.From, r.To)
+	if err := command.New(
+		GCloudExecutable, "container", "images", "add-tag", "--quiet", r.From, r.To,
+	).RunSilentSuccess(); err != nil {
+		return errors.Wrapf(err, "tagging %s as %s", r.From, r.To)
+	}
+	return nil
+}
+
+// CheckImageRetags verifies that the `retags` can be done without moving
+// any existing tag, before the first image gets tagged. Every From tag has
+// to exist and none of the To tags must exist yet. The tags of every image
+// are listed once via `list`, which is ListRegistryImages outside of tests.
+func CheckImageRetags(
+	retags []ImageRetag, list func(ref string) ([]RegistryImage, error),
+) error {
+	tags := map[string]map[string]bool{}
+	hasTag := func(ref string) (bool, error) {
+		image, tag := splitImageTag(ref)
+		if _, ok := tags[image]; !ok {
+			listed, err := list(image)
+			if err != nil {
+				return false, err
+			}
+			tags[image] = map[string]bool{}
+			for _, l := range listed {
+				for _, t := range l.Tags {
+					tags[image][t] = true
+				}
+			}
+		}
+		return tags[image][tag], nil
+	}
+
+	problems := []string{}
+	for _, retag := range retags {
+		exists, err := hasTag(retag.From)
+		if err != nil {
+			return err
+		}
+		if !exists {
+			problems = append(problems, retag.From+" does not exist")
+		}
+
+		exists, err = hasTag(retag.To)
+		if err != nil {
+			return err
+		}
+		if exists {
+			problems = append(problems, retag.To+" already exists")
+		}
+	}
+	if len(problems) > 0 {
+		return errors.Errorf(
+			"refusing to tag any image: %s", strings.Join(problems, ", "),
+		)
+	}
+	return nil
+}
+
+// splitImageTag splits the image reference `ref` into the image and its tag
+func splitImageTag(ref string) (image, tag string) {
+	i := strings.LastIndex(ref, ":")
+	if i < 0 || strings.Contains(ref[i:], "/") {
+		return ref, ""
+	}
+	return ref[:i], ref[i+1:]
+}
+
+// ImageNames returns the names of the manifest lists and the architecture
+// specific images of `images`.
+func ImageNames(images, arches []string) []string {
+	res := []string{}
+	for _, image := range images {
+		res = append(res, image)
+		for _, arch := range arches {
+			res = append(res, fmt.Sprintf("%s-%s", image, arch))
+		}
+	}
+	return res
+}
+
+// RegistryImage is a digest of an image and its tags, as listed by the
+// registry
+type RegistryImage struct {
+	Digest string   `json:"digest"`
+	Tags   []string `json:"tags"`
+}
+
+// OrphanedImage is a digest of an image which is not referenced by any
+// known release
+type OrphanedImage struct {
+	Image  string
+	Digest string
+	Tags   []string
+	Reason string
+}
+
+// ListRegistryImages returns all digests and their tags of the image `ref`,
+// like gcr.io/google-containers/kube-proxy.
+func ListRegistryImages(ref string) ([]RegistryImage, error) {
+	output, err := command.New(
+		GCloudExecutable, "container", "images", "list-tags", ref,
+		"--format=json", "--limit=unlimited",
+	).RunSilentSuccessOutput()
+	if err != nil {
+		return nil, errors.Wrapf(err, "listing tags of %s", ref)
+	}
+
+	res := []RegistryImage{}
+	if err := json.Unmarshal([]byte(output.Output()), &res); err != nil {
+		return nil, errors.Wrapf(err, "decoding tags of %s", ref)
+	}
+	return res, nil
+}
+
+// FindOrphanedImages returns the digests of the image `ref` which are only
+// tagged with versions not released according to `releases`. Digests with
+// tags which are no versions, like `latest`, are always kept. So are
+// untagged digests and the `children` referenced by any manifest list,
+// which are the architecture specific images of multi-arch images.
+func FindOrphanedImages(
+	ref string, listed []RegistryImage, releases, children map[string]bool,
+) []OrphanedImage {
+	res := []OrphanedImage{}
+	for _, image := range listed {
+		if len(image.Tags) == 0 || children[image.Digest] {
+			continue
+		}
+
+		referenced := false
+		candidate := false
+		for _, tag := range image.Tags {
+			version, err := util.TagStringToSemver(tag)
+			if err != nil || releases[tag] {
+				referenced = true
+				break
+			}
+			if len(version.Pre) > 0 {
+				candidate = true
+			}
+		}
+		if referenced {
+			continue
+		}
+
+		orphan := OrphanedImage{Image: ref, Digest: image.Digest, Tags: image.Tags}
+		orphan.Reason = "not referenced by any release"
+		if candidate {
+			orphan.Reason = "orphaned release candidate"
+		}
+		res = append(res, orphan)
+	}
+	return res
+}
+
+// ManifestListChildren returns the digests of all images referenced by the
+// manifest lists within `listed` of the image `ref`
+func ManifestListChildren(ref string, listed []RegistryImage) (map[string]bool, error) {
+	res := map[string]bool{}
+	for _, image := range listed {
+		if len(image.Tags) == 0 {
+			continue
+		}
+		digests, err := manifestListDigests(ref + "@" + image.Digest)
+		if err != nil {
+			return nil, err
+		}
+		for _, digest := range digests {
+			res[digest] = true
+		}
+	}
+	return res, nil
+}
+
+// manifestListDigests returns the digests referenced by the manifest list
+// `ref`, which are none if `ref` is a single image
+func manifestListDigests(ref string) ([]string, error) {
+	output, err := command.New(
+		DockerExecutable, "manifest", "inspect", ref,
+	).RunSilentSuccessOutput()
+	if err != nil {
+		return nil, errors.Wrapf(err, "inspecting manifest of %s", ref)
+	}
+	return parseManifestList([]byte(output.Output()))
+}
+
+func parseManifestList(content []byte) ([]string, error) {
+	manifest := struct {
+		Manifests []struct {
+			Digest string `json:"digest"`
+		} `json:"manifests"`
+	}{}
+	if err := json.Unmarshal(content, &manifest); err != nil {
+		return nil, errors.Wrap(err, "decoding manifest")
+	}
+	res := []string{}
+	for _, m := range manifest.Manifests {
+		res = append(res, m.Digest)
+	}
+	return res, nil
+}
+
+// Delete removes the orphaned digest including all of its tags from the
+// registry
+func (o *OrphanedImage) Delete() error {
+	ref := o.Image + "@" + o.Digest
+	logrus.Infof("Deleting %s", ref)
+	if err := command.New(
+		GCloudExecutable, "container", "images", "delete", ref,
+		"--quiet", "--force-delete-tags",
+	).RunSilentSuccess(); err != nil {
+		return errors.Wrapf(err, "deleting %s", ref)
+	}
+	return nil
+}
@@ -0,0 +1,175 @@
+/*
+Copyright 2020 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package release
+
+import (
+	"testing"
+
+	"github.com/pkg/errors"
+	"github.com/stretchr/testify/require"
+)
+
+func TestImageRetags(t *testing.T) {
+	require.Equal(t, []ImageRetag{
+		{From: "gcr.io/a/kube-proxy:v1.18.0-rc.1", To: "gcr.io/a/kube-proxy:v1.18.0"},
+		{From: "gcr.io/a/kube-proxy-amd64:v1.18.0-rc.1", To: "gcr.io/a/kube-proxy-amd64:v1.18.0"},
+		{From: "gcr.io/a/kube-proxy-arm64:v1.18.0-rc.1", To: "gcr.io/a/kube-proxy-arm64:v1.18.0"},
+		{From: "gcr.io/b/kube-proxy:v1.18.0-rc.1", To: "gcr.io/b/kube-proxy:v1.18.0"},
+		{From: "gcr.io/b/kube-proxy-amd64:v1.18.0-rc.1", To: "gcr.io/b/kube-proxy-amd64:v1.18.0"},
+		{From: "gcr.io/b/kube-proxy-arm64:v1.18.0-rc.1", To: "gcr.io/b/kube-proxy-arm64:v1.18.0"},
+	}, ImageRetags(
+		[]string{"gcr.io/a", "gcr.io/b/"},
+		[]string{"kube-proxy"},
+		[]string{"amd64", "arm64"},
+		"v1.18.0-rc.1", "v1.18.0",
+	))
+}
+
+func TestImageRetagsNoArchitectures(t *testing.T) {
+	require.Equal(t, []ImageRetag{
+		{From: "gcr.io/a/kube-proxy:v1", To: "gcr.io/a/kube-proxy:v2"},
+	}, ImageRetags(
+		[]string{"gcr.io/a"}, []string{"kube-proxy"}, []string{}, "v1", "v2",
+	))
+}
+
+func TestCheckImageRetags(t *testing.T) {
+	registry := map[string][]RegistryImage{
+		"gcr.io/a/kube-proxy": {
+			{Digest: "sha256:1", Tags: []string{"v1.18.0-rc.1"}},
+			{Digest: "sha256:2", Tags: []string{"v1.17.0", "latest"}},
+		},
+		"gcr.io/a/kube-proxy-amd64": {
+			{Digest: "sha256:3", Tags: []string{"v1.18.0-rc.1"}},
+		},
+	}
+	list := func(ref string) ([]RegistryImage, error) {
+		if ref == "gcr.io/broken/kube-proxy" {
+			return nil, errors.New("listing failed")
+		}
+		return registry[ref], nil
+	}
+
+	for name, tc := range map[string]struct {
+		from, to   string
+		registries []string
+		arches     []string
+		err        string
+	}{
+		"success": {
+			from: "v1.18.0-rc.1", to: "v1.18.0",
+			registries: []string{"gcr.io/a"}, arches: []string{"amd64"},
+		},
+		"failure existing to tag": {
+			from: "v1.18.0-rc.1", to: "v1.17.0",
+			registries: []string{"gcr.io/a"}, arches: []string{"amd64"},
+			err: "gcr.io/a/kube-proxy:v1.17.0 already exists",
+		},
+		"failure missing from tag": {
+			from: "v1.18.0-rc.1", to: "v1.18.0",
+			registries: []string{"gcr.io/a"}, arches: []string{"amd64", "arm64"},
+			err: "gcr.io/a/kube-proxy-arm64:v1.18.0-rc.1 does not exist",
+		},
+		"failure listing": {
+			from: "v1.18.0-rc.1", to: "v1.18.0",
+			registries: []string{"gcr.io/broken"},
+			err:        "listing failed",
+		},
+	} {
+		t.Run(name, func(t *testing.T) {
+			err := CheckImageRetags(ImageRetags(
+				tc.registries, []string{"kube-proxy"}, tc.arches, tc.from, tc.to,
+			), list)
+			if tc.err == "" {
+				require.Nil(t, err)
+			} else {
+				require.NotNil(t, err)
+				require.Contains(t, err.Error(), tc.err)
+			}
+		})
+	}
+}
+
+func TestSplitImageTag(t *testing.T) {
+	for ref, expected := range map[string][2]string{
+		"gcr.io/a/kube-proxy:v1.18.0": {"gcr.io/a/kube-proxy", "v1.18.0"},
+		"gcr.io/a/kube-proxy":         {"gcr.io/a/kube-proxy", ""},
+		"localhost:5000/kube-proxy":   {"localhost:5000/kube-proxy", ""},
+	} {
+		image, tag := splitImageTag(ref)
+		require.Equal(t, expected, [2]string{image, tag}, ref)
+	}
+}
+
+func TestImageNames(t *testing.T) {
+	require.Equal(t, []string{
+		"kube-proxy", "kube-proxy-amd64", "kube-proxy-arm64",
+		"kube-scheduler", "kube-scheduler-amd64", "kube-scheduler-arm64",
+	}, ImageNames(
+		[]string{"kube-proxy", "kube-scheduler"}, []string{"amd64", "arm64"},
+	))
+}
+
+func TestFindOrphanedImages(t *testing.T) {
+	releases := map[string]bool{"v1.18.0": true, "v1.18.0-rc.1": true}
+	children := map[string]bool{"sha256:child": true}
+	listed := []RegistryImage{
+		{Digest: "sha256:final", Tags: []string{"v1.18.0"}},
+		{Digest: "sha256:rc1", Tags: []string{"v1.18.0-rc.1"}},
+		{Digest: "sha256:rc2", Tags: []string{"v1.18.0-rc.2"}},
+		{Digest: "sha256:unknown", Tags: []string{"v1.17.99"}},
+		{Digest: "sha256:latest", Tags: []string{"latest", "v1.19.0-alpha.0"}},
+		{Digest: "sha256:untagged", Tags: []string{}},
+		{Digest: "sha256:child", Tags: []string{"v1.17.98"}},
+	}
+
+	require.Equal(t, []OrphanedImage{
+		{
+			Image:  "gcr.io/a/kube-proxy",
+			Digest: "sha256:rc2",
+			Tags:   []string{"v1.18.0-rc.2"},
+			Reason: "orphaned release candidate",
+		},
+		{
+			Image:  "gcr.io/a/kube-proxy",
+			Digest: "sha256:unknown",
+			Tags:   []string{"v1.17.99"},
+			Reason: "not referenced by any release",
+		},
+	}, FindOrphanedImages("gcr.io/a/kube-proxy", listed, releases, children))
+}
+
+func TestParseManifestList(t *testing.T) {
+	digests, err := parseManifestList([]byte(`{
+		"schemaVersion": 2,
+		"mediaType": "application/vnd.docker.distribution.manifest.list.v2+json",
+		"manifests": [
+			{"digest": "sha256:amd64", "platform": {"architecture": "amd64"}},
+			{"digest": "sha256:arm64", "platform": {"architecture": "arm64"}}
+		]
+	}`))
+	require.Nil(t, err)
+	require.Equal(t, []string{"sha256:amd64", "sha256:arm64"}, digests)
+
+	// Single images reference no other manifests
+	digests, err = parseManifestList([]byte(`{"schemaVersion": 2, "layers": []}`))
+	require.Nil(t, err)
+	require.Empty(t, digests)
+
+	_, err = parseManifestList([]byte("invalid"))
+	require.NotNil(t, err)
+}
@@ -0,0 +1,158 @@
+/*
+Copyright 2020 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package release
+
+import (
+	"encoding/json"
+	"fmt"
+	"io/ioutil"
+	"os"
+	"path"
+	"path/filepath"
+	"regexp"
+	"strings"
+
+	"github.com/pkg/errors"
+	"github.com/sirupsen/logrus"
+
+	"k8s.io/release/pkg/util"
+)
+
+// ManifestFile is the name of the release manifest written by WriteManifest
+const ManifestFile = "release-manifest.json"
+
+// platformRegex matches the platform of release tarballs like
+// `kubernetes-client-linux-amd64.tar.gz`
+var platformRegex = regexp.MustCompile(`-(linux|darwin|windows)-([a-z0-9]+)\.tar\.gz$`)
+
+// Manifest lists all artifacts of a staged release
+type Manifest struct {
+	Version   string             `json:"version"`
+	Location  string             `json:"location"`
+	Artifacts []ManifestArtifact `json:"artifacts"`
+}
+
+// ManifestArtifact is a single file of a release
+type ManifestArtifact struct {
+	Name      string `json:"name"`
+	Platform  string `json:"platform,omitempty"`
+	Size      int64  `json:"size"`
+	SHA256    string `json:"sha256"`
+	SHA512    string `json:"sha512"`
+	Signature string `json:"signature,omitempty"`
+	URL       string `json:"url,omitempty"`
+}
+
+// WriteManifest writes the release manifest into `rootPath` for all
+// artifacts found recursively within it. The checksum and signature files
+// next to the artifacts are referenced by the artifacts instead of being
+// listed on their own. `location` is the remote path the artifacts will be
+// published to, for example `gs://kubernetes-release/release/v1.18.0`.
+// Already known `digests` are reused.
+//
+// The manifest has to be written before the checksums and signatures, so
+// that it is covered by both. If `signed` is set, all artifacts which will
+// be signed by SignArtifacts are referenced as signed, otherwise only the
+// ones with an existing signature.
+func WriteManifest(rootPath, version, location string, signed bool, digests Digests) error {
+	files, err := findFilesWithSuffix(rootPath, "")
+	if err != nil {
+		return errors.Wrapf(err, "finding files in %s", rootPath)
+	}
+
+	manifest := &Manifest{
+		Version:   version,
+		Location:  location,
+		Artifacts: []ManifestArtifact{},
+	}
+	for _, file := range files {
+		if isManifestSidecar(file) || filepath.Base(file) == ManifestFile {
+			continue
+		}
+
+		artifact, err := manifestArtifact(rootPath, file, location, signed, digests)
+		if err != nil {
+			return err
+		}
+		manifest.Artifacts = append(manifest.Artifacts, *artifact)
+	}
+
+	content, err := json.MarshalIndent(manifest, "", "  ")
+	if err != nil {
+		return errors.Wrap(err, "marshalling release manifest")
+	}
+
+	manifestPath := filepath.Join(rootPath, ManifestFile)
+	logrus.Infof(
+		"Writing release manifest for %d artifacts to %s",
+		len(manifest.Artifacts), manifestPath,
+	)
+	if err := ioutil.WriteFile(
+		manifestPath, content, os.FileMode(0644),
+	); err != nil {
+		return errors.Wrapf(err, "writing %s", manifestPath)
+	}
+	return nil
+}
+
+func manifestArtifact(
+	rootPath, file, location string, signed bool, digests Digests,
+) (*ManifestArtifact, error) {
+	rel, err := filepath.Rel(rootPath, file)
+	if err != nil {
+		return nil, err
+	}
+	name := filepath.ToSlash(rel)
+
+	fileDigests, err := digests.Of(file)
+	if err != nil {
+		return nil, err
+	}
+
+	artifact := &ManifestArtifact{
+		Name:   name,
+		Size:   fileDigests.Size,
+		SHA256: fileDigests.SHA256,
+		SHA512: fileDigests.SHA512,
+		URL:    location + "/" + name,
+	}
+
+	if match := platformRegex.FindStringSubmatch(path.Base(name)); match != nil {
+		artifact.Platform = fmt.Sprintf("%s/%s", match[1], match[2])
+	}
+
+	if (signed && isSignedArtifact(rootPath, file)) ||
+		util.Exists(file+signatureExtension) {
+		artifact.Signature = artifact.URL + signatureExtension
+	}
+
+	return artifact, nil
+}
+
+// isManifestSidecar returns true if the file is a checksum or signature of
+// another artifact.
+func isManifestSidecar(file string) bool {
+	if strings.HasSuffix(file, signatureExtension) {
+		return true
+	}
+	for _, algo := range checksumAlgorithms {
+		if strings.HasSuffix(file, algo.extension()) {
+			return true
+		}
+	}
+	return false
+}
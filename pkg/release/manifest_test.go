@@ -0,0 +1,108 @@
+/*
+Copyright 2020 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package release
+
+import (
+	"encoding/json"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestWriteManifest(t *testing.T) {
+	baseTmpDir, err := ioutil.TempDir("", "")
+	require.Nil(t, err)
+	defer cleanupTmps(t, baseTmpDir)
+
+	for _, file := range []string{
+		"kubernetes-client-linux-amd64.tar.gz",
+		"kubernetes-client-linux-amd64.tar.gz.asc",
+		"kubernetes.tar.gz",
+	} {
+		require.Nil(t, ioutil.WriteFile(
+			filepath.Join(baseTmpDir, file), []byte("test"), os.FileMode(0644),
+		))
+	}
+
+	location := "gs://bucket/release/v1.18.0"
+	require.Nil(t, WriteManifest(baseTmpDir, "v1.18.0", location, false, Digests{}))
+	require.Nil(t, WriteChecksums(baseTmpDir, Digests{}))
+
+	content, err := ioutil.ReadFile(filepath.Join(baseTmpDir, ManifestFile))
+	require.Nil(t, err)
+	manifest := &Manifest{}
+	require.Nil(t, json.Unmarshal(content, manifest))
+
+	require.Equal(t, "v1.18.0", manifest.Version)
+	require.Equal(t, location, manifest.Location)
+
+	artifacts := map[string]ManifestArtifact{}
+	for _, artifact := range manifest.Artifacts {
+		artifacts[artifact.Name] = artifact
+	}
+	require.Len(t, artifacts, 2)
+
+	client := artifacts["kubernetes-client-linux-amd64.tar.gz"]
+	require.Equal(t, "linux/amd64", client.Platform)
+	require.Equal(t, int64(4), client.Size)
+	require.Equal(t,
+		"9f86d081884c7d659a2feaa0c55ad015a3bf4f1b2b0b822cd15d6c15b0f00a08",
+		client.SHA256,
+	)
+	require.Equal(t, location+"/kubernetes-client-linux-amd64.tar.gz", client.URL)
+	require.Equal(t, client.URL+".asc", client.Signature)
+
+	tarball := artifacts["kubernetes.tar.gz"]
+	require.Empty(t, tarball.Platform)
+	require.Empty(t, tarball.Signature)
+
+	// The manifest is covered by the checksums
+	sums, err := ioutil.ReadFile(filepath.Join(baseTmpDir, "SHA256SUMS"))
+	require.Nil(t, err)
+	require.Contains(t, string(sums), "  "+ManifestFile+"\n")
+}
+
+func TestWriteManifestSigned(t *testing.T) {
+	baseTmpDir, err := ioutil.TempDir("", "")
+	require.Nil(t, err)
+	defer cleanupTmps(t, baseTmpDir)
+
+	for _, file := range []string{"kubernetes.tar.gz", "README.md"} {
+		require.Nil(t, ioutil.WriteFile(
+			filepath.Join(baseTmpDir, file), []byte("test"), os.FileMode(0644),
+		))
+	}
+	require.Nil(t, WriteManifest(baseTmpDir, "v1.18.0", "gs://bucket", true, Digests{}))
+
+	manifest, err := ReadManifest(LocalOpen(baseTmpDir))
+	require.Nil(t, err)
+	require.Len(t, manifest.Artifacts, 2)
+	for _, artifact := range manifest.Artifacts {
+		if artifact.Name == "kubernetes.tar.gz" {
+			require.Equal(t, "gs://bucket/kubernetes.tar.gz.asc", artifact.Signature)
+		} else {
+			require.Empty(t, artifact.Signature)
+		}
+	}
+}
+
+func TestWriteManifestNotExistingDir(t *testing.T) {
+	require.NotNil(t, WriteManifest("/not/existing", "v1.18.0", "gs://bucket", false, Digests{}))
+}
@@ -0,0 +1,152 @@
+/*
+Copyright 2020 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package release
+
+import (
+	"fmt"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"github.com/pkg/errors"
+	"github.com/sirupsen/logrus"
+
+	"k8s.io/release/pkg/command"
+)
+
+const (
+	// PinnedImagesPath is the directory of the digest pinned image snippets
+	// of a release
+	PinnedImagesPath = "pinned-images"
+
+	// KustomizeImagesFile is the kustomize snippet referencing the release
+	// images by digest
+	KustomizeImagesFile = "kustomization.yaml"
+
+	// HelmValuesFile is the Helm values snippet referencing the release
+	// images by digest
+	HelmValuesFile = "values.yaml"
+)
+
+// PinnedImage is a published release image resolved to its digest
+type PinnedImage struct {
+	Name       string
+	Repository string
+	Tag        string
+	Digest     string
+}
+
+// Reference returns the immutable reference of the image, like
+// gcr.io/google-containers/kube-proxy@sha256:...
+func (p *PinnedImage) Reference() string {
+	return p.Repository + "@" + p.Digest
+}
+
+// ImageDigest returns the digest the tagged image `ref` currently points to
+func ImageDigest(ref string) (string, error) {
+	output, err := command.New(
+		GCloudExecutable, "container", "images", "describe", ref,
+		"--format=value(image_summary.digest)",
+	).RunSilentSuccessOutput()
+	if err != nil {
+		return "", errors.Wrapf(err, "describing image %s", ref)
+	}
+	digest := output.OutputTrimNL()
+	if !strings.HasPrefix(digest, "sha256:") {
+		return "", errors.Errorf("invalid digest %q of image %s", digest, ref)
+	}
+	return digest, nil
+}
+
+// PinImages resolves the manifest lists of `images` tagged `tag` in
+// `registry` to their digests
+func PinImages(registry string, images []string, tag string) ([]PinnedImage, error) {
+	registry = strings.TrimSuffix(registry, "/")
+	res := []PinnedImage{}
+	for _, image := range images {
+		pinned := PinnedImage{
+			Name:       image,
+			Repository: registry + "/" + image,
+			Tag:        tag,
+		}
+		digest, err := ImageDigest(pinned.Repository + ":" + tag)
+		if err != nil {
+			return nil, err
+		}
+		pinned.Digest = digest
+		logrus.Infof("Pinned %s:%s to %s", pinned.Repository, tag, digest)
+		res = append(res, pinned)
+	}
+	return res, nil
+}
+
+// KustomizeImages returns a kustomization which replaces the tags of the
+// images by their digests
+func KustomizeImages(pinned []PinnedImage) string {
+	o := &strings.Builder{}
+	o.WriteString("images:\n")
+	for i := range pinned {
+		fmt.Fprintf(o, "- name: %s\n", pinned[i].Repository)
+		fmt.Fprintf(o, "  digest: %s\n", pinned[i].Digest)
+	}
+	return o.String()
+}
+
+// HelmValues returns Helm values with the repository, tag and digest of
+// every image, keyed by the camel cased image name
+func HelmValues(pinned []PinnedImage) string {
+	o := &strings.Builder{}
+	o.WriteString("images:\n")
+	for i := range pinned {
+		fmt.Fprintf(o, "  %s:\n", helmKey(pinned[i].Name))
+		fmt.Fprintf(o, "    repository: %s\n", pinned[i].Repository)
+		fmt.Fprintf(o, "    tag: %s\n", pinned[i].Tag)
+		fmt.Fprintf(o, "    digest: %s\n", pinned[i].Digest)
+	}
+	return o.String()
+}
+
+// WritePinnedImages writes the kustomize and Helm snippets of the pinned
+// images into `dir`
+func WritePinnedImages(dir string, pinned []PinnedImage) error {
+	if err := os.MkdirAll(dir, os.FileMode(0755)); err != nil {
+		return errors.Wrapf(err, "creating directory %s", dir)
+	}
+	for file, content := range map[string]string{
+		KustomizeImagesFile: KustomizeImages(pinned),
+		HelmValuesFile:      HelmValues(pinned),
+	} {
+		if err := ioutil.WriteFile(
+			filepath.Join(dir, file), []byte(content), os.FileMode(0644),
+		); err != nil {
+			return errors.Wrapf(err, "writing %s", file)
+		}
+	}
+	return nil
+}
+
+// helmKey converts an image name like kube-apiserver to kubeApiserver
+func helmKey(name string) string {
+	parts := strings.Split(name, "-")
+	for i := 1; i < len(parts); i++ {
+		if parts[i] != "" {
+			parts[i] = strings.ToUpper(parts[i][:1]) + parts[i][1:]
+		}
+	}
+	return strings.Join(parts, "")
+}
@@ -0,0 +1,94 @@
+/*
+Copyright 2020 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package release
+
+import (
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+var testPinnedImages = []PinnedImage{
+	{
+		Name:       "kube-apiserver",
+		Repository: "gcr.io/a/kube-apiserver",
+		Tag:        "v1.18.0",
+		Digest:     "sha256:111",
+	},
+	{
+		Name:       "conformance",
+		Repository: "gcr.io/a/conformance",
+		Tag:        "v1.18.0",
+		Digest:     "sha256:222",
+	},
+}
+
+func TestPinnedImageReference(t *testing.T) {
+	require.Equal(t, "gcr.io/a/kube-apiserver@sha256:111", testPinnedImages[0].Reference())
+}
+
+func TestKustomizeImages(t *testing.T) {
+	require.Equal(t, `images:
+- name: gcr.io/a/kube-apiserver
+  digest: sha256:111
+- name: gcr.io/a/conformance
+  digest: sha256:222
+`, KustomizeImages(testPinnedImages))
+}
+
+func TestHelmValues(t *testing.T) {
+	require.Equal(t, `images:
+  kubeApiserver:
+    repository: gcr.io/a/kube-apiserver
+    tag: v1.18.0
+    digest: sha256:111
+  conformance:
+    repository: gcr.io/a/conformance
+    tag: v1.18.0
+    digest: sha256:222
+`, HelmValues(testPinnedImages))
+}
+
+func TestWritePinnedImages(t *testing.T) {
+	tempDir, err := ioutil.TempDir("", "pinned-")
+	require.Nil(t, err)
+	defer os.RemoveAll(tempDir)
+
+	dir := filepath.Join(tempDir, PinnedImagesPath)
+	require.Nil(t, WritePinnedImages(dir, testPinnedImages))
+
+	content, err := ioutil.ReadFile(filepath.Join(dir, KustomizeImagesFile))
+	require.Nil(t, err)
+	require.Equal(t, KustomizeImages(testPinnedImages), string(content))
+
+	content, err = ioutil.ReadFile(filepath.Join(dir, HelmValuesFile))
+	require.Nil(t, err)
+	require.Equal(t, HelmValues(testPinnedImages), string(content))
+}
+
+func TestHelmKey(t *testing.T) {
+	for name, expected := range map[string]string{
+		"kube-apiserver":          "kubeApiserver",
+		"kube-controller-manager": "kubeControllerManager",
+		"conformance":             "conformance",
+	} {
+		require.Equal(t, expected, helmKey(name))
+	}
+}
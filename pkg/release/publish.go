@@ -0,0 +1,270 @@
+/*
+Copyright 2020 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package release
+
+import (
+	"context"
+	"fmt"
+	"io/ioutil"
+	"path"
+	"regexp"
+	"strings"
+
+	"cloud.google.com/go/storage"
+	"github.com/pkg/errors"
+	"github.com/sirupsen/logrus"
+
+	"k8s.io/release/pkg/gcp/gcs"
+	"k8s.io/release/pkg/util"
+)
+
+const (
+	// BuildTypeRelease is the build type of official releases
+	BuildTypeRelease = "release"
+
+	// BuildTypeCI is the build type of CI builds
+	BuildTypeCI = "ci"
+
+	markerExtension = ".txt"
+)
+
+// markerRegex matches the names of version markers like `stable-1.18`
+var markerRegex = regexp.MustCompile(`^[a-z0-9][a-z0-9.-]*$`)
+
+// VersionMarkers returns the paths of the version marker files, relative to
+// the bucket root, which point to `version` after publishing it for
+// `buildType`. These are for example `release/stable.txt`,
+// `release/stable-1.txt` and `release/stable-1.18.txt` for an official
+// release, or the `latest` variants for pre-releases and CI builds.
+// `extraPublishFile` is published as an additional marker if not empty.
+func VersionMarkers(buildType, version, extraPublishFile string) ([]string, error) {
+	return versionMarkers(buildType, buildType, version, extraPublishFile)
+}
+
+// versionMarkers returns the version markers like VersionMarkers, but
+// within `dir`, which is the build type with an optional suffix.
+func versionMarkers(buildType, dir, version, extraPublishFile string) ([]string, error) {
+	sv, err := util.TagStringToSemver(version)
+	if err != nil {
+		return nil, errors.Wrapf(err, "parsing version %s", version)
+	}
+
+	markerType := "latest"
+	if buildType == BuildTypeRelease && len(sv.Pre) == 0 {
+		markerType = "stable"
+	}
+
+	names := []string{
+		markerType,
+		fmt.Sprintf("%s-%d", markerType, sv.Major),
+		fmt.Sprintf("%s-%d.%d", markerType, sv.Major, sv.Minor),
+	}
+	if extraPublishFile != "" {
+		names = append(names, extraPublishFile)
+	}
+
+	res := []string{}
+	for _, name := range names {
+		marker, err := MarkerPath(dir, name)
+		if err != nil {
+			return nil, err
+		}
+		res = append(res, marker)
+	}
+	return res, nil
+}
+
+// MarkerPath returns the path of the version marker `name` within the
+// directory of a build type, for example `release/stable-1.18.txt`.
+func MarkerPath(dir, name string) (string, error) {
+	if !markerRegex.MatchString(name) {
+		return "", errors.Errorf("invalid version marker name %q", name)
+	}
+	return path.Join(dir, name+markerExtension), nil
+}
+
+// IsNewerVersion returns true if `version` should replace the `published`
+// version of a marker for `buildType`. Official release markers are only
+// updated by strictly newer versions, to keep the timestamp of the original
+// publish. CI markers are updated by equal versions, too.
+func IsNewerVersion(buildType, version, published string) (bool, error) {
+	sv, err := util.TagStringToSemver(version)
+	if err != nil {
+		return false, errors.Wrapf(err, "parsing version %s", version)
+	}
+	psv, err := util.TagStringToSemver(published)
+	if err != nil {
+		return false, errors.Wrapf(err, "parsing published version %s", published)
+	}
+
+	if buildType == BuildTypeCI {
+		return sv.GTE(psv), nil
+	}
+	return sv.GT(psv), nil
+}
+
+// PublishVersion updates all version markers of `version` in `bucket`, but
+// only if the release exists and the markers do not already point to a newer
+// version. The release and the markers are located in the directory of the
+// `buildType` with the `gcsSuffix` appended, for example `ci-cross`.
+// Replaces release::gcs::publish_version
+func PublishVersion(
+	ctx context.Context, bucket *storage.BucketHandle,
+	buildType, gcsSuffix, version, extraPublishFile string,
+) error {
+	dir := buildType + gcsSuffix
+	releasePath := path.Join(dir, version)
+	exists, err := gcs.PathExists(ctx, bucket, releasePath+"/")
+	if err != nil {
+		return errors.Wrapf(err, "checking release files of %s", version)
+	}
+	if !exists {
+		return errors.Errorf("release files don't exist at %s", releasePath)
+	}
+
+	markers, err := versionMarkers(buildType, dir, version, extraPublishFile)
+	if err != nil {
+		return err
+	}
+
+	for _, marker := range markers {
+		marker := marker
+		if _, err := UpdateMarker(ctx, bucket, marker, version, func(published string) (bool, error) {
+			if published == "" {
+				return true, nil
+			}
+			newer, err := IsNewerVersion(buildType, version, published)
+			if err != nil {
+				return false, errors.Wrapf(err, "comparing with %s", marker)
+			}
+			if !newer {
+				logrus.Infof(
+					"Not updating %s, version %s is not newer than %s",
+					marker, version, published,
+				)
+			}
+			return newer, nil
+		}); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// ReadMarker returns the version the marker at `marker` points to, or an
+// empty string if the marker does not exist.
+func ReadMarker(
+	ctx context.Context, bucket *storage.BucketHandle, marker string,
+) (string, error) {
+	version, _, err := readMarker(ctx, bucket, marker)
+	return version, err
+}
+
+// readMarker returns the version of the marker like ReadMarker together with
+// the generation of the marker object, which is zero if it does not exist.
+// The generation is read first, so that it is never newer than the version.
+func readMarker(
+	ctx context.Context, bucket *storage.BucketHandle, marker string,
+) (version string, generation int64, err error) {
+	attrs, err := bucket.Object(marker).Attrs(ctx)
+	if err == storage.ErrObjectNotExist {
+		return "", 0, nil
+	}
+	if err != nil {
+		return "", 0, errors.Wrapf(err, "reading attributes of version marker %s", marker)
+	}
+
+	r, err := gcs.NewReader(ctx, bucket, marker)
+	if errors.Cause(err) == storage.ErrObjectNotExist {
+		return "", 0, nil
+	}
+	if err != nil {
+		return "", 0, errors.Wrapf(err, "opening version marker %s", marker)
+	}
+	defer r.Close()
+
+	content, err := ioutil.ReadAll(r)
+	if err != nil {
+		return "", 0, errors.Wrapf(err, "reading version marker %s", marker)
+	}
+	return strings.TrimSpace(string(content)), attrs.Generation, nil
+}
+
+// WriteMarker points the marker at `marker` to `version`, regardless of the
+// version it currently points to.
+func WriteMarker(
+	ctx context.Context, bucket *storage.BucketHandle, marker, version string,
+) error {
+	_, err := UpdateMarker(ctx, bucket, marker, version, func(string) (bool, error) {
+		return true, nil
+	})
+	return err
+}
+
+// UpdateMarker points the marker at `marker` to `version` if `shouldUpdate`
+// returns true for the version the marker currently points to, which is
+// empty if the marker does not exist. The marker is only written if it did
+// not change since it was read. Otherwise it is read again and
+// `shouldUpdate` is called with the new version. The marker is uploaded
+// uncached to make the new version visible immediately. Returns true if
+// the marker was written.
+func UpdateMarker(
+	ctx context.Context, bucket *storage.BucketHandle, marker, version string,
+	shouldUpdate func(current string) (bool, error),
+) (bool, error) {
+	retryOpts := util.DefaultRetryOptions()
+	retryOpts.Context = ctx
+	retryOpts.Retryable = gcs.IsPreconditionFailed
+	for shouldRetry := util.Retrier(retryOpts); ; {
+		updated, err := updateMarker(ctx, bucket, marker, version, shouldUpdate)
+		if !shouldRetry(err) {
+			return updated, err
+		}
+	}
+}
+
+func updateMarker(
+	ctx context.Context, bucket *storage.BucketHandle, marker, version string,
+	shouldUpdate func(current string) (bool, error),
+) (bool, error) {
+	current, generation, err := readMarker(ctx, bucket, marker)
+	if err != nil {
+		return false, err
+	}
+	update, err := shouldUpdate(current)
+	if err != nil || !update {
+		return false, err
+	}
+
+	logrus.Infof("Publishing version %s to %s", version, marker)
+	opts := gcs.DefaultOptions()
+	opts.CacheControl = gcs.NoCacheControl
+	opts.Conditions = markerConditions(generation)
+	if err := gcs.WriteString(ctx, bucket, marker, version, opts); err != nil {
+		return false, errors.Wrapf(err, "publishing version marker %s", marker)
+	}
+	return true, nil
+}
+
+// markerConditions returns the preconditions for replacing the marker
+// object of `generation`, which does not exist if it is zero.
+func markerConditions(generation int64) *storage.Conditions {
+	if generation == 0 {
+		return &storage.Conditions{DoesNotExist: true}
+	}
+	return &storage.Conditions{GenerationMatch: generation}
+}
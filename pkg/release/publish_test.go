@@ -0,0 +1,173 @@
+/*
+Copyright 2020 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package release
+
+import (
+	"testing"
+
+	"cloud.google.com/go/storage"
+	"github.com/stretchr/testify/require"
+)
+
+func TestVersionMarkers(t *testing.T) {
+	for name, tc := range map[string]struct {
+		buildType string
+		version   string
+		extra     string
+		expected  []string
+		shouldErr bool
+	}{
+		"Stable": {
+			buildType: BuildTypeRelease,
+			version:   "v1.18.2",
+			expected: []string{
+				"release/stable.txt",
+				"release/stable-1.txt",
+				"release/stable-1.18.txt",
+			},
+		},
+		"PreRelease": {
+			buildType: BuildTypeRelease,
+			version:   "v1.19.0-beta.0",
+			expected: []string{
+				"release/latest.txt",
+				"release/latest-1.txt",
+				"release/latest-1.19.txt",
+			},
+		},
+		"CIWithExtraFile": {
+			buildType: BuildTypeCI,
+			version:   "v1.19.0-alpha.1.123+0123456789abcd",
+			extra:     "k8s-master",
+			expected: []string{
+				"ci/latest.txt",
+				"ci/latest-1.txt",
+				"ci/latest-1.19.txt",
+				"ci/k8s-master.txt",
+			},
+		},
+		"InvalidVersion": {
+			buildType: BuildTypeRelease,
+			version:   "1.18",
+			shouldErr: true,
+		},
+		"InvalidExtraFile": {
+			buildType: BuildTypeCI,
+			version:   "v1.18.2",
+			extra:     "../stable",
+			shouldErr: true,
+		},
+	} {
+		t.Run(name, func(t *testing.T) {
+			res, err := VersionMarkers(tc.buildType, tc.version, tc.extra)
+			if tc.shouldErr {
+				require.NotNil(t, err)
+				return
+			}
+			require.Nil(t, err)
+			require.Equal(t, tc.expected, res)
+		})
+	}
+}
+
+func TestIsNewerVersion(t *testing.T) {
+	for name, tc := range map[string]struct {
+		buildType string
+		version   string
+		published string
+		expected  bool
+		shouldErr bool
+	}{
+		"NewerPatch": {
+			buildType: BuildTypeRelease,
+			version:   "v1.18.3",
+			published: "v1.18.2",
+			expected:  true,
+		},
+		"OlderMinor": {
+			buildType: BuildTypeRelease,
+			version:   "v1.17.6",
+			published: "v1.18.2",
+		},
+		"FinalAfterRC": {
+			buildType: BuildTypeRelease,
+			version:   "v1.18.0",
+			published: "v1.18.0-rc.1",
+			expected:  true,
+		},
+		"BetaAfterAlpha": {
+			buildType: BuildTypeRelease,
+			version:   "v1.19.0-beta.0",
+			published: "v1.19.0-alpha.3",
+			expected:  true,
+		},
+		"ReleaseEqual": {
+			buildType: BuildTypeRelease,
+			version:   "v1.18.2",
+			published: "v1.18.2",
+		},
+		"CIEqual": {
+			buildType: BuildTypeCI,
+			version:   "v1.19.0-alpha.1.123+0123456789abcd",
+			published: "v1.19.0-alpha.1.123+0123456789abcd",
+			expected:  true,
+		},
+		"CIMoreCommits": {
+			buildType: BuildTypeCI,
+			version:   "v1.19.0-alpha.1.124+0123456789abcd",
+			published: "v1.19.0-alpha.1.123+0123456789abcd",
+			expected:  true,
+		},
+		"CINoCommitsBefore": {
+			buildType: BuildTypeCI,
+			version:   "v1.19.0-alpha.1.1+0123456789abcd",
+			published: "v1.19.0-alpha.1",
+			expected:  true,
+		},
+		"InvalidPublished": {
+			buildType: BuildTypeRelease,
+			version:   "v1.18.2",
+			published: "<html>",
+			shouldErr: true,
+		},
+	} {
+		t.Run(name, func(t *testing.T) {
+			res, err := IsNewerVersion(tc.buildType, tc.version, tc.published)
+			if tc.shouldErr {
+				require.NotNil(t, err)
+				return
+			}
+			require.Nil(t, err)
+			require.Equal(t, tc.expected, res)
+		})
+	}
+}
+
+func TestVersionMarkersWithSuffix(t *testing.T) {
+	res, err := versionMarkers(BuildTypeCI, "ci-cross", "v1.19.0-alpha.1.123+0123456789abcd", "")
+	require.Nil(t, err)
+	require.Equal(t, []string{
+		"ci-cross/latest.txt",
+		"ci-cross/latest-1.txt",
+		"ci-cross/latest-1.19.txt",
+	}, res)
+}
+
+func TestMarkerConditions(t *testing.T) {
+	require.Equal(t, &storage.Conditions{DoesNotExist: true}, markerConditions(0))
+	require.Equal(t, &storage.Conditions{GenerationMatch: 42}, markerConditions(42))
+}
@@ -0,0 +1,99 @@
+/*
+Copyright 2020 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package release
+
+import (
+	"context"
+	"encoding/json"
+	"path"
+	"time"
+
+	"cloud.google.com/go/storage"
+	"github.com/pkg/errors"
+	"github.com/sirupsen/logrus"
+
+	"k8s.io/release/pkg/gcp/gcs"
+)
+
+// RollbackFile is the name of the rollback record written next to the
+// artifacts of a rolled back release
+const RollbackFile = "rollback.json"
+
+// RollbackRecord documents the rollback of a published release
+type RollbackRecord struct {
+	Version    string    `json:"version"`
+	RolledBack string    `json:"rolled_back_to"`
+	User       string    `json:"user"`
+	Reason     string    `json:"reason,omitempty"`
+	Markers    []string  `json:"markers"`
+	Time       time.Time `json:"time"`
+}
+
+// RollbackMarkers returns the version markers which can be pointed back from
+// `version` to the previous release `to`. These are the markers which are
+// published for both versions, for example `release/stable-1.18.txt` when
+// rolling back from v1.18.3 to v1.18.2, but not `release/stable-1.19.txt`
+// when rolling back from v1.19.0 to v1.18.5.
+func RollbackMarkers(buildType, version, to string) ([]string, error) {
+	from, err := VersionMarkers(buildType, version, "")
+	if err != nil {
+		return nil, err
+	}
+	previous, err := VersionMarkers(buildType, to, "")
+	if err != nil {
+		return nil, err
+	}
+
+	shared := map[string]bool{}
+	for _, marker := range previous {
+		shared[marker] = true
+	}
+
+	res := []string{}
+	for _, marker := range from {
+		if shared[marker] {
+			res = append(res, marker)
+		} else {
+			logrus.Warnf(
+				"Marker %s does not apply to %s and has to be fixed manually",
+				marker, to,
+			)
+		}
+	}
+	return res, nil
+}
+
+// WriteRollbackRecord stores the record next to the artifacts of the rolled
+// back release in `bucket`.
+func WriteRollbackRecord(
+	ctx context.Context, bucket *storage.BucketHandle, buildType string,
+	record *RollbackRecord,
+) error {
+	content, err := json.MarshalIndent(record, "", "  ")
+	if err != nil {
+		return errors.Wrap(err, "marshalling rollback record")
+	}
+
+	dst := path.Join(buildType, record.Version, RollbackFile)
+	logrus.Infof("Recording rollback of %s in %s", record.Version, dst)
+	opts := gcs.DefaultOptions()
+	opts.CacheControl = gcs.NoCacheControl
+	if err := gcs.WriteString(ctx, bucket, dst, string(content), opts); err != nil {
+		return errors.Wrapf(err, "writing rollback record %s", dst)
+	}
+	return nil
+}
@@ -0,0 +1,70 @@
+/*
+Copyright 2020 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package release
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestRollbackMarkers(t *testing.T) {
+	for name, tc := range map[string]struct {
+		version   string
+		to        string
+		expected  []string
+		shouldErr bool
+	}{
+		"SameMinor": {
+			version: "v1.18.3",
+			to:      "v1.18.2",
+			expected: []string{
+				"release/stable.txt",
+				"release/stable-1.txt",
+				"release/stable-1.18.txt",
+			},
+		},
+		"PreviousMinor": {
+			version: "v1.19.0",
+			to:      "v1.18.5",
+			expected: []string{
+				"release/stable.txt",
+				"release/stable-1.txt",
+			},
+		},
+		"FinalToPreRelease": {
+			version:  "v1.19.0",
+			to:       "v1.19.0-rc.1",
+			expected: []string{},
+		},
+		"InvalidVersion": {
+			version:   "v1.19.0",
+			to:        "latest",
+			shouldErr: true,
+		},
+	} {
+		t.Run(name, func(t *testing.T) {
+			res, err := RollbackMarkers(BuildTypeRelease, tc.version, tc.to)
+			if tc.shouldErr {
+				require.NotNil(t, err)
+				return
+			}
+			require.Nil(t, err)
+			require.Equal(t, tc.expected, res)
+		})
+	}
+}
@@ -0,0 +1,183 @@
+/*
+Copyright 2020 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package release
+
+import (
+	"encoding/json"
+	"io/ioutil"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/pkg/errors"
+	"sigs.k8s.io/yaml"
+
+	"k8s.io/release/pkg/util"
+)
+
+// ScheduleDateFormat is the format of the dates in a release schedule
+const ScheduleDateFormat = "2006-01-02"
+
+// Date is a calendar day of a release schedule
+type Date struct {
+	time.Time
+}
+
+// UnmarshalJSON parses a date in the ScheduleDateFormat
+func (d *Date) UnmarshalJSON(b []byte) error {
+	var s string
+	if err := json.Unmarshal(b, &s); err != nil {
+		return errors.Wrap(err, "parsing date")
+	}
+	t, err := time.Parse(ScheduleDateFormat, s)
+	if err != nil {
+		return errors.Wrapf(err, "parsing date %q", s)
+	}
+	d.Time = t
+	return nil
+}
+
+// MarshalJSON formats the date in the ScheduleDateFormat
+func (d Date) MarshalJSON() ([]byte, error) {
+	return json.Marshal(d.Format(ScheduleDateFormat))
+}
+
+// Milestone is a single date of a release schedule, optionally with the
+// version which gets released at it
+type Milestone struct {
+	Name    string `json:"name"`
+	Version string `json:"version,omitempty"`
+	Date    Date   `json:"date"`
+}
+
+// Schedule is a release calendar, as maintained by the release team in a
+// YAML file, for example:
+//
+//	# schedule.yaml
+//	- name: Code Freeze
+//	  date: "2020-07-09"
+//	- name: v1.19.0-rc.1
+//	  version: v1.19.0-rc.1
+//	  date: "2020-08-11"
+//	- name: v1.19.0
+//	  version: v1.19.0
+//	  date: "2020-08-25"
+type Schedule []Milestone
+
+// ParseSchedule reads and validates the release schedule from the provided
+// YAML file. The milestones are sorted by their dates.
+func ParseSchedule(path string) (Schedule, error) {
+	content, err := ioutil.ReadFile(path)
+	if err != nil {
+		return nil, errors.Wrapf(err, "reading schedule file %s", path)
+	}
+
+	schedule := Schedule{}
+	if err := yaml.UnmarshalStrict(content, &schedule); err != nil {
+		return nil, errors.Wrapf(err, "parsing schedule file %s", path)
+	}
+
+	versions := map[string]bool{}
+	for _, milestone := range schedule {
+		if strings.TrimSpace(milestone.Name) == "" {
+			return nil, errors.Errorf("schedule file %s contains a milestone without name", path)
+		}
+		if milestone.Date.IsZero() {
+			return nil, errors.Errorf("milestone %q has no date", milestone.Name)
+		}
+		if milestone.Version == "" {
+			continue
+		}
+		if _, err := util.TagStringToSemver(milestone.Version); err != nil {
+			return nil, errors.Wrapf(err, "milestone %q has an invalid version", milestone.Name)
+		}
+		if versions[milestone.Version] {
+			return nil, errors.Errorf(
+				"version %s is scheduled more than once", milestone.Version,
+			)
+		}
+		versions[milestone.Version] = true
+	}
+
+	sort.SliceStable(schedule, func(i, j int) bool {
+		return schedule[i].Date.Before(schedule[j].Date.Time)
+	})
+	return schedule, nil
+}
+
+// Check verifies that `version` is scheduled within `tolerance` days around
+// the day of `now`.
+func (s Schedule) Check(version string, now time.Time, tolerance int) error {
+	today := day(now)
+	for _, milestone := range s {
+		if milestone.Version != version {
+			continue
+		}
+		earliest := milestone.Date.AddDate(0, 0, -tolerance)
+		latest := milestone.Date.AddDate(0, 0, tolerance)
+		if today.Before(earliest) || today.After(latest) {
+			return errors.Errorf(
+				"%s is scheduled for %s, today is %s",
+				version,
+				milestone.Date.Format(ScheduleDateFormat),
+				today.Format(ScheduleDateFormat),
+			)
+		}
+		return nil
+	}
+	return errors.Errorf("%s is not part of the release schedule", version)
+}
+
+// Due returns the milestones with a version which are scheduled within
+// `tolerance` days around the day of `now`.
+func (s Schedule) Due(now time.Time, tolerance int) Schedule {
+	today := day(now)
+	due := Schedule{}
+	for _, milestone := range s {
+		if milestone.Version == "" {
+			continue
+		}
+		if today.Before(milestone.Date.AddDate(0, 0, -tolerance)) ||
+			today.After(milestone.Date.AddDate(0, 0, tolerance)) {
+			continue
+		}
+		due = append(due, milestone)
+	}
+	return due
+}
+
+// Next returns up to `count` milestones which are due at the day of `now` or
+// later. All upcoming milestones are returned if `count` is not positive.
+func (s Schedule) Next(now time.Time, count int) Schedule {
+	today := day(now)
+	next := Schedule{}
+	for _, milestone := range s {
+		if milestone.Date.Before(today) {
+			continue
+		}
+		if count > 0 && len(next) == count {
+			break
+		}
+		next = append(next, milestone)
+	}
+	return next
+}
+
+// day truncates `t` to the calendar day, as used for the schedule dates
+func day(t time.Time) time.Time {
+	return time.Date(t.Year(), t.Month(), t.Day(), 0, 0, 0, 0, time.UTC)
+}
@@ -0,0 +1,178 @@
+/*
+Copyright 2020 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package release
+
+import (
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+)
+
+const testSchedule = `
+- name: v1.19.0
+  version: v1.19.0
+  date: "2020-08-25"
+- name: Code Freeze
+  date: "2020-07-09"
+- name: v1.19.0-rc.4
+  version: v1.19.0-rc.4
+  date: "2020-08-11"
+`
+
+func parseTestSchedule(t *testing.T, content string) (Schedule, error) {
+	dir, err := ioutil.TempDir("", "schedule-")
+	require.Nil(t, err)
+	defer os.RemoveAll(dir)
+	path := filepath.Join(dir, "schedule.yaml")
+	require.Nil(t, ioutil.WriteFile(path, []byte(content), 0644))
+	return ParseSchedule(path)
+}
+
+func TestParseSchedule(t *testing.T) {
+	for name, tc := range map[string]struct {
+		content   string
+		expected  []string
+		shouldErr bool
+	}{
+		"Sorted": {
+			content:  testSchedule,
+			expected: []string{"Code Freeze", "v1.19.0-rc.4", "v1.19.0"},
+		},
+		"InvalidDate": {
+			content:   "- name: v1.19.0\n  date: 25.08.2020\n",
+			shouldErr: true,
+		},
+		"MissingDate": {
+			content:   "- name: v1.19.0\n",
+			shouldErr: true,
+		},
+		"MissingName": {
+			content:   "- date: \"2020-08-25\"\n",
+			shouldErr: true,
+		},
+		"InvalidVersion": {
+			content:   "- name: release\n  version: wrong\n  date: \"2020-08-25\"\n",
+			shouldErr: true,
+		},
+		"DuplicateVersion": {
+			content: `- name: first
+  version: v1.19.0
+  date: "2020-08-25"
+- name: second
+  version: v1.19.0
+  date: "2020-08-26"
+`,
+			shouldErr: true,
+		},
+		"UnknownField": {
+			content:   "- name: v1.19.0\n  date: \"2020-08-25\"\n  owner: me\n",
+			shouldErr: true,
+		},
+	} {
+		t.Run(name, func(t *testing.T) {
+			schedule, err := parseTestSchedule(t, tc.content)
+			if tc.shouldErr {
+				require.NotNil(t, err)
+				return
+			}
+			require.Nil(t, err)
+			names := []string{}
+			for _, milestone := range schedule {
+				names = append(names, milestone.Name)
+			}
+			require.Equal(t, tc.expected, names)
+		})
+	}
+}
+
+func TestScheduleCheck(t *testing.T) {
+	schedule, err := parseTestSchedule(t, testSchedule)
+	require.Nil(t, err)
+
+	for name, tc := range map[string]struct {
+		version   string
+		now       time.Time
+		tolerance int
+		shouldErr bool
+	}{
+		"SameDay": {
+			version: "v1.19.0",
+			now:     time.Date(2020, 8, 25, 23, 59, 0, 0, time.UTC),
+		},
+		"DayBeforeWithTolerance": {
+			version:   "v1.19.0",
+			now:       time.Date(2020, 8, 24, 10, 0, 0, 0, time.UTC),
+			tolerance: 1,
+		},
+		"DayBeforeWithoutTolerance": {
+			version:   "v1.19.0",
+			now:       time.Date(2020, 8, 24, 10, 0, 0, 0, time.UTC),
+			shouldErr: true,
+		},
+		"TooLate": {
+			version:   "v1.19.0-rc.4",
+			now:       time.Date(2020, 8, 25, 10, 0, 0, 0, time.UTC),
+			tolerance: 1,
+			shouldErr: true,
+		},
+		"NotScheduled": {
+			version:   "v1.19.1",
+			now:       time.Date(2020, 8, 25, 10, 0, 0, 0, time.UTC),
+			shouldErr: true,
+		},
+	} {
+		t.Run(name, func(t *testing.T) {
+			err := schedule.Check(tc.version, tc.now, tc.tolerance)
+			if tc.shouldErr {
+				require.NotNil(t, err)
+			} else {
+				require.Nil(t, err)
+			}
+		})
+	}
+}
+
+func TestScheduleNext(t *testing.T) {
+	schedule, err := parseTestSchedule(t, testSchedule)
+	require.Nil(t, err)
+
+	now := time.Date(2020, 8, 11, 12, 0, 0, 0, time.UTC)
+	next := schedule.Next(now, 0)
+	require.Len(t, next, 2)
+	require.Equal(t, "v1.19.0-rc.4", next[0].Name)
+	require.Equal(t, "v1.19.0", next[1].Name)
+
+	require.Len(t, schedule.Next(now, 1), 1)
+	require.Empty(t, schedule.Next(now.AddDate(0, 1, 0), 0))
+}
+
+func TestScheduleDue(t *testing.T) {
+	schedule, err := parseTestSchedule(t, testSchedule)
+	require.Nil(t, err)
+
+	due := schedule.Due(time.Date(2020, 8, 12, 12, 0, 0, 0, time.UTC), 1)
+	require.Len(t, due, 1)
+	require.Equal(t, "v1.19.0-rc.4", due[0].Version)
+
+	// Milestones without a version are never due
+	require.Empty(t, schedule.Due(time.Date(2020, 7, 9, 12, 0, 0, 0, time.UTC), 0))
+	require.Empty(t, schedule.Due(time.Date(2020, 8, 13, 12, 0, 0, 0, time.UTC), 1))
+}
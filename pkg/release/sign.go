@@ -0,0 +1,124 @@
+/*
+Copyright 2020 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package release
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+
+	"github.com/pkg/errors"
+	"github.com/sirupsen/logrus"
+
+	"k8s.io/release/pkg/command"
+)
+
+const (
+	// GPGExecutable is the binary used for creating signatures.
+	GPGExecutable = "gpg"
+
+	signatureExtension = ".asc"
+)
+
+// SignFile creates an ASCII armored detached GPG signature for the file at
+// `path`. The signature is written next to the file with the `.asc` suffix.
+// If `keyID` is empty, gpg falls back to its default key, which allows
+// signing via an already configured gpg-agent.
+func SignFile(path, keyID string) error {
+	args := []string{"--batch", "--yes", "--armor", "--detach-sign"}
+	if keyID != "" {
+		args = append(args, "--local-user", keyID)
+	}
+	args = append(args, "--output", path+signatureExtension, path)
+
+	if err := command.New(GPGExecutable, args...).RunSilentSuccess(); err != nil {
+		return errors.Wrapf(err, "signing file %s", path)
+	}
+	return nil
+}
+
+// VerifySignature verifies the detached signature `path.asc` of the file at
+// `path` against the keys of `keyring` only, ignoring the keys of the
+// default keyring. The keyring is a file of public keys exported via
+// `gpg --export`.
+func VerifySignature(path, keyring string) error {
+	keyring, err := filepath.Abs(keyring)
+	if err != nil {
+		return errors.Wrapf(err, "resolving keyring path")
+	}
+	if err := command.New(
+		GPGExecutable, "--batch", "--no-default-keyring", "--keyring", keyring,
+		"--verify", path+signatureExtension, path,
+	).RunSilentSuccess(); err != nil {
+		return errors.Wrapf(err, "verifying signature of %s", path)
+	}
+	return nil
+}
+
+// SignArtifacts creates detached GPG signatures for all tarballs and binary
+// deltas found recursively within `dir` as well as for the consolidated
+// checksum files and the release manifest in `dir`, if they exist.
+func SignArtifacts(dir, keyID string) error {
+	found, err := findFilesWithSuffix(dir, "")
+	if err != nil {
+		return errors.Wrapf(err, "finding artifacts in %s", dir)
+	}
+
+	for _, file := range found {
+		if !isSignedArtifact(dir, file) {
+			continue
+		}
+		logrus.Infof("Signing %s", file)
+		if err := SignFile(file, keyID); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// isSignedArtifact returns true if SignArtifacts signs the `file` within
+// `dir`.
+func isSignedArtifact(dir, file string) bool {
+	if strings.HasSuffix(file, tarballExtension) ||
+		strings.HasSuffix(file, deltaExtension) ||
+		filepath.Base(file) == DeltaTargetsFile ||
+		file == filepath.Join(dir, ManifestFile) {
+		return true
+	}
+	for _, sumsFile := range ChecksumFiles() {
+		if file == filepath.Join(dir, sumsFile) {
+			return true
+		}
+	}
+	return false
+}
+
+// findFilesWithSuffix returns all regular files below `dir` which end with
+// `suffix`.
+func findFilesWithSuffix(dir, suffix string) ([]string, error) {
+	res := []string{}
+	err := filepath.Walk(dir, func(path string, info os.FileInfo, err error) error {
+		if err != nil {
+			return err
+		}
+		if info.Mode().IsRegular() && strings.HasSuffix(path, suffix) {
+			res = append(res, path)
+		}
+		return nil
+	})
+	return res, err
+}
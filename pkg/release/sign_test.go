@@ -0,0 +1,83 @@
+/*
+Copyright 2020 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package release
+
+import (
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestFindFilesWithSuffix(t *testing.T) {
+	baseTmpDir, err := ioutil.TempDir("", "")
+	require.Nil(t, err)
+	defer cleanupTmps(t, baseTmpDir)
+
+	require.Nil(t, os.MkdirAll(filepath.Join(baseTmpDir, "extra"), os.ModePerm))
+	for _, file := range []string{
+		"kubernetes.tar.gz",
+		"kubernetes.tar.gz.asc",
+		"README.md",
+		"extra/kubernetes-client.tar.gz",
+	} {
+		require.Nil(t, ioutil.WriteFile(
+			filepath.Join(baseTmpDir, file),
+			[]byte("test"),
+			os.FileMode(0644),
+		))
+	}
+
+	res, err := findFilesWithSuffix(baseTmpDir, tarballExtension)
+	require.Nil(t, err)
+	require.Equal(t, []string{
+		filepath.Join(baseTmpDir, "extra/kubernetes-client.tar.gz"),
+		filepath.Join(baseTmpDir, "kubernetes.tar.gz"),
+	}, res)
+}
+
+func TestFindFilesWithSuffixNotExisting(t *testing.T) {
+	_, err := findFilesWithSuffix("/not/existing", tarballExtension)
+	require.NotNil(t, err)
+}
+
+func TestSignArtifactsEmptyDir(t *testing.T) {
+	baseTmpDir, err := ioutil.TempDir("", "")
+	require.Nil(t, err)
+	defer cleanupTmps(t, baseTmpDir)
+
+	require.Nil(t, SignArtifacts(baseTmpDir, ""))
+}
+
+func TestIsSignedArtifact(t *testing.T) {
+	for file, expected := range map[string]bool{
+		"/stage/kubernetes.tar.gz":                       true,
+		"/stage/extra/client.tar.gz":                     true,
+		"/stage/SHA256SUMS":                              true,
+		"/stage/SHA512SUMS":                              true,
+		"/stage/" + ManifestFile:                         true,
+		"/stage/deltas/v1.18.0/kubernetes.tar.zst-patch": true,
+		"/stage/deltas/v1.18.0/" + DeltaTargetsFile:      true,
+		"/stage/extra/SHA256SUMS":                        false,
+		"/stage/kubernetes.tar.gz.sha256":                false,
+		"/stage/README.md":                               false,
+	} {
+		require.Equal(t, expected, isSignedArtifact("/stage", file), file)
+	}
+}
@@ -0,0 +1,235 @@
+/*
+Copyright 2020 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package release
+
+import (
+	"bufio"
+	"encoding/json"
+	"io"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"github.com/pkg/errors"
+
+	"k8s.io/release/pkg/util"
+)
+
+// OpenFunc returns the content of a published release file by its path
+// relative to the release location.
+type OpenFunc func(name string) (io.ReadCloser, error)
+
+// ArtifactVerification is the result of verifying a single release artifact.
+// Every check is nil if it passed.
+type ArtifactVerification struct {
+	Name      string
+	Size      error
+	SHA256    error
+	SHA512    error
+	Sums      error
+	Signature error
+}
+
+// Passed returns true if all checks of the artifact passed
+func (a *ArtifactVerification) Passed() bool {
+	return a.Size == nil && a.SHA256 == nil && a.SHA512 == nil &&
+		a.Sums == nil && a.Signature == nil
+}
+
+// ReadManifest reads the release manifest written by WriteManifest from the
+// published release.
+func ReadManifest(open OpenFunc) (*Manifest, error) {
+	return readManifestFile(open, ManifestFile)
+}
+
+// readManifestFile reads the manifest `name`, which is either the release
+// or the bundle manifest
+func readManifestFile(open OpenFunc, name string) (*Manifest, error) {
+	r, err := open(name)
+	if err != nil {
+		return nil, errors.Wrapf(err, "opening %s", name)
+	}
+	defer r.Close()
+
+	manifest := &Manifest{}
+	if err := json.NewDecoder(r).Decode(manifest); err != nil {
+		return nil, errors.Wrapf(err, "decoding %s", name)
+	}
+	return manifest, nil
+}
+
+// Files returns the paths of all files of the release listed by the
+// manifest, including the signatures of signed artifacts. The manifest and
+// the checksum files, which are not listed in the manifest because they
+// cover it, are included as well.
+func (m *Manifest) Files() []string {
+	res := append([]string{ManifestFile}, ChecksumFiles()...)
+	included := map[string]bool{}
+	for _, name := range res {
+		included[name] = true
+	}
+	for i := range m.Artifacts {
+		if included[m.Artifacts[i].Name] {
+			continue
+		}
+		res = append(res, m.Artifacts[i].Name)
+		if m.Artifacts[i].Signature != "" {
+			res = append(res, m.Artifacts[i].Name+signatureExtension)
+		}
+	}
+	return res
+}
+
+// VerifyManifest checks the release artifacts downloaded into `dir` against
+// the size and hashes recorded in the manifest. The SHA256 hashes are also
+// cross-checked against the SHA256SUMS file, which has to list every
+// artifact and the manifest itself. If `keyring` is set, the signatures of
+// the manifest, the SHA256SUMS file and all signed artifacts are verified
+// with the keys of the keyring, otherwise they only have to exist. The
+// returned error is only set if the verification itself could not be done.
+func VerifyManifest(manifest *Manifest, dir, keyring string) ([]ArtifactVerification, error) {
+	sha256Sums, err := readSums(dir)
+	if err != nil {
+		return nil, err
+	}
+
+	res := []ArtifactVerification{verifyManifestFile(dir, keyring, sha256Sums)}
+	if keyring != "" {
+		sumsFile := checksumAlgorithms[0].sumsFile()
+		res = append(res, ArtifactVerification{
+			Name:      sumsFile,
+			Signature: VerifySignature(filepath.Join(dir, sumsFile), keyring),
+		})
+	}
+	for i := range manifest.Artifacts {
+		res = append(res, verifyArtifact(
+			&manifest.Artifacts[i], dir, keyring, sha256Sums,
+		))
+	}
+	return res, nil
+}
+
+// verifyManifestFile checks the manifest in `dir` itself, which is covered
+// by the SHA256SUMS file and signed if the release got signed.
+func verifyManifestFile(
+	dir, keyring string, sha256Sums map[string]string,
+) ArtifactVerification {
+	res := ArtifactVerification{Name: ManifestFile}
+	manifestPath := filepath.Join(dir, ManifestFile)
+
+	digests, err := util.DigestFile(manifestPath)
+	if err != nil {
+		res.SHA256 = err
+		return res
+	}
+	res.Sums = checkSums(ManifestFile, digests.SHA256, sha256Sums)
+
+	if keyring != "" {
+		res.Signature = VerifySignature(manifestPath, keyring)
+	}
+	return res
+}
+
+// verifyArtifact checks a single artifact in `dir`. The SHA256SUMS check is
+// skipped if `sha256Sums` is nil.
+func verifyArtifact(
+	artifact *ManifestArtifact, dir, keyring string, sha256Sums map[string]string,
+) ArtifactVerification {
+	res := ArtifactVerification{Name: artifact.Name}
+	file, err := util.JoinWithin(dir, artifact.Name)
+	if err != nil {
+		res.Size = err
+		res.SHA256 = err
+		res.SHA512 = err
+		res.Sums = err
+		res.Signature = err
+		return res
+	}
+
+	digests, err := util.DigestFile(file)
+	if err != nil {
+		res.Size = err
+		res.SHA256 = err
+		res.SHA512 = err
+	} else {
+		if digests.Size != artifact.Size {
+			res.Size = errors.Errorf("size is %d, expected %d", digests.Size, artifact.Size)
+		}
+		if digests.SHA256 != artifact.SHA256 {
+			res.SHA256 = errors.Errorf("sha256 is %s, expected %s", digests.SHA256, artifact.SHA256)
+		}
+		if digests.SHA512 != artifact.SHA512 {
+			res.SHA512 = errors.Errorf("sha512 is %s, expected %s", digests.SHA512, artifact.SHA512)
+		}
+	}
+
+	// The checksum files cannot list themselves
+	if sha256Sums != nil && !isChecksumFile(dir, file) {
+		res.Sums = checkSums(artifact.Name, artifact.SHA256, sha256Sums)
+	}
+
+	if artifact.Signature != "" {
+		if keyring != "" {
+			res.Signature = VerifySignature(file, keyring)
+		} else if !util.Exists(file + signatureExtension) {
+			res.Signature = errors.Errorf("signature %s does not exist", artifact.Signature)
+		}
+	}
+
+	return res
+}
+
+// checkSums returns an error if `name` is not listed in the SHA256SUMS file
+// with `sha256sum`.
+func checkSums(name, sha256sum string, sha256Sums map[string]string) error {
+	sum, ok := sha256Sums[name]
+	if !ok {
+		return errors.Errorf("%s is not listed in SHA256SUMS", name)
+	}
+	if sum != sha256sum {
+		return errors.Errorf(
+			"SHA256SUMS lists %s, manifest contains %s", sum, sha256sum,
+		)
+	}
+	return nil
+}
+
+// readSums reads the SHA256SUMS file in `dir` into a map of file names to
+// their hashes.
+func readSums(dir string) (map[string]string, error) {
+	sumsFile := checksumAlgorithms[0].sumsFile()
+	sums := map[string]string{}
+
+	r, err := os.Open(filepath.Join(dir, sumsFile))
+	if err != nil {
+		return nil, errors.Wrapf(err, "opening %s", sumsFile)
+	}
+	defer r.Close()
+
+	scanner := bufio.NewScanner(r)
+	for scanner.Scan() {
+		fields := strings.Fields(scanner.Text())
+		if len(fields) != 2 {
+			continue
+		}
+		sums[fields[1]] = fields[0]
+	}
+	if err := scanner.Err(); err != nil {
+		return nil, errors.Wrapf(err, "reading %s", sumsFile)
+	}
+	return sums, nil
+}
@@ -0,0 +1,260 @@
+/*
+Copyright 2020 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package release
+
+import (
+	"io"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+
+	"k8s.io/release/pkg/command"
+)
+
+func TestVerifyManifest(t *testing.T) {
+	baseTmpDir, err := ioutil.TempDir("", "")
+	require.Nil(t, err)
+	defer cleanupTmps(t, baseTmpDir)
+
+	for _, file := range []string{
+		"kubernetes-client-linux-amd64.tar.gz",
+		"kubernetes-client-linux-amd64.tar.gz.asc",
+		"kubernetes-node-linux-amd64.tar.gz",
+		"kubernetes-node-linux-amd64.tar.gz.asc",
+		"kubernetes.tar.gz",
+	} {
+		require.Nil(t, ioutil.WriteFile(
+			filepath.Join(baseTmpDir, file), []byte("test"), os.FileMode(0644),
+		))
+	}
+	require.Nil(t, WriteManifest(baseTmpDir, "v1.18.0", "gs://bucket/release/v1.18.0", false, Digests{}))
+	require.Nil(t, WriteChecksums(baseTmpDir, Digests{}))
+
+	// Tamper with the published artifacts
+	require.Nil(t, ioutil.WriteFile(
+		filepath.Join(baseTmpDir, "kubernetes.tar.gz"), []byte("changed"), os.FileMode(0644),
+	))
+	require.Nil(t, os.Remove(
+		filepath.Join(baseTmpDir, "kubernetes-node-linux-amd64.tar.gz.asc"),
+	))
+
+	manifest, err := ReadManifest(LocalOpen(baseTmpDir))
+	require.Nil(t, err)
+	require.Equal(t, "v1.18.0", manifest.Version)
+
+	results, err := VerifyManifest(manifest, baseTmpDir, "")
+	require.Nil(t, err)
+	require.Len(t, results, 4)
+
+	byName := map[string]*ArtifactVerification{}
+	for i := range results {
+		byName[results[i].Name] = &results[i]
+	}
+
+	require.True(t, byName[ManifestFile].Passed())
+	require.True(t, byName["kubernetes-client-linux-amd64.tar.gz"].Passed())
+
+	node := byName["kubernetes-node-linux-amd64.tar.gz"]
+	require.False(t, node.Passed())
+	require.Nil(t, node.SHA256)
+	require.NotNil(t, node.Signature)
+
+	tarball := byName["kubernetes.tar.gz"]
+	require.False(t, tarball.Passed())
+	require.NotNil(t, tarball.Size)
+	require.NotNil(t, tarball.SHA256)
+	require.NotNil(t, tarball.SHA512)
+	require.Nil(t, tarball.Sums)
+	require.Nil(t, tarball.Signature)
+}
+
+func TestVerifyManifestSumsMismatch(t *testing.T) {
+	baseTmpDir, err := ioutil.TempDir("", "")
+	require.Nil(t, err)
+	defer cleanupTmps(t, baseTmpDir)
+
+	for file, content := range map[string]string{
+		"SHA256SUMS":        "0000  kubernetes.tar.gz\n",
+		"kubernetes.tar.gz": "test",
+		"unlisted.tar.gz":   "test",
+		ManifestFile:        "{}",
+	} {
+		require.Nil(t, ioutil.WriteFile(
+			filepath.Join(baseTmpDir, file), []byte(content), os.FileMode(0644),
+		))
+	}
+
+	artifact := ManifestArtifact{
+		Name:   "kubernetes.tar.gz",
+		Size:   4,
+		SHA256: "9f86d081884c7d659a2feaa0c55ad015a3bf4f1b2b0b822cd15d6c15b0f00a08",
+		SHA512: "ee26b0dd4af7e749aa1a8ee3c10ae9923f618980772e473f8819a5d4940e0db27ac185f8a0e1d5f84f88bc887fd67b143732c304cc5fa9ad8e6f57f50028a8ff",
+	}
+	unlisted := artifact
+	unlisted.Name = "unlisted.tar.gz"
+	manifest := &Manifest{Artifacts: []ManifestArtifact{artifact, unlisted}}
+
+	results, err := VerifyManifest(manifest, baseTmpDir, "")
+	require.Nil(t, err)
+	require.Len(t, results, 3)
+
+	// The manifest itself is not listed
+	require.Equal(t, ManifestFile, results[0].Name)
+	require.NotNil(t, results[0].Sums)
+
+	require.Nil(t, results[1].SHA256)
+	require.NotNil(t, results[1].Sums)
+
+	require.Nil(t, results[2].SHA256)
+	require.Contains(t, results[2].Sums.Error(), "not listed")
+}
+
+func TestVerifyManifestOutsideDir(t *testing.T) {
+	baseTmpDir, err := ioutil.TempDir("", "")
+	require.Nil(t, err)
+	defer cleanupTmps(t, baseTmpDir)
+
+	dir := filepath.Join(baseTmpDir, "release")
+	require.Nil(t, os.Mkdir(dir, os.FileMode(0755)))
+	for file, content := range map[string]string{
+		filepath.Join(dir, "SHA256SUMS"): "",
+		filepath.Join(dir, ManifestFile): "{}",
+		filepath.Join(baseTmpDir, "x"):   "test",
+	} {
+		require.Nil(t, ioutil.WriteFile(file, []byte(content), os.FileMode(0644)))
+	}
+
+	manifest := &Manifest{Artifacts: []ManifestArtifact{{Name: "../x", Size: 4}}}
+	results, err := VerifyManifest(manifest, dir, "")
+	require.Nil(t, err)
+	require.Len(t, results, 2)
+	require.False(t, results[1].Passed())
+	require.NotNil(t, results[1].Size)
+
+	_, err = LocalOpen(dir)("../x")
+	require.NotNil(t, err)
+}
+
+func TestVerifyManifestNoSums(t *testing.T) {
+	baseTmpDir, err := ioutil.TempDir("", "")
+	require.Nil(t, err)
+	defer cleanupTmps(t, baseTmpDir)
+
+	_, err = VerifyManifest(&Manifest{}, baseTmpDir, "")
+	require.NotNil(t, err)
+}
+
+func TestVerifyManifestSignatures(t *testing.T) {
+	if !command.Available(GPGExecutable) {
+		t.Skipf("%s is not available", GPGExecutable)
+	}
+
+	baseTmpDir, err := ioutil.TempDir("", "")
+	require.Nil(t, err)
+	defer cleanupTmps(t, baseTmpDir)
+
+	keyring, cleanup := newTestKeyring(t, baseTmpDir)
+	defer cleanup()
+
+	stageDir := filepath.Join(baseTmpDir, "stage")
+	require.Nil(t, os.Mkdir(stageDir, os.ModePerm))
+	for _, file := range []string{
+		"kubernetes.tar.gz", "kubernetes-client-linux-amd64.tar.gz",
+	} {
+		require.Nil(t, ioutil.WriteFile(
+			filepath.Join(stageDir, file), []byte(file), os.FileMode(0644),
+		))
+	}
+	require.Nil(t, WriteManifest(stageDir, "v1.18.0", "gs://bucket/release/v1.18.0", true, Digests{}))
+	require.Nil(t, WriteChecksums(stageDir, Digests{}))
+	require.Nil(t, SignArtifacts(stageDir, ""))
+
+	// Replace one signature with a signature of another file
+	client := filepath.Join(stageDir, "kubernetes-client-linux-amd64.tar.gz")
+	require.Nil(t, os.Rename(
+		filepath.Join(stageDir, "kubernetes.tar.gz"+signatureExtension),
+		client+signatureExtension,
+	))
+
+	manifest, err := ReadManifest(LocalOpen(stageDir))
+	require.Nil(t, err)
+
+	results, err := VerifyManifest(manifest, stageDir, keyring)
+	require.Nil(t, err)
+	require.Len(t, results, 4)
+
+	byName := map[string]*ArtifactVerification{}
+	for i := range results {
+		byName[results[i].Name] = &results[i]
+	}
+	require.True(t, byName[ManifestFile].Passed())
+	require.True(t, byName["SHA256SUMS"].Passed())
+	require.NotNil(t, byName["kubernetes-client-linux-amd64.tar.gz"].Signature)
+	require.NotNil(t, byName["kubernetes.tar.gz"].Signature)
+
+	// Signatures of other keys are rejected
+	otherKeyring := filepath.Join(baseTmpDir, "other.gpg")
+	require.Nil(t, ioutil.WriteFile(otherKeyring, []byte{}, os.FileMode(0644)))
+	results, err = VerifyManifest(manifest, stageDir, otherKeyring)
+	require.Nil(t, err)
+	require.NotNil(t, results[0].Signature)
+}
+
+// newTestKeyring switches to a throwaway gpg home with a single unprotected
+// default key below `baseTmpDir` and returns the keyring file of its public
+// key. The returned function switches back to the previous gpg home.
+func newTestKeyring(t *testing.T, baseTmpDir string) (keyring string, cleanup func()) {
+	gnupgHome := filepath.Join(baseTmpDir, "gnupg")
+	require.Nil(t, os.Mkdir(gnupgHome, os.FileMode(0700)))
+	previous := os.Getenv("GNUPGHOME")
+	cleanup = func() {
+		command.New("gpgconf", "--kill", "gpg-agent").RunSilent() // nolint: errcheck
+		os.Setenv("GNUPGHOME", previous)                          // nolint: errcheck
+	}
+	require.Nil(t, os.Setenv("GNUPGHOME", gnupgHome))
+	require.Nil(t, command.New(
+		GPGExecutable, "--batch", "--passphrase", "",
+		"--quick-generate-key", "Release Test <release@example.com>",
+		"default", "default", "never",
+	).RunSilentSuccess())
+
+	keyring = filepath.Join(baseTmpDir, "keyring.gpg")
+	keys, err := command.New(GPGExecutable, "--export").RunSilentSuccessOutput()
+	require.Nil(t, err)
+	require.Nil(t, ioutil.WriteFile(keyring, []byte(keys.Output()), os.FileMode(0644)))
+	return keyring, cleanup
+}
+
+func TestReadManifestNotExisting(t *testing.T) {
+	_, err := ReadManifest(func(string) (io.ReadCloser, error) {
+		return nil, os.ErrNotExist
+	})
+	require.NotNil(t, err)
+}
+
+func TestManifestFiles(t *testing.T) {
+	manifest := &Manifest{Artifacts: []ManifestArtifact{
+		{Name: "SHA256SUMS"},
+		{Name: "kubernetes.tar.gz", Signature: "gs://bucket/kubernetes.tar.gz.asc"},
+	}}
+	require.Equal(t, []string{
+		ManifestFile, "SHA256SUMS", "SHA512SUMS", "kubernetes.tar.gz", "kubernetes.tar.gz.asc",
+	}, manifest.Files())
+}
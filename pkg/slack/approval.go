@@ -0,0 +1,319 @@
+/*
+Copyright 2020 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package slack
+
+import (
+	"context"
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"io/ioutil"
+	"net/http"
+	"net/url"
+	"regexp"
+	"strconv"
+	"time"
+
+	"github.com/pkg/errors"
+	"github.com/sirupsen/logrus"
+)
+
+const (
+	// InteractionPath is the path the interactivity request URL of the Slack
+	// app has to point to
+	InteractionPath = "/slack/interaction"
+
+	actionApprove = "approve"
+	actionReject  = "reject"
+
+	// maxRequestAge is the maximum age of an interaction request before it
+	// is considered a replay
+	maxRequestAge = 5 * time.Minute
+)
+
+// userIDPattern matches Slack user IDs, like U012AB3CD. Names are not
+// accepted for approvers, because they are neither unique across workspaces
+// nor immutable.
+var userIDPattern = regexp.MustCompile(`^[UW][A-Z0-9]+$`)
+
+// ApprovalRequest asks the Approvers in Channel to approve an action
+type ApprovalRequest struct {
+	// ID identifies the request, like push-v1.18.0
+	ID string
+
+	// Channel is the Slack channel the request is posted to
+	Channel string
+
+	// Text describes the action to approve
+	Text string
+
+	// Approvers are the Slack user IDs allowed to approve
+	Approvers []string
+
+	// TeamID is the ID of the Slack workspace the approvers belong to.
+	// Interactions from other workspaces, like via shared channels, are
+	// ignored.
+	TeamID string
+
+	// SigningSecret of the Slack app, used to verify the interactions
+	SigningSecret string
+
+	// Required is the number of distinct approvers which have to approve,
+	// defaults to one
+	Required int
+}
+
+func (r *ApprovalRequest) required() int {
+	if r.Required < 1 {
+		return 1
+	}
+	return r.Required
+}
+
+// Approval is the decision of an approver
+type Approval struct {
+	User     string
+	UserID   string
+	Approved bool
+	Time     time.Time
+}
+
+type interaction struct {
+	Type string `json:"type"`
+	Team struct {
+		ID string `json:"id"`
+	} `json:"team"`
+	User struct {
+		ID       string `json:"id"`
+		Username string `json:"username"`
+		TeamID   string `json:"team_id"`
+	} `json:"user"`
+	Actions []struct {
+		ActionID string `json:"action_id"`
+		Value    string `json:"value"`
+	} `json:"actions"`
+}
+
+// RequestApproval posts the approval request with an approve and reject
+// button and waits until Required distinct approvers approved it, an
+// approver rejected it or `ctx` is done. The returned decisions end with the
+// rejection, if any. The button clicks are received by a plain HTTP server
+// on `listenAddress`. Slack only sends them to HTTPS request URLs, so the
+// server has to be exposed via a TLS terminating tunnel or reverse proxy,
+// whose URL with the InteractionPath is the interactivity request URL of
+// the app.
+func (c *Client) RequestApproval(
+	ctx context.Context, req *ApprovalRequest, listenAddress string,
+) ([]Approval, error) {
+	if len(req.Approvers) == 0 {
+		return nil, errors.New("the approval request has no approvers")
+	}
+	for _, approver := range req.Approvers {
+		if !userIDPattern.MatchString(approver) {
+			return nil, errors.Errorf("approver %q is no Slack user ID", approver)
+		}
+	}
+	if req.TeamID == "" {
+		return nil, errors.New("the approval request has no Slack team ID")
+	}
+	if req.required() > len(req.Approvers) {
+		return nil, errors.Errorf(
+			"%d approvals required, but only %d approvers allowed",
+			req.required(), len(req.Approvers),
+		)
+	}
+
+	approvals := make(chan Approval, len(req.Approvers))
+	mux := http.NewServeMux()
+	mux.Handle(InteractionPath, InteractionHandler(req, approvals))
+	server := &http.Server{Addr: listenAddress, Handler: mux}
+
+	serverErr := make(chan error, 1)
+	go func() {
+		if err := server.ListenAndServe(); err != http.ErrServerClosed {
+			serverErr <- err
+		}
+	}()
+	defer server.Close()
+
+	if _, err := c.PostMessage(approvalMessage(req)); err != nil {
+		return nil, err
+	}
+	logrus.Infof(
+		"Waiting for %d approval(s) of %s in Slack channel %s",
+		req.required(), req.ID, req.Channel,
+	)
+	return collectApprovals(ctx, req, approvals, serverErr)
+}
+
+// collectApprovals reads the decisions until Required distinct approvers
+// approved or the first rejection. Repeated approvals of the same approver
+// are counted once by their user ID.
+func collectApprovals(
+	ctx context.Context, req *ApprovalRequest,
+	approvals <-chan Approval, serverErr <-chan error,
+) ([]Approval, error) {
+	decisions := []Approval{}
+	approvedBy := map[string]bool{}
+	for len(approvedBy) < req.required() {
+		select {
+		case approval := <-approvals:
+			if approval.Approved && approvedBy[approval.UserID] {
+				continue
+			}
+			decisions = append(decisions, approval)
+			if !approval.Approved {
+				return decisions, nil
+			}
+			approvedBy[approval.UserID] = true
+			logrus.Infof(
+				"%s approved %s (%d/%d)",
+				approval.User, req.ID, len(approvedBy), req.required(),
+			)
+		case err := <-serverErr:
+			return decisions, errors.Wrap(err, "receiving Slack interactions")
+		case <-ctx.Done():
+			return decisions, errors.Wrapf(ctx.Err(), "waiting for the approval of %s", req.ID)
+		}
+	}
+	return decisions, nil
+}
+
+// InteractionHandler handles the button clicks on the approval request. All
+// requests not signed with the signing secret of the app are rejected. So
+// are clicks of users from other workspaces than the TeamID and of users
+// whose ID is not one of the approvers. The decisions of the approvers are
+// sent to `approvals`.
+func InteractionHandler(req *ApprovalRequest, approvals chan<- Approval) http.Handler {
+	approvers := map[string]bool{}
+	for _, approver := range req.Approvers {
+		approvers[approver] = true
+	}
+
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		body, err := ioutil.ReadAll(r.Body)
+		if err != nil {
+			http.Error(w, "unable to read request", http.StatusBadRequest)
+			return
+		}
+		if err := verifySignature(
+			req.SigningSecret, r.Header, body, time.Now(),
+		); err != nil {
+			logrus.Warnf("Rejecting Slack interaction: %v", err)
+			http.Error(w, "invalid signature", http.StatusUnauthorized)
+			return
+		}
+
+		form, err := url.ParseQuery(string(body))
+		if err != nil {
+			http.Error(w, "invalid request", http.StatusBadRequest)
+			return
+		}
+		payload := &interaction{}
+		if err := json.Unmarshal([]byte(form.Get("payload")), payload); err != nil {
+			http.Error(w, "invalid payload", http.StatusBadRequest)
+			return
+		}
+
+		for _, action := range payload.Actions {
+			if action.Value != req.ID ||
+				(action.ActionID != actionApprove && action.ActionID != actionReject) {
+				continue
+			}
+			if payload.Team.ID != req.TeamID ||
+				(payload.User.TeamID != "" && payload.User.TeamID != req.TeamID) {
+				logrus.Warnf(
+					"Ignoring decision of %s (%s), who is not part of the Slack team %s",
+					payload.User.Username, payload.User.ID, req.TeamID,
+				)
+				continue
+			}
+			if !approvers[payload.User.ID] {
+				logrus.Warnf(
+					"Ignoring decision of %s (%s), who is no approver of %s",
+					payload.User.Username, payload.User.ID, req.ID,
+				)
+				continue
+			}
+
+			select {
+			case approvals <- Approval{
+				User:     payload.User.Username,
+				UserID:   payload.User.ID,
+				Approved: action.ActionID == actionApprove,
+				Time:     time.Now().UTC(),
+			}:
+			default:
+				// All decisions have been made already
+			}
+		}
+		w.WriteHeader(http.StatusOK)
+	})
+}
+
+// verifySignature checks the signature of a Slack request as described in
+// https://api.slack.com/authentication/verifying-requests-from-slack
+func verifySignature(secret string, header http.Header, body []byte, now time.Time) error {
+	timestamp := header.Get("X-Slack-Request-Timestamp")
+	seconds, err := strconv.ParseInt(timestamp, 10, 64)
+	if err != nil {
+		return errors.Wrapf(err, "invalid timestamp %q", timestamp)
+	}
+	if age := now.Sub(time.Unix(seconds, 0)); age > maxRequestAge || age < -maxRequestAge {
+		return errors.Errorf("request timestamp %s is too old", timestamp)
+	}
+
+	mac := hmac.New(sha256.New, []byte(secret))
+	mac.Write([]byte("v0:" + timestamp + ":"))
+	mac.Write(body)
+	expected := "v0=" + hex.EncodeToString(mac.Sum(nil))
+	if !hmac.Equal([]byte(expected), []byte(header.Get("X-Slack-Signature"))) {
+		return errors.New("signature does not match")
+	}
+	return nil
+}
+
+func approvalMessage(req *ApprovalRequest) *Message {
+	button := func(text, actionID, style string) map[string]interface{} {
+		return map[string]interface{}{
+			"type":      "button",
+			"text":      map[string]interface{}{"type": "plain_text", "text": text},
+			"action_id": actionID,
+			"value":     req.ID,
+			"style":     style,
+		}
+	}
+	return &Message{
+		Channel: req.Channel,
+		Text:    req.Text,
+		Blocks: []interface{}{
+			map[string]interface{}{
+				"type": "section",
+				"text": map[string]interface{}{"type": "mrkdwn", "text": req.Text},
+			},
+			map[string]interface{}{
+				"type":     "actions",
+				"block_id": req.ID,
+				"elements": []interface{}{
+					button("Approve", actionApprove, "primary"),
+					button("Reject", actionReject, "danger"),
+				},
+			},
+		},
+	}
+}
@@ -0,0 +1,208 @@
+/*
+Copyright 2020 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package slack
+
+import (
+	"context"
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/hex"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"strconv"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+)
+
+const testSecret = "secret"
+
+func signedRequest(secret string, timestamp time.Time, payload string) *http.Request {
+	body := url.Values{"payload": {payload}}.Encode()
+	ts := strconv.FormatInt(timestamp.Unix(), 10)
+	mac := hmac.New(sha256.New, []byte(secret))
+	mac.Write([]byte("v0:" + ts + ":" + body))
+
+	req := httptest.NewRequest(http.MethodPost, InteractionPath, strings.NewReader(body))
+	req.Header.Set("X-Slack-Request-Timestamp", ts)
+	req.Header.Set("X-Slack-Signature", "v0="+hex.EncodeToString(mac.Sum(nil)))
+	return req
+}
+
+func clickPayload(user, actionID, value string) string {
+	return teamClickPayload("T123", user, actionID, value)
+}
+
+func teamClickPayload(team, user, actionID, value string) string {
+	return fmt.Sprintf(
+		`{"type": "block_actions", "team": {"id": %q}, "user": {"id": %q, "username": "name", "team_id": %q}, "actions": [{"action_id": %q, "value": %q}]}`,
+		team, user, team, actionID, value,
+	)
+}
+
+func TestInteractionHandler(t *testing.T) {
+	req := &ApprovalRequest{
+		ID:            "push-v1.18.0",
+		Approvers:     []string{"U123"},
+		TeamID:        "T123",
+		SigningSecret: testSecret,
+	}
+
+	for name, tc := range map[string]struct {
+		request        *http.Request
+		expectedStatus int
+		expected       *Approval
+	}{
+		"success approved": {
+			request:        signedRequest(testSecret, time.Now(), clickPayload("U123", actionApprove, req.ID)),
+			expectedStatus: http.StatusOK,
+			expected:       &Approval{User: "name", Approved: true},
+		},
+		"success rejected": {
+			request:        signedRequest(testSecret, time.Now(), clickPayload("U123", actionReject, req.ID)),
+			expectedStatus: http.StatusOK,
+			expected:       &Approval{User: "name", Approved: false},
+		},
+		"success ignored other request": {
+			request:        signedRequest(testSecret, time.Now(), clickPayload("U123", actionApprove, "push-v1.17.0")),
+			expectedStatus: http.StatusOK,
+		},
+		"success ignored no approver": {
+			request:        signedRequest(testSecret, time.Now(), clickPayload("U456", actionApprove, req.ID)),
+			expectedStatus: http.StatusOK,
+		},
+		"success ignored other team": {
+			request:        signedRequest(testSecret, time.Now(), teamClickPayload("T456", "U123", actionApprove, req.ID)),
+			expectedStatus: http.StatusOK,
+		},
+		"failure wrong secret": {
+			request:        signedRequest("wrong", time.Now(), clickPayload("U123", actionApprove, req.ID)),
+			expectedStatus: http.StatusUnauthorized,
+		},
+		"failure replayed request": {
+			request:        signedRequest(testSecret, time.Now().Add(-time.Hour), clickPayload("U123", actionApprove, req.ID)),
+			expectedStatus: http.StatusUnauthorized,
+		},
+		"failure invalid payload": {
+			request:        signedRequest(testSecret, time.Now(), "invalid"),
+			expectedStatus: http.StatusBadRequest,
+		},
+	} {
+		t.Run(name, func(t *testing.T) {
+			approvals := make(chan Approval, 1)
+			w := httptest.NewRecorder()
+			InteractionHandler(req, approvals).ServeHTTP(w, tc.request)
+			require.Equal(t, tc.expectedStatus, w.Code)
+
+			select {
+			case approval := <-approvals:
+				require.NotNil(t, tc.expected)
+				require.Equal(t, tc.expected.User, approval.User)
+				require.Equal(t, "U123", approval.UserID)
+				require.Equal(t, tc.expected.Approved, approval.Approved)
+			default:
+				require.Nil(t, tc.expected)
+			}
+		})
+	}
+}
+
+func TestApprovalMessage(t *testing.T) {
+	msg := approvalMessage(&ApprovalRequest{
+		ID: "push-v1.18.0", Channel: "release-management", Text: "Push v1.18.0?",
+	})
+	require.Equal(t, "release-management", msg.Channel)
+	require.Equal(t, "Push v1.18.0?", msg.Text)
+	require.Len(t, msg.Blocks, 2)
+}
+
+func TestCollectApprovals(t *testing.T) {
+	for name, tc := range map[string]struct {
+		required    int
+		decisions   []Approval
+		expected    []string
+		shouldError bool
+	}{
+		"success single approval": {
+			decisions: []Approval{{User: "first", UserID: "U1", Approved: true}},
+			expected:  []string{"first"},
+		},
+		"success distinct approvals": {
+			required: 2,
+			decisions: []Approval{
+				{User: "first", UserID: "U1", Approved: true},
+				{User: "first", UserID: "U1", Approved: true},
+				{User: "second", UserID: "U2", Approved: true},
+			},
+			expected: []string{"first", "second"},
+		},
+		"success rejected": {
+			required: 2,
+			decisions: []Approval{
+				{User: "first", UserID: "U1", Approved: true},
+				{User: "second", UserID: "U2", Approved: false},
+			},
+			expected: []string{"first", "second"},
+		},
+		"failure not enough approvals": {
+			required:    2,
+			decisions:   []Approval{{User: "first", UserID: "U1", Approved: true}},
+			expected:    []string{"first"},
+			shouldError: true,
+		},
+	} {
+		t.Run(name, func(t *testing.T) {
+			approvals := make(chan Approval, len(tc.decisions))
+			for _, decision := range tc.decisions {
+				approvals <- decision
+			}
+			ctx, cancel := context.WithTimeout(context.Background(), 100*time.Millisecond)
+			defer cancel()
+
+			res, err := collectApprovals(
+				ctx, &ApprovalRequest{ID: "push-v1.18.0", Required: tc.required},
+				approvals, make(chan error),
+			)
+			if tc.shouldError {
+				require.NotNil(t, err)
+			} else {
+				require.Nil(t, err)
+			}
+			users := []string{}
+			for _, approval := range res {
+				users = append(users, approval.User)
+			}
+			require.Equal(t, tc.expected, users)
+		})
+	}
+}
+
+func TestRequestApprovalInvalid(t *testing.T) {
+	for name, req := range map[string]*ApprovalRequest{
+		"no approvers":      {ID: "push-v1.18.0", TeamID: "T123"},
+		"approver name":     {ID: "push-v1.18.0", TeamID: "T123", Approvers: []string{"manager"}},
+		"no team":           {ID: "push-v1.18.0", Approvers: []string{"U123"}},
+		"too few approvers": {ID: "push-v1.18.0", TeamID: "T123", Approvers: []string{"U123"}, Required: 2},
+	} {
+		_, err := New("token").RequestApproval(context.Background(), req, "localhost:0")
+		require.NotNil(t, err, name)
+	}
+}
@@ -0,0 +1,95 @@
+/*
+Copyright 2020 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package slack
+
+import (
+	"bytes"
+	"encoding/json"
+	"net/http"
+	"strings"
+
+	"github.com/pkg/errors"
+)
+
+// DefaultAPIURL is the base URL of the Slack Web API
+const DefaultAPIURL = "https://slack.com/api"
+
+// Client posts messages to Slack as a Slack app, authenticated by the bot
+// token of the app
+type Client struct {
+	Token      string
+	APIURL     string
+	HTTPClient *http.Client
+}
+
+// New creates a new Client for the Slack app bot token `token`
+func New(token string) *Client {
+	return &Client{
+		Token:      token,
+		APIURL:     DefaultAPIURL,
+		HTTPClient: http.DefaultClient,
+	}
+}
+
+// Message is a message posted by the app, where Blocks is the optional
+// Block Kit layout of the message
+type Message struct {
+	Channel string        `json:"channel"`
+	Text    string        `json:"text"`
+	Blocks  []interface{} `json:"blocks,omitempty"`
+}
+
+type postMessageResponse struct {
+	OK    bool   `json:"ok"`
+	Error string `json:"error"`
+	TS    string `json:"ts"`
+}
+
+// PostMessage posts the message and returns its timestamp, which identifies
+// the message within the channel
+func (c *Client) PostMessage(msg *Message) (string, error) {
+	content, err := json.Marshal(msg)
+	if err != nil {
+		return "", errors.Wrap(err, "encoding Slack message")
+	}
+
+	req, err := http.NewRequest(
+		http.MethodPost,
+		strings.TrimSuffix(c.APIURL, "/")+"/chat.postMessage",
+		bytes.NewReader(content),
+	)
+	if err != nil {
+		return "", errors.Wrap(err, "creating Slack request")
+	}
+	req.Header.Set("Content-Type", "application/json; charset=utf-8")
+	req.Header.Set("Authorization", "Bearer "+c.Token)
+
+	resp, err := c.HTTPClient.Do(req)
+	if err != nil {
+		return "", errors.Wrap(err, "posting Slack message")
+	}
+	defer resp.Body.Close()
+
+	res := &postMessageResponse{}
+	if err := json.NewDecoder(resp.Body).Decode(res); err != nil {
+		return "", errors.Wrap(err, "decoding Slack response")
+	}
+	if !res.OK {
+		return "", errors.Errorf("posting Slack message to %s: %s", msg.Channel, res.Error)
+	}
+	return res.TS, nil
+}
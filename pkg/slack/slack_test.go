@@ -0,0 +1,56 @@
+/*
+Copyright 2020 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package slack
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestPostMessage(t *testing.T) {
+	var received *Message
+	ok := true
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		require.Equal(t, "/chat.postMessage", r.URL.Path)
+		require.Equal(t, "Bearer token", r.Header.Get("Authorization"))
+		received = &Message{}
+		require.Nil(t, json.NewDecoder(r.Body).Decode(received))
+		if ok {
+			w.Write([]byte(`{"ok": true, "ts": "1503435956.000247"}`)) // nolint: errcheck
+		} else {
+			w.Write([]byte(`{"ok": false, "error": "channel_not_found"}`)) // nolint: errcheck
+		}
+	}))
+	defer server.Close()
+
+	client := New("token")
+	client.APIURL = server.URL
+
+	ts, err := client.PostMessage(&Message{Channel: "release-management", Text: "hello"})
+	require.Nil(t, err)
+	require.Equal(t, "1503435956.000247", ts)
+	require.Equal(t, &Message{Channel: "release-management", Text: "hello"}, received)
+
+	ok = false
+	_, err = client.PostMessage(&Message{Channel: "not-existing", Text: "hello"})
+	require.NotNil(t, err)
+	require.Contains(t, err.Error(), "channel_not_found")
+}
@@ -0,0 +1,230 @@
+/*
+Copyright 2020 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package testgrid
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/url"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/pkg/errors"
+	"github.com/sirupsen/logrus"
+)
+
+const (
+	// DefaultURL is the Kubernetes Testgrid instance
+	DefaultURL = "https://testgrid.k8s.io"
+
+	// StatusFailing is the overall status of a failing dashboard tab
+	StatusFailing = "FAILING"
+
+	// DefaultTimeout is the timeout of a single request to Testgrid
+	DefaultTimeout = 30 * time.Second
+
+	// overallRow is the name of the row containing the result of the whole
+	// run in a dashboard tab
+	overallRow = "Overall"
+
+	// minCommitLength is the minimum length of an abbreviated commit in a
+	// column header to match a commit
+	minCommitLength = 7
+)
+
+// The results of a single test in a run, as defined by the Testgrid
+// TestStatus
+const (
+	ResultPass           = 1
+	ResultPassWithErrors = 2
+	ResultPassWithSkips  = 3
+	ResultRunning        = 4
+	ResultFail           = 12
+	ResultFlaky          = 13
+	ResultBuildPassed    = 15
+)
+
+var httpClient = &http.Client{Timeout: DefaultTimeout}
+
+// TabSummary is the state of a single dashboard tab, as returned by the
+// Testgrid summary endpoint
+type TabSummary struct {
+	OverallStatus string `json:"overall_status"`
+	Alert         string `json:"alert"`
+	LatestGreen   string `json:"latest_green"`
+	Status        string `json:"status"`
+}
+
+// Summary maps the tab names of a dashboard to their state
+type Summary map[string]TabSummary
+
+// Table contains the results of the recent runs of a single dashboard tab,
+// as returned by the Testgrid table endpoint. Every column is a single run,
+// starting with the most recent one.
+type Table struct {
+	// Changelists are the primary column headers, one per run
+	Changelists []string `json:"changelists"`
+
+	// CustomColumns are the configured column headers per run, like the
+	// tested commit
+	CustomColumns [][]string `json:"custom-columns"`
+
+	// Tests are the rows of the table
+	Tests []TableRow `json:"tests"`
+}
+
+// TableRow is the result of a single test for all runs of a Table
+type TableRow struct {
+	Name string `json:"name"`
+
+	// Statuses are run length encoded results, one per run
+	Statuses []StatusRun `json:"statuses"`
+}
+
+// StatusRun is `Count` consecutive runs with the same result `Value`
+type StatusRun struct {
+	Count int `json:"count"`
+	Value int `json:"value"`
+}
+
+// BlockingDashboard returns the name of the release blocking dashboard of
+// the branch, like sig-release-1.18-blocking
+func BlockingDashboard(branch string) string {
+	if branch == "master" {
+		branch = "release-master"
+	}
+	return fmt.Sprintf("sig-%s-blocking", branch)
+}
+
+// DashboardSummary retrieves the summary of the dashboard from the Testgrid
+// instance at `baseURL`
+func DashboardSummary(baseURL, dashboard string) (Summary, error) {
+	u := fmt.Sprintf("%s/%s/summary", strings.TrimSuffix(baseURL, "/"), dashboard)
+	logrus.Infof("Retrieving Testgrid summary from %s", u)
+
+	summary := Summary{}
+	if err := getJSON(u, &summary); err != nil {
+		return nil, errors.Wrapf(err, "retrieving summary of dashboard %s", dashboard)
+	}
+	return summary, nil
+}
+
+// DashboardTab retrieves the results of the recent runs of the `tab` of the
+// dashboard from the Testgrid instance at `baseURL`
+func DashboardTab(baseURL, dashboard, tab string) (*Table, error) {
+	u := fmt.Sprintf(
+		"%s/%s/table?tab=%s", strings.TrimSuffix(baseURL, "/"),
+		dashboard, url.QueryEscape(tab),
+	)
+	logrus.Infof("Retrieving Testgrid table from %s", u)
+
+	table := &Table{}
+	if err := getJSON(u, table); err != nil {
+		return nil, errors.Wrapf(err, "retrieving tab %s of dashboard %s", tab, dashboard)
+	}
+	return table, nil
+}
+
+// getJSON decodes the JSON response of a GET request to `u` into `v`
+func getJSON(u string, v interface{}) error {
+	resp, err := httpClient.Get(u)
+	if err != nil {
+		return errors.Wrapf(err, "an error occurred GET-ing %s", u)
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK {
+		return errors.Errorf("GET %s: %s", u, resp.Status)
+	}
+	return errors.Wrapf(json.NewDecoder(resp.Body).Decode(v), "decoding %s", u)
+}
+
+// Failing returns the sorted names of all failing tabs
+func (s Summary) Failing() []string {
+	failing := []string{}
+	for name, tab := range s {
+		if tab.OverallStatus == StatusFailing {
+			failing = append(failing, name)
+		}
+	}
+	sort.Strings(failing)
+	return failing
+}
+
+// CommitResult returns the overall result of the most recent run of
+// `commit` and false if the table contains no run of it. A run matches if
+// one of its column headers is the commit, an abbreviation of it or a
+// version ending with +<abbreviated commit>.
+func (t *Table) CommitResult(commit string) (result int, found bool) {
+	var overall *TableRow
+	for i := range t.Tests {
+		if t.Tests[i].Name == overallRow {
+			overall = &t.Tests[i]
+			break
+		}
+	}
+	if overall == nil {
+		return 0, false
+	}
+
+	results := overall.results()
+	for i := range t.Changelists {
+		headers := []string{t.Changelists[i]}
+		if i < len(t.CustomColumns) {
+			headers = append(headers, t.CustomColumns[i]...)
+		}
+		for _, header := range headers {
+			if matchesCommit(header, commit) && i < len(results) {
+				return results[i], true
+			}
+		}
+	}
+	return 0, false
+}
+
+// Passed returns true if `result` is a passed run
+func Passed(result int) bool {
+	switch result {
+	case ResultPass, ResultPassWithErrors, ResultPassWithSkips, ResultBuildPassed:
+		return true
+	}
+	return false
+}
+
+// results decodes the run length encoded statuses of the row
+func (r *TableRow) results() []int {
+	res := []int{}
+	for _, run := range r.Statuses {
+		for i := 0; i < run.Count; i++ {
+			res = append(res, run.Value)
+		}
+	}
+	return res
+}
+
+// matchesCommit returns true if the column `header` refers to `commit`
+func matchesCommit(header, commit string) bool {
+	if i := strings.LastIndex(header, "+"); i >= 0 {
+		header = header[i+1:]
+	}
+	if len(header) < minCommitLength || len(commit) < minCommitLength {
+		return false
+	}
+	return strings.HasPrefix(commit, header) || strings.HasPrefix(header, commit)
+}
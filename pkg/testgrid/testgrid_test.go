@@ -0,0 +1,130 @@
+/*
+Copyright 2020 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package testgrid
+
+import (
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestBlockingDashboard(t *testing.T) {
+	require.Equal(t, "sig-release-1.18-blocking", BlockingDashboard("release-1.18"))
+	require.Equal(t, "sig-release-master-blocking", BlockingDashboard("master"))
+}
+
+func TestDashboardSummary(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path != "/sig-release-1.18-blocking/summary" {
+			w.WriteHeader(http.StatusNotFound)
+			return
+		}
+		fmt.Fprint(w, `{
+			"gce-cos-1.18-default": {"overall_status": "PASSING", "latest_green": "1234"},
+			"verify-1.18": {"overall_status": "FAILING", "alert": "2 tests failed"},
+			"gce-cos-1.18-serial": {"overall_status": "FLAKY"},
+			"build-1.18": {"overall_status": "FAILING"}
+		}`)
+	}))
+	defer server.Close()
+
+	summary, err := DashboardSummary(server.URL+"/", "sig-release-1.18-blocking")
+	require.Nil(t, err)
+	require.Len(t, summary, 4)
+	require.Equal(t, "1234", summary["gce-cos-1.18-default"].LatestGreen)
+	require.Equal(t, []string{"build-1.18", "verify-1.18"}, summary.Failing())
+
+	_, err = DashboardSummary(server.URL, "wrong")
+	require.NotNil(t, err)
+}
+
+func TestDashboardTab(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path != "/sig-release-1.18-blocking/table" || r.URL.Query().Get("tab") != "gce cos" {
+			w.WriteHeader(http.StatusNotFound)
+			return
+		}
+		fmt.Fprint(w, `{
+			"changelists": ["1003", "1002", "1001"],
+			"custom-columns": [["v1.18.1-beta.0.12+0123456789ab"], ["abcdef012"], ["fedcba987"]],
+			"tests": [
+				{"name": "Overall", "statuses": [{"count": 1, "value": 12}, {"count": 2, "value": 1}]},
+				{"name": "test", "statuses": [{"count": 3, "value": 1}]}
+			]
+		}`)
+	}))
+	defer server.Close()
+
+	table, err := DashboardTab(server.URL, "sig-release-1.18-blocking", "gce cos")
+	require.Nil(t, err)
+	require.Len(t, table.Changelists, 3)
+
+	_, err = DashboardTab(server.URL, "sig-release-1.18-blocking", "wrong")
+	require.NotNil(t, err)
+}
+
+func TestCommitResult(t *testing.T) {
+	table := &Table{
+		Changelists:   []string{"1003", "1002", "1001"},
+		CustomColumns: [][]string{{"v1.18.1-beta.0.12+0123456789ab"}, {"abcdef012"}, {"abcdef012"}},
+		Tests: []TableRow{
+			{Name: "test", Statuses: []StatusRun{{Count: 3, Value: ResultPass}}},
+			{Name: overallRow, Statuses: []StatusRun{
+				{Count: 1, Value: ResultFail}, {Count: 1, Value: ResultPass}, {Count: 1, Value: ResultFail},
+			}},
+		},
+	}
+
+	for name, tc := range map[string]struct {
+		commit        string
+		expectedFound bool
+		expectedPass  bool
+	}{
+		"failed run of version header": {
+			commit:        "0123456789abcdef0123456789abcdef01234567",
+			expectedFound: true,
+		},
+		"most recent run of abbreviated header": {
+			commit:        "abcdef0123456789abcdef0123456789abcdef01",
+			expectedFound: true,
+			expectedPass:  true,
+		},
+		"abbreviated commit": {
+			commit:        "abcdef0",
+			expectedFound: true,
+			expectedPass:  true,
+		},
+		"too short commit": {
+			commit: "abc",
+		},
+		"no run": {
+			commit: "9999999999999999999999999999999999999999",
+		},
+	} {
+		t.Run(name, func(t *testing.T) {
+			result, found := table.CommitResult(tc.commit)
+			require.Equal(t, tc.expectedFound, found)
+			require.Equal(t, tc.expectedPass, Passed(result))
+		})
+	}
+
+	_, found := (&Table{Changelists: []string{"abcdef012"}}).CommitResult("abcdef012")
+	require.False(t, found)
+}
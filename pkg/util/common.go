@@ -387,3 +387,19 @@ func Exists(path string) bool {
 
 	return true
 }
+
+// JoinWithin joins the slash separated relative path `name` to `dir` and
+// fails if the result is not located within `dir`. It protects against
+// untrusted names like `../../etc/passwd` or absolute paths.
+func JoinWithin(dir, name string) (string, error) {
+	if name == "" || strings.HasPrefix(name, "/") || filepath.IsAbs(filepath.FromSlash(name)) {
+		return "", errors.Errorf("path %q is not relative", name)
+	}
+	joined := filepath.Join(dir, filepath.FromSlash(name))
+	rel, err := filepath.Rel(filepath.Clean(dir), joined)
+	if err != nil || rel == "." || rel == ".." ||
+		strings.HasPrefix(rel, ".."+string(filepath.Separator)) {
+		return "", errors.Errorf("path %q is not within %s", name, dir)
+	}
+	return joined, nil
+}
@@ -574,3 +574,49 @@ func TestSemverToTagString(t *testing.T) {
 	version := semver.Version{Major: 1, Minor: 2, Patch: 3}
 	require.Equal(t, SemverToTagString(version), "v1.2.3")
 }
+
+func TestJoinWithin(t *testing.T) {
+	for name, tc := range map[string]struct {
+		name        string
+		expected    string
+		shouldError bool
+	}{
+		"success file": {
+			name:     "file.tar.gz",
+			expected: filepath.Join("/tmp", "dst", "file.tar.gz"),
+		},
+		"success subdirectory": {
+			name:     "bin/linux/amd64/../amd64/kubectl",
+			expected: filepath.Join("/tmp", "dst", "bin", "linux", "amd64", "kubectl"),
+		},
+		"failure parent directory": {
+			name:        "../../etc/x",
+			shouldError: true,
+		},
+		"failure sibling directory": {
+			name:        "../dst-other/x",
+			shouldError: true,
+		},
+		"failure absolute path": {
+			name:        "/etc/x",
+			shouldError: true,
+		},
+		"failure directory itself": {
+			name:        "bin/..",
+			shouldError: true,
+		},
+		"failure empty": {
+			shouldError: true,
+		},
+	} {
+		t.Run(name, func(t *testing.T) {
+			res, err := JoinWithin(filepath.Join("/tmp", "dst"), tc.name)
+			if tc.shouldError {
+				require.NotNil(t, err)
+			} else {
+				require.Nil(t, err)
+				require.Equal(t, tc.expected, res)
+			}
+		})
+	}
+}
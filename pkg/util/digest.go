@@ -0,0 +1,56 @@
+/*
+Copyright 2020 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package util
+
+import (
+	"crypto/sha256"
+	"crypto/sha512"
+	"fmt"
+	"io"
+	"os"
+
+	"github.com/pkg/errors"
+)
+
+// FileDigests are the hex encoded digests of a file
+type FileDigests struct {
+	Size   int64
+	SHA256 string
+	SHA512 string
+}
+
+// DigestFile returns the size as well as the SHA256 and SHA512 digest of the
+// file at `path`, which is read only once for both digests.
+func DigestFile(path string) (*FileDigests, error) {
+	f, err := os.Open(path)
+	if err != nil {
+		return nil, errors.Wrapf(err, "opening file %s", path)
+	}
+	defer f.Close()
+
+	h256 := sha256.New()
+	h512 := sha512.New()
+	size, err := io.Copy(io.MultiWriter(h256, h512), f)
+	if err != nil {
+		return nil, errors.Wrapf(err, "hashing file %s", path)
+	}
+	return &FileDigests{
+		Size:   size,
+		SHA256: fmt.Sprintf("%x", h256.Sum(nil)),
+		SHA512: fmt.Sprintf("%x", h512.Sum(nil)),
+	}, nil
+}
@@ -0,0 +1,160 @@
+/*
+Copyright 2020 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package util
+
+import (
+	"context"
+	"math/rand"
+	"time"
+
+	"github.com/sirupsen/logrus"
+)
+
+const (
+	// DefaultRetryMaxRetries is the default amount of retries of a single call
+	DefaultRetryMaxRetries = 3
+
+	// DefaultRetryInitialDelay is the default wait time before the first retry
+	DefaultRetryInitialDelay = time.Second
+
+	// DefaultRetryMaxDelay is the default upper limit of a single wait time
+	DefaultRetryMaxDelay = time.Minute
+
+	// DefaultRetryJitter is the default fraction of the delay which gets
+	// randomly added to it, to avoid parallel callers retrying in lockstep
+	DefaultRetryJitter = 0.1
+)
+
+// RetryOptions configure the retry policy of a Retrier
+type RetryOptions struct {
+	// MaxRetries is the maximum amount of retries after the initial call
+	MaxRetries int
+
+	// InitialDelay is the wait time before the first retry, which doubles
+	// for every further retry
+	InitialDelay time.Duration
+
+	// MaxDelay caps the wait time between two retries
+	MaxDelay time.Duration
+
+	// Jitter is the fraction of the delay which gets randomly added to it
+	Jitter float64
+
+	// Retryable decides if an error should be retried. All errors are
+	// retried if not set.
+	Retryable func(error) bool
+
+	// Delay returns the wait time an error dictates itself, like the reset
+	// time of a rate limit. The exponential backoff is used for all errors
+	// it does not return a wait time for, or if it is not set.
+	Delay func(error) (time.Duration, bool)
+
+	// Context aborts the waiting for the next retry once it is done, no
+	// further retries happen afterwards
+	Context context.Context
+
+	// Sleeper implements the waiting, defaults to a timer which respects
+	// the Context
+	Sleeper func(time.Duration)
+}
+
+// DefaultRetryOptions returns a new RetryOptions instance with the default
+// values, which retries all errors.
+func DefaultRetryOptions() *RetryOptions {
+	return &RetryOptions{
+		MaxRetries:   DefaultRetryMaxRetries,
+		InitialDelay: DefaultRetryInitialDelay,
+		MaxDelay:     DefaultRetryMaxDelay,
+		Jitter:       DefaultRetryJitter,
+	}
+}
+
+// Retrier returns a function that checks errors and decides if the call
+// which produced them should be retried. If so, it waits with exponential
+// backoff before returning.
+//
+// It can be used like this:
+//
+//	for shouldRetry := Retrier(DefaultRetryOptions()); ; {
+//	  err := upload(...)
+//	  if !shouldRetry(err) {
+//	    return err
+//	  }
+//	}
+func Retrier(opts *RetryOptions) func(error) bool {
+	ctx := opts.Context
+	if ctx == nil {
+		ctx = context.Background()
+	}
+	sleep := func(d time.Duration) bool {
+		if opts.Sleeper != nil {
+			opts.Sleeper(d)
+			return ctx.Err() == nil
+		}
+		timer := time.NewTimer(d)
+		defer timer.Stop()
+		select {
+		case <-timer.C:
+			return true
+		case <-ctx.Done():
+			return false
+		}
+	}
+	delay := opts.InitialDelay
+	try := 0
+
+	return func(err error) bool {
+		if err == nil {
+			return false
+		}
+		if opts.Retryable != nil && !opts.Retryable(err) {
+			return false
+		}
+		if try >= opts.MaxRetries {
+			logrus.Errorf("Max retries (%d) reached, not retrying anymore: %v", opts.MaxRetries, err)
+			return false
+		}
+		if ctx.Err() != nil {
+			logrus.Errorf("Not retrying anymore, %v: %v", ctx.Err(), err)
+			return false
+		}
+
+		try++
+
+		wait, dictated := time.Duration(0), false
+		if opts.Delay != nil {
+			wait, dictated = opts.Delay(err)
+		}
+		if !dictated {
+			wait = delay
+			if opts.Jitter > 0 {
+				// nolint: gosec
+				wait += time.Duration(rand.Float64() * opts.Jitter * float64(delay))
+			}
+			delay *= 2
+			if opts.MaxDelay > 0 && delay > opts.MaxDelay {
+				delay = opts.MaxDelay
+			}
+		}
+		logrus.Warnf("Retrying failed call (%d/%d) in %s: %v", try, opts.MaxRetries, wait, err)
+		if !sleep(wait) {
+			logrus.Errorf("Not retrying anymore, %v: %v", ctx.Err(), err)
+			return false
+		}
+		return true
+	}
+}
@@ -0,0 +1,138 @@
+/*
+Copyright 2020 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package util
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/pkg/errors"
+	"github.com/stretchr/testify/require"
+)
+
+func TestRetrier(t *testing.T) {
+	errRetryable := errors.New("retryable")
+	errFatal := errors.New("fatal")
+
+	type want struct {
+		retries []bool
+		sleeps  []time.Duration
+	}
+	cases := map[string]struct {
+		opts func(*RetryOptions)
+		errs []error
+		want want
+	}{
+		"NoError": {
+			errs: []error{nil},
+			want: want{retries: []bool{false}},
+		},
+		"ExponentialBackoff": {
+			errs: []error{errRetryable, errRetryable, errRetryable, errRetryable},
+			want: want{
+				retries: []bool{true, true, true, false},
+				sleeps:  []time.Duration{time.Second, 2 * time.Second, 4 * time.Second},
+			},
+		},
+		"MaxDelay": {
+			opts: func(o *RetryOptions) {
+				o.MaxDelay = 3 * time.Second
+			},
+			errs: []error{errRetryable, errRetryable, errRetryable},
+			want: want{
+				retries: []bool{true, true, true},
+				sleeps:  []time.Duration{time.Second, 2 * time.Second, 3 * time.Second},
+			},
+		},
+		"DictatedDelay": {
+			opts: func(o *RetryOptions) {
+				o.Delay = func(err error) (time.Duration, bool) {
+					return time.Hour, err == errFatal
+				}
+			},
+			errs: []error{errFatal, errRetryable, errFatal},
+			want: want{
+				retries: []bool{true, true, true},
+				sleeps:  []time.Duration{time.Hour, time.Second, time.Hour},
+			},
+		},
+		"NotRetryable": {
+			opts: func(o *RetryOptions) {
+				o.Retryable = func(err error) bool { return err == errRetryable }
+			},
+			errs: []error{errRetryable, errFatal},
+			want: want{
+				retries: []bool{true, false},
+				sleeps:  []time.Duration{time.Second},
+			},
+		},
+	}
+
+	for name, tc := range cases {
+		t.Run(name, func(t *testing.T) {
+			sleeps := []time.Duration{}
+			opts := DefaultRetryOptions()
+			opts.Jitter = 0
+			opts.Sleeper = func(d time.Duration) { sleeps = append(sleeps, d) }
+			if tc.opts != nil {
+				tc.opts(opts)
+			}
+
+			shouldRetry := Retrier(opts)
+			retries := []bool{}
+			for _, err := range tc.errs {
+				retries = append(retries, shouldRetry(err))
+			}
+
+			require.Equal(t, tc.want.retries, retries)
+			if tc.want.sleeps == nil {
+				tc.want.sleeps = []time.Duration{}
+			}
+			require.Equal(t, tc.want.sleeps, sleeps)
+		})
+	}
+}
+
+func TestRetrierJitter(t *testing.T) {
+	var slept time.Duration
+	opts := DefaultRetryOptions()
+	opts.Sleeper = func(d time.Duration) { slept = d }
+
+	require.True(t, Retrier(opts)(errors.New("error")))
+	require.True(t, slept >= DefaultRetryInitialDelay)
+	require.True(t, slept <= DefaultRetryInitialDelay+time.Duration(
+		DefaultRetryJitter*float64(DefaultRetryInitialDelay),
+	))
+}
+
+func TestRetrierContext(t *testing.T) {
+	ctx, cancel := context.WithCancel(context.Background())
+	opts := DefaultRetryOptions()
+	opts.InitialDelay = time.Hour
+	opts.Context = ctx
+
+	shouldRetry := Retrier(opts)
+	go cancel()
+	start := time.Now()
+	require.False(t, shouldRetry(errors.New("error")))
+	require.True(t, time.Since(start) < time.Minute)
+
+	// No further retries once the context is done
+	opts.InitialDelay = 0
+	require.False(t, Retrier(opts)(errors.New("error")))
+}